@@ -0,0 +1,275 @@
+package vast
+
+import "time"
+
+// linearStorage holds a linear creative and the elements it points to, so
+// that the common cases are built with a single allocation.
+type linearStorage struct {
+	linear         Linear
+	mediaFiles     MediaFiles
+	mediaFile      [2]MediaFile
+	trackingEvents TrackingEvents
+	tracking       [6]Tracking
+	videoClicks    VideoClicks
+	clickThrough   [1]VideoClick
+	uaids          []UniversalAdID
+	uaid           [1]UniversalAdID
+}
+
+// InLineBuilder builds a VAST document holding a single InLine ad:
+//
+//	v, err := vast.NewInLine("123", "DSP", "Title").
+//		AddImpression("http://impression").
+//		AddLinear(15 * time.Second).
+//		AddMediaFile(vast.MediaFile{Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, URI: "http://media.mp4"}).
+//		Track(vast.EventTypeStart, "http://start").
+//		Build()
+//
+// Methods adding to a creative apply to the last creative added. Misuses are
+// reported by Build. The document returned by Build shares memory with the
+// builder, which must not be used afterwards.
+type InLineBuilder struct {
+	vast        VAST
+	ads         [1]Ad
+	inline      InLine
+	adSystem    AdSystem
+	impressions [2]Impression
+	creatives   [1]Creative
+	first       linearStorage
+	used        bool
+	// current is the storage of the last linear creative, nil if the last
+	// creative isn't linear.
+	current *linearStorage
+	err     error
+}
+
+// NewInLine returns a builder for a VAST 4.2 document with a single InLine
+// ad with the given id, ad system and title.
+func NewInLine(id, adSystem, title string) *InLineBuilder {
+	b := &InLineBuilder{}
+	b.vast.Version = Version42
+	b.ads[0] = Ad{ID: id, InLine: &b.inline}
+	b.vast.Ads = b.ads[:]
+	b.adSystem.Name = adSystem
+	b.inline.AdSystem = &b.adSystem
+	b.inline.AdTitle.CDATA = title
+	b.inline.Impressions = b.impressions[:0]
+	b.inline.Creatives = b.creatives[:0]
+	return b
+}
+
+// Version sets the version of the document.
+func (b *InLineBuilder) Version(version string) *InLineBuilder {
+	b.vast.Version = version
+	return b
+}
+
+// AdServingID sets the ad serving id of the ad. It defaults to the id of
+// the ad for the versions requiring it.
+func (b *InLineBuilder) AdServingID(id string) *InLineBuilder {
+	b.inline.AdServingId = id
+	return b
+}
+
+// Sequence sets the sequence of the ad in a pod.
+func (b *InLineBuilder) Sequence(seq int) *InLineBuilder {
+	b.ads[0].Sequence = seq
+	return b
+}
+
+// AddImpression adds an impression URI.
+func (b *InLineBuilder) AddImpression(uri string) *InLineBuilder {
+	b.inline.Impressions = append(b.inline.Impressions, Impression{URI: uri})
+	return b
+}
+
+// AddError adds an error URI.
+func (b *InLineBuilder) AddError(uri string) *InLineBuilder {
+	b.inline.Errors = append(b.inline.Errors, CDATAString{CDATA: uri})
+	return b
+}
+
+// AddExtension adds an extension to the ad.
+func (b *InLineBuilder) AddExtension(ext Extension) *InLineBuilder {
+	if b.inline.Extensions == nil {
+		b.inline.Extensions = &[]Extension{}
+	}
+	*b.inline.Extensions = append(*b.inline.Extensions, ext)
+	return b
+}
+
+// AddLinear adds a linear creative of duration d.
+func (b *InLineBuilder) AddLinear(d time.Duration) *InLineBuilder {
+	s := &b.first
+	if b.used {
+		s = &linearStorage{}
+	}
+	b.used = true
+	s.linear.Duration = Duration(d)
+	b.inline.Creatives = append(b.inline.Creatives, Creative{Linear: &s.linear})
+	b.current = s
+	return b
+}
+
+// AddCreative adds a creative, e.g. companion or non-linear ads.
+func (b *InLineBuilder) AddCreative(c Creative) *InLineBuilder {
+	b.inline.Creatives = append(b.inline.Creatives, c)
+	b.current = nil
+	return b
+}
+
+// CreativeID sets the id of the last creative.
+func (b *InLineBuilder) CreativeID(id string) *InLineBuilder {
+	if c := b.creative("CreativeID"); c != nil {
+		c.ID = id
+	}
+	return b
+}
+
+// UniversalAdID adds a universal ad id to the last creative. It defaults to
+// the "unknown" registry for the versions requiring it.
+func (b *InLineBuilder) UniversalAdID(registry, id string) *InLineBuilder {
+	c := b.creative("UniversalAdID")
+	if c == nil {
+		return b
+	}
+	if c.UniversalAdID == nil {
+		if s := b.current; s != nil {
+			s.uaids = s.uaid[:0]
+			c.UniversalAdID = &s.uaids
+		} else {
+			c.UniversalAdID = &[]UniversalAdID{}
+		}
+	}
+	*c.UniversalAdID = append(*c.UniversalAdID, UniversalAdID{IDRegistry: registry, ID: id})
+	return b
+}
+
+// SkipOffset makes the last linear creative skippable after offset.
+func (b *InLineBuilder) SkipOffset(offset Offset) *InLineBuilder {
+	if s := b.linear("SkipOffset"); s != nil {
+		s.linear.SkipOffset = &offset
+	}
+	return b
+}
+
+// AddMediaFile adds a media file to the last linear creative.
+func (b *InLineBuilder) AddMediaFile(m MediaFile) *InLineBuilder {
+	s := b.linear("AddMediaFile")
+	if s == nil {
+		return b
+	}
+	if s.linear.MediaFiles == nil {
+		s.mediaFiles.MediaFile = s.mediaFile[:0]
+		s.linear.MediaFiles = &s.mediaFiles
+	}
+	s.mediaFiles.MediaFile = append(s.mediaFiles.MediaFile, m)
+	return b
+}
+
+// Track adds a tracking URI for event to the last linear creative.
+func (b *InLineBuilder) Track(event, uri string) *InLineBuilder {
+	if s := b.linear("Track"); s != nil {
+		s.track(Tracking{Event: event, URI: uri})
+	}
+	return b
+}
+
+// TrackProgress adds a progress tracking URI at offset to the last linear
+// creative.
+func (b *InLineBuilder) TrackProgress(offset Offset, uri string) *InLineBuilder {
+	if s := b.linear("TrackProgress"); s != nil {
+		s.track(Tracking{Event: EventTypeProgress, Offset: &offset, URI: uri})
+	}
+	return b
+}
+
+func (s *linearStorage) track(t Tracking) {
+	if s.linear.TrackingEvents == nil {
+		s.trackingEvents.Tracking = s.tracking[:0]
+		s.linear.TrackingEvents = &s.trackingEvents
+	}
+	s.trackingEvents.Tracking = append(s.trackingEvents.Tracking, t)
+}
+
+// ClickThrough sets the click through URI of the last linear creative.
+func (b *InLineBuilder) ClickThrough(uri string) *InLineBuilder {
+	if s := b.linear("ClickThrough"); s != nil {
+		s.clickThrough[0] = VideoClick{URI: uri}
+		s.videoClicks.ClickThroughs = s.clickThrough[:]
+		s.linear.VideoClicks = &s.videoClicks
+	}
+	return b
+}
+
+// AddClickTracking adds a click tracking URI to the last linear creative.
+func (b *InLineBuilder) AddClickTracking(uri string) *InLineBuilder {
+	if s := b.linear("AddClickTracking"); s != nil {
+		s.videoClicks.ClickTrackings = append(s.videoClicks.ClickTrackings, VideoClick{URI: uri})
+		s.linear.VideoClicks = &s.videoClicks
+	}
+	return b
+}
+
+// creative returns the last creative, recording an error if there is none.
+func (b *InLineBuilder) creative(method string) *Creative {
+	if len(b.inline.Creatives) == 0 {
+		b.fail(method + ": no creative")
+		return nil
+	}
+	return &b.inline.Creatives[len(b.inline.Creatives)-1]
+}
+
+// linear returns the storage of the last creative, recording an error if it
+// isn't linear.
+func (b *InLineBuilder) linear(method string) *linearStorage {
+	if b.current == nil {
+		b.fail(method + ": no linear creative")
+	}
+	return b.current
+}
+
+func (b *InLineBuilder) fail(msg string) {
+	if b.err == nil {
+		b.err = ValidationErrors{{Severity: SeverityError, Path: "Ads[0].InLine.Creatives", Message: msg}}
+	}
+}
+
+// Build fills the required fields left empty and returns the document, or
+// ValidationErrors if it isn't valid for its version.
+func (b *InLineBuilder) Build() (*VAST, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	n := versionNumbers[b.vast.Version]
+	if n >= 41 && b.inline.AdServingId == "" {
+		b.inline.AdServingId = b.ads[0].ID
+	}
+	if n >= 40 {
+		for i := range b.inline.Creatives {
+			c := &b.inline.Creatives[i]
+			if c.UniversalAdID != nil {
+				continue
+			}
+			unknown := UniversalAdID{IDRegistry: "unknown", ID: "unknown"}
+			if c.Linear == &b.first.linear {
+				b.first.uaid[0] = unknown
+				b.first.uaids = b.first.uaid[:]
+				c.UniversalAdID = &b.first.uaids
+			} else {
+				c.UniversalAdID = &[]UniversalAdID{unknown}
+			}
+		}
+	}
+	diags := Validate(&b.vast, "")
+	if HasErrors(diags) {
+		var errs ValidationErrors
+		for _, d := range diags {
+			if d.Severity == SeverityError {
+				errs = append(errs, d)
+			}
+		}
+		return nil, errs
+	}
+	return &b.vast, nil
+}
@@ -0,0 +1,159 @@
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestAd() (*VAST, error) {
+	return NewInLine("123", "DSP", "Title").
+		AddImpression("http://impression").
+		AddError("http://error").
+		AddLinear(15*time.Second).
+		CreativeID("987").
+		AddMediaFile(MediaFile{Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, URI: "http://media.mp4"}).
+		Track(EventTypeStart, "http://start").
+		Track(EventTypeComplete, "http://complete").
+		ClickThrough("http://click").
+		Build()
+}
+
+func literalTestAd() *VAST {
+	return &VAST{
+		Version: "4.2",
+		Ads: []Ad{{
+			ID: "123",
+			InLine: &InLine{
+				AdSystem:    &AdSystem{Name: "DSP"},
+				AdTitle:     PlainString{CDATA: "Title"},
+				AdServingId: "123",
+				Impressions: []Impression{{URI: "http://impression"}},
+				Errors:      []CDATAString{{CDATA: "http://error"}},
+				Creatives: []Creative{{
+					ID:            "987",
+					UniversalAdID: &[]UniversalAdID{{IDRegistry: "unknown", ID: "unknown"}},
+					Linear: &Linear{
+						Duration: Duration(15 * time.Second),
+						TrackingEvents: &TrackingEvents{Tracking: []Tracking{
+							{Event: EventTypeStart, URI: "http://start"},
+							{Event: EventTypeComplete, URI: "http://complete"},
+						}},
+						VideoClicks: &VideoClicks{ClickThroughs: []VideoClick{{URI: "http://click"}}},
+						MediaFiles: &MediaFiles{MediaFile: []MediaFile{
+							{Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, URI: "http://media.mp4"},
+						}},
+					},
+				}},
+			},
+		}},
+	}
+}
+
+func TestInLineBuilder(t *testing.T) {
+	v, err := buildTestAd()
+	if !assert.NoError(t, err) {
+		return
+	}
+	want := literalTestAd()
+	got, err := xml.Marshal(v)
+	assert.NoError(t, err)
+	expected, err := xml.Marshal(want)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(got))
+	assert.Empty(t, Validate(v, ""))
+}
+
+func TestInLineBuilderCreatives(t *testing.T) {
+	d := Duration(5 * time.Second)
+	v, err := NewInLine("1", "DSP", "Title").
+		Version(Version3).
+		Sequence(2).
+		AddImpression("http://impression").
+		AddLinear(10*time.Second).
+		SkipOffset(Offset{Duration: &d}).
+		AddMediaFile(MediaFile{Delivery: "streaming", Type: "application/x-mpegURL", Width: 1, Height: 1, URI: "http://a.m3u8"}).
+		TrackProgress(Offset{Percent: 0.5}, "http://half").
+		AddClickTracking("http://click").
+		AddLinear(20 * time.Second).
+		AddMediaFile(MediaFile{Delivery: "progressive", Type: "video/mp4", Width: 1, Height: 1, URI: "http://b.mp4"}).
+		AddCreative(Creative{CompanionAds: &CompanionAds{}}).
+		AddExtension(Extension{Type: "x"}).
+		Build()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, v.Ads[0].Sequence)
+	in := v.Ads[0].InLine
+	assert.Empty(t, in.AdServingId)
+	if assert.Len(t, in.Creatives, 3) {
+		assert.Nil(t, in.Creatives[0].UniversalAdID)
+		assert.Equal(t, &d, in.Creatives[0].Linear.SkipOffset.Duration)
+		assert.Equal(t, "http://a.m3u8", in.Creatives[0].Linear.MediaFiles.MediaFile[0].URI)
+		assert.Equal(t, "http://b.mp4", in.Creatives[1].Linear.MediaFiles.MediaFile[0].URI)
+		assert.Equal(t, Duration(20*time.Second), in.Creatives[1].Linear.Duration)
+	}
+	assert.Len(t, *in.Extensions, 1)
+
+	v, err = NewInLine("1", "DSP", "Title").
+		AddImpression("http://impression").
+		AddLinear(10*time.Second).
+		UniversalAdID("Ad-ID", "abc").
+		AddMediaFile(MediaFile{Delivery: "progressive", Type: "video/mp4", Width: 1, Height: 1, URI: "http://a.mp4"}).
+		AddCreative(Creative{CompanionAds: &CompanionAds{}}).
+		Build()
+	if assert.NoError(t, err) {
+		in := v.Ads[0].InLine
+		assert.Equal(t, &[]UniversalAdID{{IDRegistry: "Ad-ID", ID: "abc"}}, in.Creatives[0].UniversalAdID)
+		assert.Equal(t, &[]UniversalAdID{{IDRegistry: "unknown", ID: "unknown"}}, in.Creatives[1].UniversalAdID)
+	}
+}
+
+func TestInLineBuilderErrors(t *testing.T) {
+	_, err := NewInLine("1", "", "Title").AddLinear(time.Second).Build()
+	var errs ValidationErrors
+	if assert.True(t, errors.As(err, &errs)) {
+		var paths []string
+		for _, d := range errs {
+			paths = append(paths, d.Path)
+		}
+		assert.Equal(t, []string{
+			"Ads[0].InLine.AdSystem",
+			"Ads[0].InLine.Impressions",
+			"Ads[0].InLine.Creatives[0].Linear.MediaFiles",
+		}, paths)
+	}
+	assert.Equal(t, ErrorCodeSchemaValidation, ErrorCodeFor(err))
+
+	_, err = NewInLine("1", "DSP", "Title").
+		AddImpression("http://impression").
+		Track(EventTypeStart, "http://start").
+		AddCreative(Creative{CompanionAds: &CompanionAds{}}).
+		AddMediaFile(MediaFile{}).
+		Build()
+	assert.EqualError(t, err, "error: Ads[0].InLine.Creatives: Track: no linear creative")
+
+	_, err = NewInLine("1", "DSP", "Title").CreativeID("x").Build()
+	assert.EqualError(t, err, "error: Ads[0].InLine.Creatives: CreativeID: no creative")
+}
+
+func BenchmarkInLineBuilder(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := buildTestAd(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInLineLiteral(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if literalTestAd() == nil {
+			b.Fatal("nil")
+		}
+	}
+}
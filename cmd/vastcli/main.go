@@ -0,0 +1,349 @@
+// Command vastcli lints, formats, converts and inspects VAST documents.
+//
+// Usage:
+//
+//	vastcli lint [-version v] [file ...]
+//	vastcli fmt [file]
+//	vastcli convert [-to xml|json] [file]
+//	vastcli inspect [file ...]
+//
+// Files default to the standard input, which can also be given as "-".
+//
+// Exit codes are 0 when everything is fine, 1 when lint only reported
+// warnings, 2 when lint reported errors and 3 when a document couldn't be
+// read or parsed, or the command line is invalid.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/llgoer/vast"
+)
+
+// Exit codes.
+const (
+	exitOK       = 0
+	exitWarnings = 1
+	exitErrors   = 2
+	exitFailure  = 3
+)
+
+const usage = `usage: vastcli <command> [arguments]
+
+commands:
+  lint     validate documents against the VAST spec
+  fmt      print a document as canonical indented XML
+  convert  convert a document between XML and JSON
+  inspect  print a human-readable summary of documents
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitFailure
+	}
+	cmd := &command{stdin: stdin, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "lint":
+		return cmd.lint(args[1:])
+	case "fmt":
+		return cmd.fmt(args[1:])
+	case "convert":
+		return cmd.convert(args[1:])
+	case "inspect":
+		return cmd.inspect(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "vastcli: unknown command %q\n\n%s", args[0], usage)
+	return exitFailure
+}
+
+type command struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func (c *command) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("vastcli "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// inputs returns the files named in args, or the standard input.
+func inputs(args []string) []string {
+	if len(args) == 0 {
+		return []string{"-"}
+	}
+	return args
+}
+
+func (c *command) read(name string) ([]byte, error) {
+	if name == "-" {
+		return ioutil.ReadAll(c.stdin)
+	}
+	return ioutil.ReadFile(name)
+}
+
+// load reads and decodes a document, either XML or JSON.
+func (c *command) load(name string) (*vast.VAST, bool, error) {
+	b, err := c.read(name)
+	if err != nil {
+		return nil, false, err
+	}
+	var v vast.VAST
+	if isJSON(b) {
+		err = json.Unmarshal(b, &v)
+		return &v, true, err
+	}
+	err = xml.Unmarshal(b, &v)
+	return &v, false, err
+}
+
+func isJSON(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && b[0] == '{'
+}
+
+func displayName(name string) string {
+	if name == "-" {
+		return "<stdin>"
+	}
+	return name
+}
+
+func (c *command) lint(args []string) int {
+	fs := c.flags("lint")
+	version := fs.String("version", "", "VAST `version` to validate against (defaults to the document version)")
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	code := exitOK
+	for _, name := range inputs(fs.Args()) {
+		v, _, err := c.load(name)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", displayName(name), err)
+			code = exitFailure
+			continue
+		}
+		for _, d := range vast.Validate(v, *version) {
+			fmt.Fprintf(c.stdout, "%s: %s\n", displayName(name), d)
+			if d.Severity == vast.SeverityError && code < exitErrors {
+				code = exitErrors
+			} else if code < exitWarnings {
+				code = exitWarnings
+			}
+		}
+	}
+	return code
+}
+
+func (c *command) fmt(args []string) int {
+	fs := c.flags("fmt")
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(c.stderr, "vastcli fmt: at most one file expected")
+		return exitFailure
+	}
+	name := inputs(fs.Args())[0]
+	v, _, err := c.load(name)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "%s: %v\n", displayName(name), err)
+		return exitFailure
+	}
+	return c.writeXML(v)
+}
+
+func (c *command) writeXML(v *vast.VAST) int {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(c.stderr, "vastcli: %v\n", err)
+		return exitFailure
+	}
+	fmt.Fprintf(c.stdout, "%s%s\n", xml.Header, b)
+	return exitOK
+}
+
+func (c *command) convert(args []string) int {
+	fs := c.flags("convert")
+	to := fs.String("to", "", "output `format`, xml or json (defaults to the opposite of the input)")
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(c.stderr, "vastcli convert: at most one file expected")
+		return exitFailure
+	}
+	name := inputs(fs.Args())[0]
+	v, fromJSON, err := c.load(name)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "%s: %v\n", displayName(name), err)
+		return exitFailure
+	}
+	format := *to
+	if format == "" {
+		format = "json"
+		if fromJSON {
+			format = "xml"
+		}
+	}
+	switch format {
+	case "xml":
+		return c.writeXML(v)
+	case "json":
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			fmt.Fprintf(c.stderr, "vastcli: %v\n", err)
+			return exitFailure
+		}
+		fmt.Fprintf(c.stdout, "%s\n", b)
+		return exitOK
+	}
+	fmt.Fprintf(c.stderr, "vastcli convert: unknown format %q\n", format)
+	return exitFailure
+}
+
+func (c *command) inspect(args []string) int {
+	fs := c.flags("inspect")
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	code := exitOK
+	for _, name := range inputs(fs.Args()) {
+		v, _, err := c.load(name)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %v\n", displayName(name), err)
+			code = exitFailure
+			continue
+		}
+		fmt.Fprintf(c.stdout, "%s: VAST %s, %d ad(s)\n", displayName(name), v.Version, len(v.Ads))
+		writeSummary(c.stdout, v)
+	}
+	return code
+}
+
+// writeSummary prints the ads, creatives, media files, durations and
+// tracking events of v.
+func writeSummary(w io.Writer, v *vast.VAST) {
+	for _, e := range v.Errors {
+		fmt.Fprintf(w, "  error: %s\n", strings.TrimSpace(e.CDATA))
+	}
+	for i, ad := range v.Ads {
+		var attrs []string
+		if ad.ID != "" {
+			attrs = append(attrs, "id="+ad.ID)
+		}
+		if ad.Sequence != 0 {
+			attrs = append(attrs, fmt.Sprintf("sequence=%d", ad.Sequence))
+		}
+		if ad.AdType != "" {
+			attrs = append(attrs, "type="+ad.AdType)
+		}
+		switch {
+		case ad.InLine != nil:
+			in := ad.InLine
+			fmt.Fprintf(w, "  ad #%d inline %s\n", i+1, strings.Join(attrs, " "))
+			fmt.Fprintf(w, "    title: %s\n", strings.TrimSpace(in.AdTitle.CDATA))
+			if in.AdSystem != nil {
+				fmt.Fprintf(w, "    system: %s\n", strings.TrimSpace(in.AdSystem.Name))
+			}
+			fmt.Fprintf(w, "    impressions: %d, errors: %d\n", len(in.Impressions), len(in.Errors))
+			for j, cr := range in.Creatives {
+				writeCreative(w, j, cr)
+			}
+		case ad.Wrapper != nil:
+			wr := ad.Wrapper
+			fmt.Fprintf(w, "  ad #%d wrapper %s\n", i+1, strings.Join(attrs, " "))
+			fmt.Fprintf(w, "    tag: %s\n", strings.TrimSpace(wr.VASTAdTagURI.CDATA))
+			fmt.Fprintf(w, "    impressions: %d, errors: %d, creatives: %d\n", len(wr.Impressions), len(wr.Errors), len(wr.Creatives))
+		default:
+			fmt.Fprintf(w, "  ad #%d empty %s\n", i+1, strings.Join(attrs, " "))
+		}
+	}
+}
+
+func writeCreative(w io.Writer, i int, cr vast.Creative) {
+	id := cr.ID
+	if id == "" {
+		id = cr.AdID
+	}
+	switch {
+	case cr.Linear != nil:
+		l := cr.Linear
+		fmt.Fprintf(w, "    creative #%d linear id=%s duration=%s", i+1, id, time.Duration(l.Duration))
+		if l.SkipOffset != nil {
+			skip, _ := l.SkipOffset.MarshalText()
+			fmt.Fprintf(w, " skipoffset=%s", skip)
+		}
+		fmt.Fprintln(w)
+		if l.MediaFiles != nil {
+			for _, m := range l.MediaFiles.MediaFile {
+				fmt.Fprintf(w, "      media: %s %s %dx%d", m.Delivery, m.Type, m.Width, m.Height)
+				if m.Bitrate != 0 {
+					fmt.Fprintf(w, " %dkbps", m.Bitrate)
+				}
+				if m.APIFramework != "" {
+					fmt.Fprintf(w, " api=%s", m.APIFramework)
+				}
+				fmt.Fprintf(w, " %s\n", strings.TrimSpace(m.URI))
+			}
+		}
+		writeTracking(w, l.TrackingEvents)
+	case cr.NonLinearAds != nil:
+		fmt.Fprintf(w, "    creative #%d nonlinear id=%s nonlinears=%d\n", i+1, id, len(cr.NonLinearAds.NonLinears))
+		writeTracking(w, cr.NonLinearAds.TrackingEvents)
+	case cr.CompanionAds != nil:
+		fmt.Fprintf(w, "    creative #%d companions id=%s companions=%d", i+1, id, len(cr.CompanionAds.Companions))
+		if cr.CompanionAds.Required != "" {
+			fmt.Fprintf(w, " required=%s", cr.CompanionAds.Required)
+		}
+		fmt.Fprintln(w)
+		for _, comp := range cr.CompanionAds.Companions {
+			fmt.Fprintf(w, "      companion: %dx%d\n", comp.Width, comp.Height)
+		}
+	default:
+		fmt.Fprintf(w, "    creative #%d id=%s\n", i+1, id)
+	}
+}
+
+// writeTracking prints the number of trackers per event, in order of first
+// appearance.
+func writeTracking(w io.Writer, te *vast.TrackingEvents) {
+	if te == nil || len(te.Tracking) == 0 {
+		return
+	}
+	var events []string
+	counts := map[string]int{}
+	for _, t := range te.Tracking {
+		event := t.Event
+		if t.Offset != nil {
+			offset, _ := t.Offset.MarshalText()
+			event += "@" + string(offset)
+		}
+		if counts[event] == 0 {
+			events = append(events, event)
+		}
+		counts[event]++
+	}
+	parts := make([]string, len(events))
+	for i, e := range events {
+		parts[i] = fmt.Sprintf("%s(%d)", e, counts[e])
+	}
+	fmt.Fprintf(w, "      tracking: %s\n", strings.Join(parts, " "))
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/llgoer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestLint(t *testing.T) {
+	code, out, _ := runCLI("", "lint", "../../testdata/iab/vast_4.2_samples/Inline_Linear_Tag-test.xml")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, out)
+
+	code, out, _ = runCLI("", "lint", "../../testdata/vast_wrapper_linear_1.xml")
+	assert.Equal(t, exitWarnings, code)
+	assert.Contains(t, out, "Ads[0].Wrapper.AllowMultipleAds")
+
+	code, out, _ = runCLI(`<VAST version="4.2"><Ad><InLine></InLine></Ad></VAST>`, "lint")
+	assert.Equal(t, exitErrors, code)
+	assert.Contains(t, out, "<stdin>: error: Ads[0].InLine.AdSystem: required")
+
+	code, _, errOut := runCLI(`<VAST`, "lint", "-")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, errOut, "<stdin>: XML syntax error")
+
+	code, _, _ = runCLI("", "lint", "-version", "2.0", "../../testdata/vast_inline_linear.xml")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestFmt(t *testing.T) {
+	code, out, _ := runCLI(`<VAST version="3.0">  <Ad id="1"><InLine><AdTitle>t</AdTitle></InLine></Ad></VAST>`, "fmt")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, xml.Header+"<VAST version=\"3.0\">\n  <Ad id=\"1\">\n    <InLine>\n      <AdTitle>t</AdTitle>\n      <Creatives></Creatives>\n    </InLine>\n  </Ad>\n</VAST>\n", out)
+}
+
+func TestConvert(t *testing.T) {
+	in, err := os.ReadFile("../../testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	code, js, _ := runCLI(string(in), "convert")
+	assert.Equal(t, exitOK, code)
+	assert.True(t, strings.HasPrefix(js, "{"))
+
+	code, x, _ := runCLI(js, "convert")
+	assert.Equal(t, exitOK, code)
+
+	var want, got vast.VAST
+	assert.NoError(t, xml.Unmarshal(in, &want))
+	assert.NoError(t, xml.Unmarshal([]byte(x), &got))
+	assert.Equal(t, want, got)
+
+	code, _, _ = runCLI(string(in), "convert", "-to", "yaml")
+	assert.Equal(t, exitFailure, code)
+}
+
+func TestInspect(t *testing.T) {
+	code, out, _ := runCLI("", "inspect", "../../testdata/vast_inline_linear.xml", "../../testdata/vast_wrapper_linear_1.xml")
+	assert.Equal(t, exitOK, code)
+	for _, s := range []string{
+		"VAST 2.0, 1 ad(s)",
+		"ad #1 inline id=601364",
+		"creative #1 linear id=601364 duration=30s",
+		"media: progressive video/x-flv 400x300 500kbps http://cdnp.tremormedia.com/video/acudeo/Carrot_400x300_500kb.flv",
+		"tracking: creativeView(1) start(1) midpoint(1)",
+		"creative #2 companions id=601364-Companion companions=2 required=all",
+		"ad #1 wrapper id=602833",
+		"tag: http://demo.tremormedia.com/proddev/vast/vast_inline_linear.xml",
+	} {
+		assert.Contains(t, out, s)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	code, _, errOut := runCLI("")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, errOut, "usage")
+
+	code, _, _ = runCLI("", "bogus")
+	assert.Equal(t, exitFailure, code)
+
+	code, _, _ = runCLI("", "lint", "does-not-exist.xml")
+	assert.Equal(t, exitFailure, code)
+}
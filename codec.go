@@ -0,0 +1,643 @@
+package vast
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//go:generate go run ./internal/cmd/codecgen -type VAST,Ad,InLine,Wrapper,Creative,Linear,MediaFiles,MediaFile,TrackingEvents,Tracking,AdSystem,Impression,CDATAString,PlainString,VideoClicks,VideoClick,AdParameters -o vast_codec.go -mirror vast_reflect_test.go
+
+// The hot types of a VAST document have generated codecs: vast_codec.go
+// holds their reflection-free MarshalXML, UnmarshalXML, MarshalJSON and
+// UnmarshalJSON methods. They produce the same output as the tag-driven
+// encoding/xml and encoding/json codecs, which the tests check against the
+// method-free copies of vast_reflect_test.go, with two exceptions:
+//
+//   - MarshalJSON always escapes HTML characters, even in the output of a
+//     json.Encoder set not to with SetEscapeHTML(false).
+//   - UnmarshalJSON may report different errors on invalid input.
+//
+// The functions below are the runtime support of the generated codecs.
+
+// tagCodec is implemented by the types whose XML codec is generated from
+// their struct tags, and which can thus still be described by them.
+type tagCodec interface {
+	tagCodec()
+}
+
+// cdataSafe reports whether s can be written as a CDATA section through an
+// xml.Directive, which rejects unbalanced quotes and angle brackets.
+func cdataSafe(s string) bool {
+	return strings.IndexAny(s, `<>'"`) < 0
+}
+
+// textSafe reports whether s is escaped by xml.EncodeToken the way
+// encoding/xml escapes character data fields, which differs on newlines.
+func textSafe(s string) bool {
+	return strings.IndexByte(s, '\n') < 0
+}
+
+// encodeCDATA writes s as a CDATA section, which must be cdataSafe.
+func encodeCDATA(e *xml.Encoder, s string) error {
+	if s == "" {
+		return nil
+	}
+	return e.EncodeToken(xml.Directive("[CDATA[" + s + "]]"))
+}
+
+// encodeText writes the element start holding the text s.
+func encodeText(e *xml.Encoder, start xml.StartElement, s string) error {
+	if !textSafe(s) {
+		return e.EncodeElement(s, start)
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if s != "" {
+		if err := e.EncodeToken(xml.CharData(s)); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// decodeText returns the character data of the element whose start was just
+// read, skipping the nested elements.
+func decodeText(d *xml.Decoder) ([]byte, error) {
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.StartElement:
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			return data, nil
+		}
+	}
+}
+
+// parseInt decodes s into v the way encoding/xml does.
+func parseInt(v *int, s string) error {
+	if s == "" {
+		*v = 0
+		return nil
+	}
+	i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 0)
+	if err != nil {
+		return err
+	}
+	*v = int(i)
+	return nil
+}
+
+// parseBool decodes s into v the way encoding/xml does.
+func parseBool(v *bool, s string) error {
+	if s == "" {
+		*v = false
+		return nil
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*v = b
+	return nil
+}
+
+// closeJSON ends the object or array in b, whose last value is followed by
+// a comma, with c.
+func closeJSON(b []byte, c byte) []byte {
+	if n := len(b) - 1; b[n] == ',' {
+		b[n] = c
+		return b
+	}
+	return append(b, c)
+}
+
+// appendJSON appends the encoding/json encoding of v to b.
+func appendJSON(b []byte, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, data...), nil
+}
+
+const hex = "0123456789abcdef"
+
+// appendJSONString appends s to b as a JSON string, escaped as encoding/json
+// does.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= ' ' && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xF])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// jsonDecoder is implemented by the types with a generated JSON codec.
+type jsonDecoder interface {
+	decodeJSON(r *jsonReader) error
+}
+
+// unmarshalJSON decodes data into v.
+func unmarshalJSON(data []byte, v jsonDecoder) error {
+	r := &jsonReader{data: data}
+	if err := v.decodeJSON(r); err != nil {
+		return err
+	}
+	if r.space(); r.off < len(r.data) {
+		return r.syntaxError()
+	}
+	return r.err
+}
+
+// jsonField returns the index of the name of names matching key as
+// encoding/json matches fields, -1 if none does.
+func jsonField(key []byte, names []string) int {
+	for i, name := range names {
+		if string(key) == name {
+			return i
+		}
+	}
+	for i, name := range names {
+		if bytes.EqualFold(key, []byte(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// maxJSONDepth is the maximum nesting depth of the skipped JSON values, the
+// one of encoding/json.
+const maxJSONDepth = 10000
+
+// jsonReader reads the JSON values decoded by the generated codecs. Its
+// methods return syntax errors, and record the first type mismatch in err
+// while skipping the mismatched value, as encoding/json does.
+type jsonReader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *jsonReader) syntaxError() error {
+	if r.off >= len(r.data) {
+		return fmt.Errorf("unexpected end of JSON input")
+	}
+	return fmt.Errorf("invalid character %q in JSON at offset %d", r.data[r.off], r.off)
+}
+
+// space skips the white space.
+func (r *jsonReader) space() {
+	for r.off < len(r.data) {
+		switch r.data[r.off] {
+		case ' ', '\t', '\n', '\r':
+			r.off++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the first byte of the next value, 0 at the end of the input.
+func (r *jsonReader) peek() byte {
+	if r.space(); r.off < len(r.data) {
+		return r.data[r.off]
+	}
+	return 0
+}
+
+// literal reads the literal lit if it is next.
+func (r *jsonReader) literal(lit string) bool {
+	if r.peek() == lit[0] && bytes.HasPrefix(r.data[r.off:], []byte(lit)) {
+		r.off += len(lit)
+		return true
+	}
+	return false
+}
+
+// null reads the next value if it is null.
+func (r *jsonReader) null() bool {
+	return r.literal("null")
+}
+
+// mismatch skips the next value, which doesn't match the type of the value
+// pointed to by dst, recording the type error.
+func (r *jsonReader) mismatch(dst interface{}) error {
+	value := "number"
+	switch r.peek() {
+	case '"':
+		value = "string"
+	case '{':
+		value = "object"
+	case '[':
+		value = "array"
+	case 't', 'f':
+		value = "bool"
+	case 'n':
+		value = "null"
+	}
+	if err := r.skip(); err != nil {
+		return err
+	}
+	r.typeError(value, dst)
+	return nil
+}
+
+func (r *jsonReader) typeError(value string, dst interface{}) {
+	if r.err == nil {
+		r.err = &json.UnmarshalTypeError{Value: value, Type: reflect.TypeOf(dst).Elem(), Offset: int64(r.off)}
+	}
+}
+
+// object reads the start of the next object, reporting false if it is null
+// or not an object, which is then skipped.
+func (r *jsonReader) object(dst interface{}) (bool, error) {
+	switch r.peek() {
+	case '{':
+		r.off++
+		return true, nil
+	case 'n':
+		if r.null() {
+			return false, nil
+		}
+	}
+	return false, r.mismatch(dst)
+}
+
+// key reads the key of the next member of an object, reporting false at the
+// end of the object.
+func (r *jsonReader) key(first bool) ([]byte, bool, error) {
+	c := r.peek()
+	if c == '}' {
+		r.off++
+		return nil, false, nil
+	}
+	if !first {
+		if c != ',' {
+			return nil, false, r.syntaxError()
+		}
+		r.off++
+		c = r.peek()
+	}
+	if c != '"' {
+		return nil, false, r.syntaxError()
+	}
+	key, err := r.string()
+	if err != nil {
+		return nil, false, err
+	}
+	if r.peek() != ':' {
+		return nil, false, r.syntaxError()
+	}
+	r.off++
+	return key, true, nil
+}
+
+// array reads the start of the next array, reporting false if it is not an
+// array, which is then skipped.
+func (r *jsonReader) array(dst interface{}) (bool, error) {
+	if r.peek() == '[' {
+		r.off++
+		return true, nil
+	}
+	return false, r.mismatch(dst)
+}
+
+// next moves to the next value of an array, reporting false at the end of
+// the array.
+func (r *jsonReader) next(first bool) (bool, error) {
+	switch c := r.peek(); {
+	case c == ']':
+		r.off++
+		return false, nil
+	case !first:
+		if c != ',' {
+			return false, r.syntaxError()
+		}
+		r.off++
+	}
+	return true, nil
+}
+
+// decodeString decodes the next value into s, leaving it unchanged if it is
+// null.
+func (r *jsonReader) decodeString(s *string) error {
+	if r.peek() == '"' {
+		b, err := r.string()
+		if err != nil {
+			return err
+		}
+		*s = string(b)
+		return nil
+	}
+	if r.null() {
+		return nil
+	}
+	return r.mismatch(s)
+}
+
+// decodeInt decodes the next value into i, leaving it unchanged if it is
+// null.
+func (r *jsonReader) decodeInt(i *int) error {
+	if c := r.peek(); c == '-' || '0' <= c && c <= '9' {
+		b, err := r.number()
+		if err != nil {
+			return err
+		}
+		n, err := strconv.ParseInt(string(b), 10, 0)
+		if err != nil {
+			r.typeError("number "+string(b), i)
+			return nil
+		}
+		*i = int(n)
+		return nil
+	}
+	if r.null() {
+		return nil
+	}
+	return r.mismatch(i)
+}
+
+// decodeBool decodes the next value into b, leaving it unchanged if it is
+// null.
+func (r *jsonReader) decodeBool(b *bool) error {
+	switch {
+	case r.literal("true"):
+		*b = true
+	case r.literal("false"):
+		*b = false
+	case r.null():
+	default:
+		return r.mismatch(b)
+	}
+	return nil
+}
+
+// decodeText decodes the next value into t, leaving it unchanged if it is
+// null.
+func (r *jsonReader) decodeText(t encoding.TextUnmarshaler) error {
+	if r.peek() == '"' {
+		b, err := r.string()
+		if err != nil {
+			return err
+		}
+		return t.UnmarshalText(b)
+	}
+	if r.null() {
+		return nil
+	}
+	return r.mismatch(t)
+}
+
+// unmarshal decodes the next value into v with encoding/json.
+func (r *jsonReader) unmarshal(v interface{}) error {
+	r.space()
+	start := r.off
+	if err := r.skip(); err != nil {
+		return err
+	}
+	err := json.Unmarshal(r.data[start:r.off], v)
+	if _, ok := err.(*json.UnmarshalTypeError); ok {
+		if r.err == nil {
+			r.err = err
+		}
+		return nil
+	}
+	return err
+}
+
+// skip skips the next value.
+func (r *jsonReader) skip() error {
+	return r.skipDepth(0)
+}
+
+func (r *jsonReader) skipDepth(depth int) error {
+	if depth > maxJSONDepth {
+		return fmt.Errorf("exceeded max depth")
+	}
+	switch c := r.peek(); {
+	case c == '"':
+		_, err := r.string()
+		return err
+	case c == '{':
+		r.off++
+		for first := true; ; first = false {
+			_, ok, err := r.key(first)
+			if !ok || err != nil {
+				return err
+			}
+			if err := r.skipDepth(depth + 1); err != nil {
+				return err
+			}
+		}
+	case c == '[':
+		r.off++
+		for first := true; ; first = false {
+			ok, err := r.next(first)
+			if !ok || err != nil {
+				return err
+			}
+			if err := r.skipDepth(depth + 1); err != nil {
+				return err
+			}
+		}
+	case c == '-' || '0' <= c && c <= '9':
+		_, err := r.number()
+		return err
+	case r.literal("true"), r.literal("false"), r.null():
+		return nil
+	}
+	return r.syntaxError()
+}
+
+// number reads the next number.
+func (r *jsonReader) number() ([]byte, error) {
+	start := r.off
+	digits := func() int {
+		n := 0
+		for r.off < len(r.data) && '0' <= r.data[r.off] && r.data[r.off] <= '9' {
+			r.off++
+			n++
+		}
+		return n
+	}
+	if r.off < len(r.data) && r.data[r.off] == '-' {
+		r.off++
+	}
+	if r.off < len(r.data) && r.data[r.off] == '0' {
+		r.off++
+	} else if digits() == 0 {
+		return nil, r.syntaxError()
+	}
+	if r.off < len(r.data) && r.data[r.off] == '.' {
+		r.off++
+		if digits() == 0 {
+			return nil, r.syntaxError()
+		}
+	}
+	if r.off < len(r.data) && (r.data[r.off] == 'e' || r.data[r.off] == 'E') {
+		r.off++
+		if r.off < len(r.data) && (r.data[r.off] == '+' || r.data[r.off] == '-') {
+			r.off++
+		}
+		if digits() == 0 {
+			return nil, r.syntaxError()
+		}
+	}
+	return r.data[start:r.off], nil
+}
+
+// string reads the next string, unquoted as encoding/json does. The result
+// may share the input.
+func (r *jsonReader) string() ([]byte, error) {
+	r.off++ // opening quote
+	start := r.off
+	for r.off < len(r.data) {
+		c := r.data[r.off]
+		if c == '"' {
+			r.off++
+			return r.data[start : r.off-1], nil
+		}
+		if c == '\\' || c < ' ' || c >= utf8.RuneSelf {
+			break
+		}
+		r.off++
+	}
+	b := append([]byte(nil), r.data[start:r.off]...)
+	for r.off < len(r.data) {
+		switch c := r.data[r.off]; {
+		case c == '"':
+			r.off++
+			return b, nil
+		case c == '\\':
+			r.off++
+			if r.off >= len(r.data) {
+				return nil, r.syntaxError()
+			}
+			switch c := r.data[r.off]; c {
+			case '"', '\\', '/':
+				b = append(b, c)
+			case 'b':
+				b = append(b, '\b')
+			case 'f':
+				b = append(b, '\f')
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'u':
+				r.off--
+				rr := r.u4()
+				if rr < 0 {
+					return nil, r.syntaxError()
+				}
+				r.off += 6
+				if utf16.IsSurrogate(rr) {
+					if rr1 := r.u4(); rr1 >= 0 {
+						if dec := utf16.DecodeRune(rr, rr1); dec != unicode.ReplacementChar {
+							r.off += 6
+							b = appendRune(b, dec)
+							continue
+						}
+					}
+					rr = unicode.ReplacementChar
+				}
+				b = appendRune(b, rr)
+				continue
+			default:
+				return nil, r.syntaxError()
+			}
+			r.off++
+		case c < ' ':
+			return nil, r.syntaxError()
+		case c < utf8.RuneSelf:
+			b = append(b, c)
+			r.off++
+		default:
+			rr, size := utf8.DecodeRune(r.data[r.off:])
+			b = appendRune(b, rr)
+			r.off += size
+		}
+	}
+	return nil, r.syntaxError()
+}
+
+// u4 returns the code point of the \uXXXX escape at the offset, -1 if there
+// is none.
+func (r *jsonReader) u4() rune {
+	s := r.data[r.off:]
+	if len(s) < 6 || s[0] != '\\' || s[1] != 'u' {
+		return -1
+	}
+	n, err := strconv.ParseUint(string(s[2:6]), 16, 16)
+	if err != nil {
+		return -1
+	}
+	return rune(n)
+}
+
+func appendRune(b []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	return append(b, buf[:utf8.EncodeRune(buf[:], r)]...)
+}
@@ -0,0 +1,368 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mirror returns v as its method-free copy, which uses the reflection-based
+// codecs.
+func mirror(v *VAST) *reflectVAST {
+	return (*reflectVAST)(unsafe.Pointer(v))
+}
+
+// marshalXMLReflect marshals v with the reflection-based codec.
+func marshalXMLReflect(v *VAST, indent bool) ([]byte, error) {
+	var b bytes.Buffer
+	e := xml.NewEncoder(&b)
+	if indent {
+		e.Indent("", "  ")
+	}
+	if err := e.EncodeElement(mirror(v), xml.StartElement{Name: xml.Name{Local: "VAST"}}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// unmarshalKeeping unmarshals data into v as xml.Unmarshal does, keeping the
+// unknown content.
+func unmarshalKeeping(data []byte, v interface{}) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	defer keepUnknown(dec)()
+	return dec.Decode(v)
+}
+
+func codecFixtures(t testing.TB) []string {
+	var paths []string
+	for _, pattern := range []string{"testdata/*.xml", "testdata/iab/*/*.xml"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// assertCodec checks that the generated codecs of v behave as the
+// reflection-based ones.
+func assertCodec(t *testing.T, v *VAST) {
+	for _, indent := range []bool{false, true} {
+		want, err := marshalXMLReflect(v, indent)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var b bytes.Buffer
+		e := xml.NewEncoder(&b)
+		if indent {
+			e.Indent("", "  ")
+		}
+		if assert.NoError(t, e.Encode(v)) {
+			assert.Equal(t, string(want), b.String())
+		}
+
+		var got, gotReflect VAST
+		assert.NoError(t, unmarshalKeeping(want, &got))
+		assert.NoError(t, unmarshalKeeping(want, mirror(&gotReflect)))
+		assert.Equal(t, gotReflect, got)
+	}
+
+	want, err := json.Marshal(mirror(v))
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := json.Marshal(v)
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(want), string(got))
+	}
+	var decoded, decodedReflect VAST
+	assert.NoError(t, json.Unmarshal(want, &decoded))
+	assert.NoError(t, json.Unmarshal(want, mirror(&decodedReflect)))
+	assert.Equal(t, decodedReflect, decoded)
+}
+
+func TestCodecTestdata(t *testing.T) {
+	for _, path := range codecFixtures(t) {
+		t.Run(path, func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var v, want VAST
+			assert.NoError(t, unmarshalKeeping(data, &v))
+			assert.NoError(t, unmarshalKeeping(data, mirror(&want)))
+			assert.Equal(t, want, v)
+			assertCodec(t, &v)
+
+			// the unknown content is dropped by default
+			dropUnknown(reflect.ValueOf(&want))
+			v = VAST{}
+			assert.NoError(t, xml.Unmarshal(data, &v))
+			assert.Equal(t, want, v)
+		})
+	}
+}
+
+func TestCodecDemo(t *testing.T) {
+	v, err := createVastDemo()
+	assert.NoError(t, err)
+	assertCodec(t, v)
+}
+
+func TestCodecEscaping(t *testing.T) {
+	for _, s := range []string{
+		`http://example.com/?a=1&b="2"`,
+		"<script>alert('x')</script>",
+		"line\nbreak\r\ttab",
+		"control \x00\x01\x1f\x7f",
+		"invalid \xff utf-8",
+		"separators \u2028 and \u2029, emoji \U0001F600",
+	} {
+		v := &VAST{
+			Version: s,
+			Ads: []Ad{{
+				ID: s,
+				InLine: &InLine{
+					AdServingId: s,
+					Creatives: []Creative{{
+						AdID: s,
+						Linear: &Linear{
+							TrackingEvents: &TrackingEvents{Tracking: []Tracking{{Event: s, URI: s}}},
+							MediaFiles:     &MediaFiles{MediaFile: []MediaFile{{Type: s, URI: s}}},
+						},
+					}},
+				},
+			}},
+		}
+		t.Run(s, func(t *testing.T) {
+			for _, indent := range []bool{false, true} {
+				want, err := marshalXMLReflect(v, indent)
+				assert.NoError(t, err)
+				var b bytes.Buffer
+				e := xml.NewEncoder(&b)
+				if indent {
+					e.Indent("", "  ")
+				}
+				assert.NoError(t, e.Encode(v))
+				assert.Equal(t, string(want), b.String())
+			}
+			want, err := json.Marshal(mirror(v))
+			assert.NoError(t, err)
+			got, err := json.Marshal(v)
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestCodecEmpty(t *testing.T) {
+	for _, v := range []*VAST{
+		{},
+		{Ads: []Ad{}},
+		{Ads: []Ad{{InLine: &InLine{Creatives: []Creative{{Linear: &Linear{MediaFiles: &MediaFiles{}}}}}}}},
+		{Ads: []Ad{{Wrapper: &Wrapper{}}}},
+	} {
+		assertCodec(t, v)
+	}
+}
+
+func TestCodecUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		init VAST
+		data string
+	}{
+		{"null", VAST{Version: "3.0"}, `null`},
+		{"null fields", VAST{Version: "3.0", Ads: []Ad{{ID: "1"}}, Mute: true}, `{"Version":null,"Ad":null,"Mute":null}`},
+		{"null elements", VAST{Ads: []Ad{{ID: "1"}}}, `{"Ad":[null,{"ID":"2"}]}`},
+		{"null pointers", VAST{Ads: []Ad{{InLine: &InLine{}}}}, `{"Ad":[{"InLine":null}]}`},
+		{"folded keys", VAST{}, `{"version":"4.0","AD":[{"iD":"1"}],"Xmlns":"ns"}`},
+		{"duplicate keys", VAST{}, `{"Version":"3.0","Version":"4.0"}`},
+		{"unknown keys", VAST{}, `{"Foo":{"a":[1,2.5e3,true,null,"x"]},"Version":"4.0"}`},
+		{"reused elements", VAST{Ads: []Ad{{ID: "1", Type: "a"}, {ID: "2"}}}, `{"Ad":[{"ID":"3"}]}`},
+		{"empty array", VAST{Ads: []Ad{{ID: "1"}}}, `{"Ad":[]}`},
+		{"escapes", VAST{}, `{"Version":"é\"\\\/\b\f\n\r\t😀\ud800x "}`},
+		{"white space", VAST{}, " {\n\t\"Version\" : \"4.0\" ,\r\"Ad\" : [ { } , { \"ID\" : \"1\" } ] } "},
+		{"null text", VAST{Ads: []Ad{{InLine: &InLine{Creatives: []Creative{{Linear: &Linear{Duration: Duration(time.Second)}}}}}}}, `{"Ad":[{"InLine":{"Creatives":[{"Linear":{"Duration":null}}]}}]}`},
+		{"nested", VAST{}, `{"Ad":[{"InLine":{"AdSystem":{"Data":"x"},"Expires":3600,"Creatives":[{"Sequence":1,"Linear":{"SkipOffset":"10%","Duration":"00:00:15","TrackingEvents":{"Tracking":[{"Event":"start","Offset":"00:00:01"}]}}}]}}]}`},
+		{"pointers", VAST{}, `{"Ad":[{"Wrapper":{"FallbackOnNoAd":true,"AllowMultipleAds":null}}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := tt.init, tt.init
+			want.Ads = append([]Ad(nil), tt.init.Ads...)
+			assert.NoError(t, json.Unmarshal([]byte(tt.data), mirror(&want)))
+			assert.NoError(t, json.Unmarshal([]byte(tt.data), &got))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCodecUnmarshalJSONTypeError(t *testing.T) {
+	for _, data := range []string{
+		`{"Version":1,"Ad":[{"ID":"1"}]}`,
+		`{"Mute":"yes","Ad":[{"ID":"1"}]}`,
+		`{"Ad":{"ID":"1"},"Version":"4.0"}`,
+		`{"Ad":[{"Sequence":1.5,"ID":"1"}],"Version":"4.0"}`,
+		`{"Ad":[{"InLine":{"Creatives":[{"Linear":{"Duration":1}}]}}],"Version":"4.0"}`,
+		`{"Ad":[{"InLine":{"AdSystem":"x","AdServingId":"1"}}],"Version":"4.0"}`,
+		`{"Ad":[{"InLine":{"Expires":"x","AdServingId":"1"}}],"Version":"4.0"}`,
+	} {
+		t.Run(data, func(t *testing.T) {
+			var got, want VAST
+			errWant := json.Unmarshal([]byte(data), mirror(&want))
+			errGot := json.Unmarshal([]byte(data), &got)
+			assert.Equal(t, want, got)
+			if assert.IsType(t, &json.UnmarshalTypeError{}, errWant) && assert.IsType(t, &json.UnmarshalTypeError{}, errGot) {
+				assert.Equal(t, errWant.(*json.UnmarshalTypeError).Value, errGot.(*json.UnmarshalTypeError).Value)
+				// the mirror types are named after the generated ones
+				typ := strings.Replace(errWant.(*json.UnmarshalTypeError).Type.String(), "vast.reflect", "vast.", -1)
+				assert.Equal(t, typ, errGot.(*json.UnmarshalTypeError).Type.String())
+			}
+		})
+	}
+}
+
+func TestCodecUnmarshalJSONSyntaxError(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`{`,
+		`{"Version"}`,
+		`{"Version":"4.0",}`,
+		`{"Version":"4.0"} x`,
+		`{"Ad":[{},]}`,
+		`{"Ad":[{}`,
+		`{"Version":"\x"}`,
+		`{"Version":"\u12"}`,
+		"{\"Version\":\"\x01\"}",
+		`{"Foo":[-]}`,
+		`{"Foo":01}`,
+		`{"Foo":1.}`,
+		`{"Foo":1e}`,
+		`{"Foo":nul}`,
+		`{"Foo":[[[[` + string(bytes.Repeat([]byte("["), maxJSONDepth)),
+	} {
+		t.Run(data, func(t *testing.T) {
+			var v VAST
+			assert.Error(t, v.UnmarshalJSON([]byte(data)))
+		})
+	}
+}
+
+func BenchmarkVastMarshalXMLReflect(b *testing.B) {
+	v, _ := createVastDemo()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := marshalXMLReflect(v, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVastMarshalXMLGenerated(b *testing.B) {
+	v, _ := createVastDemo()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := xml.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVastMarshalJSONReflect(b *testing.B) {
+	v, _ := createVastDemo()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(mirror(v)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVastMarshalJSONGenerated(b *testing.B) {
+	v, _ := createVastDemo()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkFixture(b *testing.B) []byte {
+	data, err := ioutil.ReadFile("testdata/iab/vast_4.2_samples/Inline_Linear_Tag-test.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkVastUnmarshalXMLReflect(b *testing.B) {
+	data := benchmarkFixture(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v VAST
+		if err := xml.Unmarshal(data, mirror(&v)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVastUnmarshalXMLGenerated(b *testing.B) {
+	data := benchmarkFixture(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v VAST
+		if err := xml.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVastUnmarshalJSONReflect(b *testing.B) {
+	var v VAST
+	if err := xml.Unmarshal(benchmarkFixture(b), &v); err != nil {
+		b.Fatal(err)
+	}
+	data, _ := json.Marshal(&v)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v VAST
+		if err := json.Unmarshal(data, mirror(&v)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVastUnmarshalJSONGenerated(b *testing.B) {
+	var v VAST
+	if err := xml.Unmarshal(benchmarkFixture(b), &v); err != nil {
+		b.Fatal(err)
+	}
+	data, _ := json.Marshal(&v)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v VAST
+		if err := json.Unmarshal(data, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
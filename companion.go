@@ -0,0 +1,296 @@
+package vast
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Values of CompanionAds.Required.
+const (
+	// The player must display all the companions.
+	CompanionsRequiredAll = "all"
+	// The player must display at least one companion.
+	CompanionsRequiredAny = "any"
+	// The companions are optional, the default.
+	CompanionsRequiredNone = "none"
+)
+
+// Values of Companion.RenderingMode.
+const (
+	// The player decides when to display the companion.
+	RenderingModeDefault = "default"
+	// The companion is displayed in the player once the linear ad is over.
+	RenderingModeEndCard = "end-card"
+	// The companion is displayed alongside the linear ad.
+	RenderingModeConcurrent = "concurrent"
+)
+
+// ErrCompanionRequired is returned by CompanionMatch.Err when the companions
+// the CompanionAds require can't be displayed, in which case the linear ad
+// must not be played either.
+var ErrCompanionRequired = errors.New("required companion can't be displayed")
+
+// ResourceKind is the kind of resource of a companion.
+type ResourceKind int
+
+// Resource kinds, in order of preference.
+const (
+	ResourceStatic ResourceKind = iota
+	ResourceIFrame
+	ResourceHTML
+)
+
+// String implements the fmt.Stringer interface.
+func (k ResourceKind) String() string {
+	switch k {
+	case ResourceStatic:
+		return "StaticResource"
+	case ResourceIFrame:
+		return "IFrameResource"
+	case ResourceHTML:
+		return "HTMLResource"
+	}
+	return fmt.Sprintf("ResourceKind(%d)", int(k))
+}
+
+// CompanionSlot is a place where a companion can be displayed, for
+// MatchCompanions.
+type CompanionSlot struct {
+	// ID of the slot, matched against the AdSlotID of the companions having
+	// one.
+	ID string
+	// Size of the slot in pixels. Larger companions don't fit, and zero
+	// doesn't constrain the size.
+	Width, Height int
+	// Pixel ratio of the screen, 1 if zero. Companions meant for it are
+	// preferred.
+	Pxratio float64
+	// Supported kinds of resources, all of them if empty.
+	Resources []ResourceKind
+	// EndCard is set for the slot of the player displaying the end cards
+	// once the linear ad is over, rather than a slot displayed alongside it.
+	EndCard bool
+}
+
+// CompanionResource is the resource to render for a companion.
+type CompanionResource struct {
+	Kind ResourceKind
+	// URI of a static or iframe resource.
+	URI string
+	// MIME type of a static resource, e.g. "image/png".
+	CreativeType string
+	// HTML of an HTML resource, decoded if it was XML-encoded.
+	HTML string
+}
+
+// CompanionPlacement is a companion displayed in a slot.
+type CompanionPlacement struct {
+	Companion *Companion
+	// Slot points to one of the slots given to MatchCompanions.
+	Slot     *CompanionSlot
+	Resource CompanionResource
+}
+
+// UnplacedCompanion is a companion MatchCompanions couldn't place.
+type UnplacedCompanion struct {
+	Companion *Companion
+	// Why the companion wasn't placed.
+	Reason string
+}
+
+// CompanionMatch is the result of MatchCompanions.
+type CompanionMatch struct {
+	// Placed companions, in the order of their slots.
+	Placements []CompanionPlacement
+	// Companions left out, in document order.
+	Unplaced []UnplacedCompanion
+	// RequiredMet reports whether the Required rule of the CompanionAds is
+	// met.
+	RequiredMet bool
+}
+
+// Err returns ErrCompanionRequired if the Required rule of the CompanionAds
+// isn't met, nil otherwise.
+func (m *CompanionMatch) Err() error {
+	if m.RequiredMet {
+		return nil
+	}
+	return ErrCompanionRequired
+}
+
+// MatchCompanions places the companions of ca in the available slots, each
+// slot receiving at most one companion.
+//
+// A companion fits a slot if it isn't larger than the slot, has one of the
+// resources supported by the slot and, if it has an AdSlotID, if it is the
+// ID of the slot. End-card companions only go in end card slots and
+// concurrent ones in the other slots, while the other companions go in
+// either. As many companions as possible are placed, each one preferring the
+// slot matching its AdSlotID, then its pixel ratio, then its size.
+//
+// The resource of a placed companion is the first one of its static, iframe
+// and HTML resources supported by the slot.
+func MatchCompanions(ca *CompanionAds, slots []CompanionSlot) *CompanionMatch {
+	m := &CompanionMatch{RequiredMet: true}
+	if ca == nil {
+		return m
+	}
+	// fits[i] lists the slots the i-th companion fits in, best first
+	fits := make([][]int, len(ca.Companions))
+	reasons := make([]string, len(ca.Companions))
+	for i := range ca.Companions {
+		c := &ca.Companions[i]
+		if len(c.resources()) == 0 {
+			reasons[i] = "no resource"
+			continue
+		}
+		reasons[i] = "no slot fits"
+		for j := range slots {
+			if _, ok := c.fit(&slots[j]); ok {
+				fits[i] = append(fits[i], j)
+			}
+		}
+		sort.SliceStable(fits[i], func(a, b int) bool {
+			return c.prefers(&slots[fits[i][a]], &slots[fits[i][b]])
+		})
+		if len(fits[i]) > 0 {
+			reasons[i] = "fitting slots taken by other companions"
+		}
+	}
+
+	// find a maximum matching between the companions and the slots with
+	// augmenting paths
+	slotOwner := make([]int, len(slots))
+	for j := range slotOwner {
+		slotOwner[j] = -1
+	}
+	var augment func(i int, seen []bool) bool
+	augment = func(i int, seen []bool) bool {
+		for _, j := range fits[i] {
+			if seen[j] {
+				continue
+			}
+			seen[j] = true
+			if slotOwner[j] < 0 || augment(slotOwner[j], seen) {
+				slotOwner[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range fits {
+		augment(i, make([]bool, len(slots)))
+	}
+
+	placed := make([]bool, len(ca.Companions))
+	for j, i := range slotOwner {
+		if i < 0 {
+			continue
+		}
+		c := &ca.Companions[i]
+		res, _ := c.fit(&slots[j])
+		m.Placements = append(m.Placements, CompanionPlacement{Companion: c, Slot: &slots[j], Resource: res})
+		placed[i] = true
+	}
+	for i := range ca.Companions {
+		if !placed[i] {
+			m.Unplaced = append(m.Unplaced, UnplacedCompanion{Companion: &ca.Companions[i], Reason: reasons[i]})
+		}
+	}
+
+	switch strings.TrimSpace(ca.Required) {
+	case CompanionsRequiredAll:
+		m.RequiredMet = len(m.Unplaced) == 0
+	case CompanionsRequiredAny:
+		m.RequiredMet = len(ca.Companions) == 0 || len(m.Placements) > 0
+	}
+	return m
+}
+
+// resources returns the resources of c, in order of preference.
+func (c *Companion) resources() []CompanionResource {
+	var res []CompanionResource
+	if r := c.StaticResource; r != nil && !blank(r.URI) {
+		res = append(res, CompanionResource{Kind: ResourceStatic, URI: strings.TrimSpace(r.URI), CreativeType: strings.TrimSpace(r.CreativeType)})
+	}
+	if r := c.IFrameResource; r != nil && !blank(r.CDATA) {
+		res = append(res, CompanionResource{Kind: ResourceIFrame, URI: strings.TrimSpace(r.CDATA)})
+	}
+	if r := c.HTMLResource; r != nil && !blank(r.HTML) {
+		h := r.HTML
+		if r.XMLEncoded {
+			h = html.UnescapeString(h)
+		}
+		res = append(res, CompanionResource{Kind: ResourceHTML, HTML: strings.TrimSpace(h)})
+	}
+	return res
+}
+
+// fit returns the resource to render c in the slot s, if it fits.
+func (c *Companion) fit(s *CompanionSlot) (CompanionResource, bool) {
+	if id := strings.TrimSpace(c.AdSlotID); id != "" && id != s.ID {
+		return CompanionResource{}, false
+	}
+	if s.Width > 0 && c.Width > s.Width || s.Height > 0 && c.Height > s.Height {
+		return CompanionResource{}, false
+	}
+	switch strings.TrimSpace(c.RenderingMode) {
+	case RenderingModeEndCard:
+		if !s.EndCard {
+			return CompanionResource{}, false
+		}
+	case RenderingModeConcurrent:
+		if s.EndCard {
+			return CompanionResource{}, false
+		}
+	}
+	for _, r := range c.resources() {
+		if len(s.Resources) == 0 {
+			return r, true
+		}
+		for _, k := range s.Resources {
+			if r.Kind == k {
+				return r, true
+			}
+		}
+	}
+	return CompanionResource{}, false
+}
+
+// prefers reports whether c would rather be displayed in the slot a than in
+// the slot b, both of which it fits.
+func (c *Companion) prefers(a, b *CompanionSlot) bool {
+	if id := strings.TrimSpace(c.AdSlotID); id != "" && (a.ID == id) != (b.ID == id) {
+		return a.ID == id
+	}
+	ratio := 1.0
+	if r, err := strconv.ParseFloat(strings.TrimSpace(c.Pxratio), 64); err == nil && r > 0 {
+		ratio = r
+	}
+	if ma, mb := slotPxratio(a) == ratio, slotPxratio(b) == ratio; ma != mb {
+		return ma
+	}
+	// the tightest slot wastes the least space
+	return slotArea(a) < slotArea(b)
+}
+
+// slotArea returns the area of the slot s, the slots of unknown size coming
+// last.
+func slotArea(s *CompanionSlot) int {
+	if s.Width <= 0 || s.Height <= 0 {
+		return math.MaxInt32
+	}
+	return s.Width * s.Height
+}
+
+func slotPxratio(s *CompanionSlot) float64 {
+	if s.Pxratio <= 0 {
+		return 1
+	}
+	return s.Pxratio
+}
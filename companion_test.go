@@ -0,0 +1,187 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func placementIDs(m *CompanionMatch) map[string]string {
+	ids := make(map[string]string, len(m.Placements))
+	for _, p := range m.Placements {
+		ids[p.Companion.ID] = p.Slot.ID
+	}
+	return ids
+}
+
+func unplacedReasons(m *CompanionMatch) map[string]string {
+	reasons := make(map[string]string, len(m.Unplaced))
+	for _, u := range m.Unplaced {
+		reasons[u.Companion.ID] = u.Reason
+	}
+	return reasons
+}
+
+func TestMatchCompanions(t *testing.T) {
+	static := &StaticResource{CreativeType: "image/png", URI: " http://c/banner.png "}
+	ca := &CompanionAds{Companions: []Companion{
+		{ID: "banner", Width: 300, Height: 250, StaticResource: static},
+		{ID: "leader", Width: 728, Height: 90, StaticResource: static},
+		{ID: "sidebar", Width: 300, Height: 250, AdSlotID: "side", StaticResource: static},
+		{ID: "empty", Width: 300, Height: 250},
+		{ID: "huge", Width: 2000, Height: 2000, StaticResource: static},
+	}}
+	slots := []CompanionSlot{
+		{ID: "top", Width: 970, Height: 250},
+		{ID: "side", Width: 300, Height: 600},
+		{ID: "box", Width: 300, Height: 250},
+	}
+	m := MatchCompanions(ca, slots)
+	assert.Equal(t, map[string]string{
+		"banner":  "box",
+		"leader":  "top",
+		"sidebar": "side",
+	}, placementIDs(m))
+	assert.Equal(t, map[string]string{
+		"empty": "no resource",
+		"huge":  "no slot fits",
+	}, unplacedReasons(m))
+	assert.True(t, m.RequiredMet)
+	assert.NoError(t, m.Err())
+	for _, p := range m.Placements {
+		assert.Equal(t, CompanionResource{Kind: ResourceStatic, URI: "http://c/banner.png", CreativeType: "image/png"}, p.Resource)
+	}
+
+	// the banner gives up the box for the only companion fitting it
+	ca = &CompanionAds{Companions: []Companion{
+		{ID: "banner", Width: 300, Height: 250, StaticResource: static},
+		{ID: "tall", Width: 300, Height: 600, StaticResource: static},
+		{ID: "third", Width: 300, Height: 250, StaticResource: static},
+	}}
+	m = MatchCompanions(ca, []CompanionSlot{
+		{ID: "box", Width: 300, Height: 250},
+		{ID: "side", Width: 300, Height: 600},
+	})
+	assert.Equal(t, map[string]string{"banner": "box", "tall": "side"}, placementIDs(m))
+	assert.Equal(t, map[string]string{"third": "fitting slots taken by other companions"}, unplacedReasons(m))
+
+	m = MatchCompanions(nil, slots)
+	assert.Empty(t, m.Placements)
+	assert.True(t, m.RequiredMet)
+}
+
+func TestMatchCompanionsPreferences(t *testing.T) {
+	static := &StaticResource{URI: "http://c/banner.png"}
+	slots := []CompanionSlot{
+		{ID: "any"},
+		{ID: "big", Width: 600, Height: 500},
+		{ID: "retina", Width: 600, Height: 500, Pxratio: 2},
+		{ID: "small", Width: 300, Height: 250},
+	}
+	tests := []struct {
+		c    Companion
+		want string
+	}{
+		{Companion{ID: "c", Width: 300, Height: 250, StaticResource: static}, "small"},
+		{Companion{ID: "c", Width: 300, Height: 250, Pxratio: "2", StaticResource: static}, "retina"},
+		{Companion{ID: "c", Width: 600, Height: 500, StaticResource: static}, "big"},
+		{Companion{ID: "c", Width: 600, Height: 600, StaticResource: static}, "any"},
+		{Companion{ID: "c", AdSlotID: "any", StaticResource: static}, "any"},
+	}
+	for _, tt := range tests {
+		m := MatchCompanions(&CompanionAds{Companions: []Companion{tt.c}}, slots)
+		assert.Equal(t, map[string]string{"c": tt.want}, placementIDs(m), "%+v", tt.c)
+	}
+}
+
+func TestMatchCompanionsRenderingMode(t *testing.T) {
+	iframe := &CDATAString{"http://c/frame.html"}
+	ca := &CompanionAds{Companions: []Companion{
+		{ID: "end", RenderingMode: RenderingModeEndCard, IFrameResource: iframe},
+		{ID: "concurrent", RenderingMode: RenderingModeConcurrent, IFrameResource: iframe},
+	}}
+	m := MatchCompanions(ca, []CompanionSlot{
+		{ID: "page"},
+		{ID: "player", EndCard: true},
+	})
+	assert.Equal(t, map[string]string{"end": "player", "concurrent": "page"}, placementIDs(m))
+
+	m = MatchCompanions(ca, []CompanionSlot{{ID: "player", EndCard: true}})
+	assert.Equal(t, map[string]string{"end": "player"}, placementIDs(m))
+	assert.Equal(t, map[string]string{"concurrent": "no slot fits"}, unplacedReasons(m))
+}
+
+func TestMatchCompanionsResources(t *testing.T) {
+	c := Companion{
+		ID:             "c",
+		IFrameResource: &CDATAString{"http://c/frame.html"},
+		HTMLResource:   &HTMLResource{XMLEncoded: true, HTML: "&lt;b&gt;ad&lt;/b&gt;"},
+	}
+	ca := &CompanionAds{Companions: []Companion{c}}
+
+	m := MatchCompanions(ca, []CompanionSlot{{ID: "s"}})
+	if assert.Len(t, m.Placements, 1) {
+		assert.Equal(t, CompanionResource{Kind: ResourceIFrame, URI: "http://c/frame.html"}, m.Placements[0].Resource)
+	}
+	m = MatchCompanions(ca, []CompanionSlot{{ID: "s", Resources: []ResourceKind{ResourceStatic, ResourceHTML}}})
+	if assert.Len(t, m.Placements, 1) {
+		assert.Equal(t, CompanionResource{Kind: ResourceHTML, HTML: "<b>ad</b>"}, m.Placements[0].Resource)
+	}
+	m = MatchCompanions(ca, []CompanionSlot{{ID: "s", Resources: []ResourceKind{ResourceStatic}}})
+	assert.Empty(t, m.Placements)
+	assert.Equal(t, map[string]string{"c": "no slot fits"}, unplacedReasons(m))
+
+	assert.Equal(t, "HTMLResource", ResourceHTML.String())
+	assert.Equal(t, "ResourceKind(7)", ResourceKind(7).String())
+}
+
+func TestMatchCompanionsRequired(t *testing.T) {
+	static := &StaticResource{URI: "http://c/banner.png"}
+	companions := []Companion{
+		{ID: "fits", Width: 300, Height: 250, StaticResource: static},
+		{ID: "large", Width: 728, Height: 90, StaticResource: static},
+	}
+	slots := []CompanionSlot{{ID: "box", Width: 300, Height: 250}}
+	tests := []struct {
+		required string
+		slots    []CompanionSlot
+		want     bool
+	}{
+		{"", slots, true},
+		{CompanionsRequiredNone, nil, true},
+		{CompanionsRequiredAny, slots, true},
+		{CompanionsRequiredAny, nil, false},
+		{CompanionsRequiredAll, slots, false},
+		{CompanionsRequiredAll, append(slots, CompanionSlot{ID: "top", Width: 970, Height: 90}), true},
+	}
+	for _, tt := range tests {
+		m := MatchCompanions(&CompanionAds{Required: tt.required, Companions: companions}, tt.slots)
+		assert.Equal(t, tt.want, m.RequiredMet, "%q %v", tt.required, tt.slots)
+		if tt.want {
+			assert.NoError(t, m.Err())
+		} else {
+			assert.Equal(t, ErrCompanionRequired, m.Err())
+		}
+	}
+
+	m := MatchCompanions(&CompanionAds{Required: CompanionsRequiredAny}, nil)
+	assert.True(t, m.RequiredMet)
+}
+
+func TestMatchCompanionsTestdata(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/iab/vast_4.2_samples/Inline_Companion_Tag-test.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ca := v.Ads[0].InLine.Creatives[0].CompanionAds
+	m := MatchCompanions(ca, []CompanionSlot{
+		{ID: "other", Width: 300, Height: 250},
+		{ID: "3214", Width: 100, Height: 150},
+	})
+	if assert.Len(t, m.Placements, 1) {
+		p := m.Placements[0]
+		assert.Equal(t, "3214", p.Slot.ID)
+		assert.Equal(t, ResourceStatic, p.Resource.Kind)
+		assert.Equal(t, "https://www.iab.com/wp-content/uploads/2014/09/iab-tech-lab-6-644x290.png", p.Resource.URI)
+	}
+}
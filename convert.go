@@ -0,0 +1,284 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// ExtensionTypeAdVerifications is the type of the Extension carrying the
+// AdVerifications of documents older than VAST 4.1, as specified by the Open
+// Measurement SDK.
+const ExtensionTypeAdVerifications = "AdVerifications"
+
+// Loss is an element or attribute removed, moved or made up by Convert
+// because of the target version.
+type Loss struct {
+	// Path locates the element in the source document, like the Path of a
+	// Diagnostic.
+	Path string
+	// Message tells what happened to the element.
+	Message string
+}
+
+func (l Loss) String() string {
+	return l.Path + ": " + l.Message
+}
+
+// Convert returns a copy of v rewritten for the given VAST version, one of
+// the versions supported by Validate, along with the list of what couldn't
+// be carried over. v isn't modified.
+//
+// Converting to an older version drops the elements and attributes it
+// doesn't define. The AdVerifications of VAST 4.1 are moved into an
+// Extension of type "AdVerifications", which is how Open Measurement expects
+// them in older documents.
+//
+// Converting to VAST 4.1 or later lifts such extensions back into the
+// AdVerifications field. Converting to VAST 4 or later sets an "unknown"
+// UniversalAdId on the creatives without one, as the spec requires, and
+// reports it as a Loss since the document didn't carry it.
+func Convert(v *VAST, version string) (*VAST, []Loss, error) {
+	target, ok := versionNumbers[version]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported VAST version %q", version)
+	}
+	res, err := cloneVAST(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	res.Version = version
+	c := &converter{version: target}
+	for i := range res.Ads {
+		c.convertAd(index("Ads", i), &res.Ads[i])
+	}
+	return res, c.losses, nil
+}
+
+// cloneVAST deep copies v by marshaling it, unknown content included.
+func cloneVAST(v *VAST) (*VAST, error) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res VAST
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	defer keepUnknown(dec)()
+	if err := dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+type converter struct {
+	version int
+	losses  []Loss
+}
+
+// drop reports whether an element present at path must be dropped because
+// it was introduced after the target version, recording the loss. The
+// element is named as in the introduced table of Validate.
+func (c *converter) drop(path string, present bool, name string) bool {
+	since := introduced[name]
+	if !present || c.version >= since {
+		return false
+	}
+	c.losses = append(c.losses, Loss{Path: path, Message: fmt.Sprintf("dropped, not defined before VAST %d.%d", since/10, since%10)})
+	return true
+}
+
+func (c *converter) convertAd(path string, ad *Ad) {
+	if c.drop(path+".Sequence", ad.Sequence != 0, "Ad.Sequence") {
+		ad.Sequence = 0
+	}
+	if c.drop(path+".ConditionalAd", ad.ConditionalAd, "Ad.ConditionalAd") {
+		ad.ConditionalAd = false
+	}
+	if c.drop(path+".AdType", ad.AdType != "", "Ad.AdType") {
+		ad.AdType = ""
+	}
+	if in := ad.InLine; in != nil {
+		path := path + ".InLine"
+		if c.drop(path+".Pricing", in.Pricing != nil, "Pricing") {
+			in.Pricing = nil
+		}
+		if c.drop(path+".AdServingId", in.AdServingId != "", "InLine.AdServingId") {
+			in.AdServingId = ""
+		}
+		if c.drop(path+".Advertiser", in.Advertiser != nil, "InLine.Advertiser") {
+			in.Advertiser = nil
+		}
+		if c.drop(path+".Category", in.Category != nil, "InLine.Category") {
+			in.Category = nil
+		}
+		if c.drop(path+".Expires", in.Expires != nil, "InLine.Expires") {
+			in.Expires = nil
+		}
+		if c.drop(path+".ViewableImpression", in.ViewableImpression != nil, "ViewableImpression") {
+			in.ViewableImpression = nil
+		}
+		c.convertAdVerifications(path, &in.AdVerifications, &in.Extensions)
+		for i := range in.Creatives {
+			c.convertCreative(index(path+".Creatives", i), &in.Creatives[i])
+		}
+	}
+	if w := ad.Wrapper; w != nil {
+		path := path + ".Wrapper"
+		if c.drop(path+".FollowAdditionalWrappers", w.FollowAdditionalWrappers != nil, "Wrapper.FollowAdditionalWrappers") {
+			w.FollowAdditionalWrappers = nil
+		}
+		if c.drop(path+".AllowMultipleAds", w.AllowMultipleAds != nil, "Wrapper.AllowMultipleAds") {
+			w.AllowMultipleAds = nil
+		}
+		if c.drop(path+".FallbackOnNoAd", w.FallbackOnNoAd != nil, "Wrapper.FallbackOnNoAd") {
+			w.FallbackOnNoAd = nil
+		}
+		if c.drop(path+".Pricing", w.Pricing != nil, "Pricing") {
+			w.Pricing = nil
+		}
+		if c.drop(path+".ViewableImpression", w.ViewableImpression != nil, "ViewableImpression") {
+			w.ViewableImpression = nil
+		}
+		c.convertAdVerifications(path, &w.AdVerifications, &w.Extensions)
+		for i := range w.Creatives {
+			c.convertCreativeWrapper(index(path+".Creatives", i), &w.Creatives[i])
+		}
+	}
+}
+
+// convertAdVerifications moves the AdVerifications of an ad to or from its
+// extensions depending on the target version.
+func (c *converter) convertAdVerifications(path string, av **AdVerifications, exts **[]Extension) {
+	if c.version >= introduced["AdVerifications"] {
+		if *av != nil || *exts == nil {
+			return
+		}
+		for i, ext := range **exts {
+			if ext.Type != ExtensionTypeAdVerifications {
+				continue
+			}
+			var lifted AdVerifications
+			if err := ext.Decode(&lifted); err != nil {
+				c.losses = append(c.losses, Loss{Path: index(path+".Extensions", i), Message: "invalid AdVerifications extension: " + err.Error()})
+				return
+			}
+			*av = &lifted
+			rest := append(append([]Extension{}, (**exts)[:i]...), (**exts)[i+1:]...)
+			*exts = &rest
+			if len(rest) == 0 {
+				*exts = nil
+			}
+			return
+		}
+		return
+	}
+	if *av == nil {
+		return
+	}
+	b, err := xml.Marshal(*av)
+	if err != nil {
+		c.drop(path+".AdVerifications", true, "AdVerifications")
+		*av = nil
+		return
+	}
+	if *exts == nil {
+		*exts = &[]Extension{}
+	}
+	**exts = append(**exts, Extension{Type: ExtensionTypeAdVerifications, Data: string(b)})
+	*av = nil
+	c.losses = append(c.losses, Loss{Path: path + ".AdVerifications", Message: "moved to an Extension of type AdVerifications"})
+}
+
+func (c *converter) convertCreative(path string, cr *Creative) {
+	if cr.UniversalAdID == nil && c.version >= introduced["Creative.UniversalAdID"] {
+		cr.UniversalAdID = &[]UniversalAdID{{IDRegistry: "unknown", ID: "unknown"}}
+		c.losses = append(c.losses, Loss{Path: path + ".UniversalAdID", Message: "missing, set to the unknown placeholder"})
+	}
+	if c.drop(path+".UniversalAdID", cr.UniversalAdID != nil, "Creative.UniversalAdID") {
+		cr.UniversalAdID = nil
+	}
+	if c.drop(path+".CreativeExtensions", cr.CreativeExtensions != nil, "Creative.CreativeExtensions") {
+		cr.CreativeExtensions = nil
+	}
+	if l := cr.Linear; l != nil {
+		path := path + ".Linear"
+		if c.drop(path+".SkipOffset", l.SkipOffset != nil, "Linear.SkipOffset") {
+			l.SkipOffset = nil
+		}
+		if c.drop(path+".Icons", l.Icons != nil, "Icons") {
+			l.Icons = nil
+		}
+		l.TrackingEvents = c.convertTrackingEvents(path+".TrackingEvents", l.TrackingEvents)
+		if m := l.MediaFiles; m != nil {
+			path := path + ".MediaFiles"
+			for i := range m.MediaFile {
+				f := &m.MediaFile[i]
+				fpath := index(path+".MediaFile", i)
+				if c.drop(fpath+".FileSize", f.FileSize != 0, "MediaFile.FileSize") {
+					f.FileSize = 0
+				}
+				if c.drop(fpath+".MediaType", f.MediaType != "", "MediaFile.MediaType") {
+					f.MediaType = ""
+				}
+			}
+			if c.drop(path+".Mezzanine", len(m.Mezzanine) > 0, "MediaFiles.Mezzanine") {
+				m.Mezzanine = nil
+			}
+			if c.drop(path+".InteractiveCreativeFile", len(m.InteractiveCreativeFile) > 0, "MediaFiles.InteractiveCreativeFile") {
+				m.InteractiveCreativeFile = nil
+			}
+			if c.drop(path+".ClosedCaptionFiles", m.ClosedCaptionFiles != nil, "MediaFiles.ClosedCaptionFiles") {
+				m.ClosedCaptionFiles = nil
+			}
+		}
+	}
+	c.convertCompanionAds(path+".CompanionAds", cr.CompanionAds)
+	if nla := cr.NonLinearAds; nla != nil {
+		nla.TrackingEvents = c.convertTrackingEvents(path+".NonLinearAds.TrackingEvents", nla.TrackingEvents)
+	}
+}
+
+func (c *converter) convertCreativeWrapper(path string, cr *CreativeWrapper) {
+	if l := cr.Linear; l != nil {
+		if c.drop(path+".Linear.Icons", l.Icons != nil, "Icons") {
+			l.Icons = nil
+		}
+		l.TrackingEvents = c.convertTrackingEvents(path+".Linear.TrackingEvents", l.TrackingEvents)
+	}
+	c.convertCompanionAds(path+".CompanionAds", cr.CompanionAds)
+	if nla := cr.NonLinearAds; nla != nil {
+		nla.TrackingEvents = c.convertTrackingEvents(path+".NonLinearAds.TrackingEvents", nla.TrackingEvents)
+	}
+}
+
+func (c *converter) convertCompanionAds(path string, ca *CompanionAds) {
+	if ca == nil {
+		return
+	}
+	for i := range ca.Companions {
+		comp := &ca.Companions[i]
+		cpath := index(path+".Companions", i)
+		if c.drop(cpath+".RenderingMode", comp.RenderingMode != "", "Companion.RenderingMode") {
+			comp.RenderingMode = ""
+		}
+		comp.TrackingEvents = c.convertTrackingEvents(cpath+".TrackingEvents", comp.TrackingEvents)
+	}
+}
+
+// convertTrackingEvents drops the progress events before VAST 3.0.
+func (c *converter) convertTrackingEvents(path string, te *TrackingEvents) *TrackingEvents {
+	if te == nil || c.version >= introduced["Tracking.progress"] {
+		return te
+	}
+	var kept []Tracking
+	for i, t := range te.Tracking {
+		if !c.drop(index(path+".Tracking", i), t.Event == EventTypeProgress, "Tracking.progress") {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	te.Tracking = kept
+	return te
+}
@@ -0,0 +1,133 @@
+package vast
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertIABSamples(t *testing.T) {
+	samples, err := filepath.Glob("testdata/iab/vast_4.2_samples/*.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, sample := range samples {
+		for _, version := range []string{Version2, Version3, Version4} {
+			v, _, _, err := loadFixture(sample)
+			if !assert.NoError(t, err) {
+				return
+			}
+			before, _, _, _ := loadFixture(sample)
+
+			res, losses, err := Convert(v, version)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, before, v, "source modified")
+			assert.Equal(t, version, res.Version)
+			for _, d := range Validate(res, version) {
+				assert.NotContains(t, d.Message, "not defined before", "%s %s: %s", sample, version, d)
+			}
+			for _, l := range losses {
+				assert.True(t, strings.HasPrefix(l.Path, "Ads["), "%s", l)
+			}
+		}
+	}
+}
+
+func TestConvertAdVerifications(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/iab/vast_4.2_samples/Ad_Verification-test.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	res, losses, err := Convert(v, Version3)
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := res.Ads[0].InLine
+	assert.Nil(t, in.AdVerifications)
+	if assert.NotNil(t, in.Extensions) {
+		exts := *in.Extensions
+		last := exts[len(exts)-1]
+		assert.Equal(t, ExtensionTypeAdVerifications, last.Type)
+		assert.True(t, strings.HasPrefix(last.Data, "<AdVerifications><Verification><JavaScriptResource>"), last.Data)
+	}
+	assert.Contains(t, losses, Loss{Path: "Ads[0].InLine.AdVerifications", Message: "moved to an Extension of type AdVerifications"})
+	assert.Contains(t, losses, Loss{Path: "Ads[0].InLine.AdServingId", Message: "dropped, not defined before VAST 4.1"})
+
+	up, _, err := Convert(res, Version42)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, v.Ads[0].InLine.AdVerifications, up.Ads[0].InLine.AdVerifications)
+	assert.Equal(t, v.Ads[0].InLine.Extensions, up.Ads[0].InLine.Extensions)
+}
+
+func TestConvertLegacy(t *testing.T) {
+	d := Duration(5)
+	v := &VAST{
+		Version: Version42,
+		Ads: []Ad{{
+			Sequence: 1,
+			AdType:   "video",
+			InLine: &InLine{
+				Creatives: []Creative{
+					{
+						Linear: &Linear{
+							SkipOffset: &Offset{Duration: &d},
+							TrackingEvents: &TrackingEvents{Tracking: []Tracking{
+								{Event: EventTypeProgress, Offset: &Offset{Duration: &d}},
+							}},
+							MediaFiles: &MediaFiles{
+								MediaFile: []MediaFile{{FileSize: 1000, MediaType: "2D"}},
+								Mezzanine: []Mezzanine{{}},
+							},
+						},
+					},
+					{CompanionAds: &CompanionAds{Companions: []Companion{{RenderingMode: "end-card"}}}},
+				},
+			},
+		}},
+	}
+	res, losses, err := Convert(v, Version2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var paths []string
+	for _, l := range losses {
+		paths = append(paths, l.Path)
+	}
+	assert.Equal(t, []string{
+		"Ads[0].Sequence",
+		"Ads[0].AdType",
+		"Ads[0].InLine.Creatives[0].Linear.SkipOffset",
+		"Ads[0].InLine.Creatives[0].Linear.TrackingEvents.Tracking[0]",
+		"Ads[0].InLine.Creatives[0].Linear.MediaFiles.MediaFile[0].FileSize",
+		"Ads[0].InLine.Creatives[0].Linear.MediaFiles.MediaFile[0].MediaType",
+		"Ads[0].InLine.Creatives[0].Linear.MediaFiles.Mezzanine",
+		"Ads[0].InLine.Creatives[1].CompanionAds.Companions[0].RenderingMode",
+	}, paths)
+	l := res.Ads[0].InLine.Creatives[0].Linear
+	assert.Nil(t, l.TrackingEvents)
+	assert.Equal(t, []MediaFile{{}}, l.MediaFiles.MediaFile)
+
+	res, losses, err = Convert(&VAST{Ads: []Ad{{InLine: &InLine{Creatives: []Creative{{}}}}}}, Version4)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &[]UniversalAdID{{IDRegistry: "unknown", ID: "unknown"}}, res.Ads[0].InLine.Creatives[0].UniversalAdID)
+		assert.Equal(t, []Loss{{Path: "Ads[0].InLine.Creatives[0].UniversalAdID", Message: "missing, set to the unknown placeholder"}}, losses)
+	}
+
+	// Advertiser is kept by VAST 3.0, which drops conditionalAd
+	v3 := &VAST{Version: Version42, Ads: []Ad{{ConditionalAd: true, InLine: &InLine{Advertiser: &Advertiser{Advertiser: "brand"}}}}}
+	res, losses, err = Convert(v3, Version3)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Loss{{Path: "Ads[0].ConditionalAd", Message: "dropped, not defined before VAST 4.0"}}, losses)
+		assert.False(t, res.Ads[0].ConditionalAd)
+		assert.Equal(t, &Advertiser{Advertiser: "brand"}, res.Ads[0].InLine.Advertiser)
+	}
+
+	_, _, err = Convert(v, "5.0")
+	assert.EqualError(t, err, `unsupported VAST version "5.0"`)
+}
@@ -0,0 +1,435 @@
+package vast
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DecodeMode tells Decode how to handle values which can't be decoded.
+type DecodeMode int
+
+const (
+	// Strict fails on the first value which can't be decoded.
+	Strict DecodeMode = iota
+	// Lenient replaces the values which can't be decoded with their zero
+	// value and reports them as warnings.
+	Lenient
+)
+
+// DecodeError is a value of a document which couldn't be decoded, such as a
+// malformed Duration.
+type DecodeError struct {
+	// Path is the XML path of the element or attribute holding the value,
+	// e.g. "/VAST/Ad[1]/InLine/Creatives/Creative[1]/Linear/Duration" or
+	// "/VAST/Ad[1]/InLine/Creatives/Creative[1]/Linear/@skipoffset".
+	// Repeatable elements are indexed from 1.
+	Path string
+	// Line is the line of the element in the document, starting at 1.
+	Line int
+	// Value is the value which couldn't be decoded.
+	Value string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode implements the ErrorCoder interface.
+func (e *DecodeError) ErrorCode() ErrorCode {
+	return ErrorCodeSchemaValidation
+}
+
+// Decode reads a VAST document from r.
+//
+// Unlike xml.Unmarshal, a value which can't be decoded, such as a Duration
+// of "00:00:60" or an integer attribute of "abc", is reported as a
+// DecodeError locating it. In Strict mode it is returned as the error. In
+// Lenient mode the value is left to its zero value, the DecodeError is added
+// to the returned warnings and decoding goes on. Malformed XML fails in both
+// modes.
+//
+// The elements and attributes not modelled by the package are dropped; see
+// DecodeWith to keep them.
+func Decode(r io.Reader, mode DecodeMode) (*VAST, []*DecodeError, error) {
+	return DecodeWith(r, DecodeOptions{Mode: mode})
+}
+
+// DecodeOptions are the options of DecodeWith.
+type DecodeOptions struct {
+	// Mode tells how to handle values which can't be decoded.
+	Mode DecodeMode
+	// KeepUnknown keeps the elements and attributes not modelled by the
+	// package in the Unknown and UnknownAttrs fields of the major elements,
+	// so that they are written back. They are dropped by default.
+	KeepUnknown bool
+}
+
+// DecodeWith reads a VAST document from r as Decode does, with the options
+// opts.
+func DecodeWith(r io.Reader, opts DecodeOptions) (*VAST, []*DecodeError, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &decodeScanner{
+		dec:  xml.NewDecoder(bytes.NewReader(data)),
+		data: data,
+		mode: opts.Mode,
+	}
+	if err := s.scan(); err != nil {
+		return nil, s.warnings, err
+	}
+	// blank the values which can't be decoded, keeping the rest of the
+	// document byte for byte so that innerxml fields are left untouched
+	if len(s.edits) > 0 {
+		patched := make([]byte, 0, len(data))
+		last := 0
+		for _, e := range s.edits {
+			patched = append(patched, data[last:e[0]]...)
+			last = e[1]
+		}
+		data = append(patched, data[last:]...)
+	}
+	var v VAST
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	if opts.KeepUnknown {
+		defer keepUnknown(dec)()
+	}
+	if err := dec.Decode(&v); err != nil {
+		return nil, s.warnings, err
+	}
+	return &v, s.warnings, nil
+}
+
+// xmlType describes how an element is decoded into a Go type.
+type xmlType struct {
+	// attrs maps attribute names to the type of their field.
+	attrs map[string]reflect.Type
+	// elems maps child element names to their description.
+	elems map[string]*xmlChild
+	// text is set for elements whose content is decoded from text, such as
+	// Duration or int.
+	text reflect.Type
+}
+
+type xmlChild struct {
+	typ      *xmlType
+	repeated bool
+}
+
+var (
+	xmlTypesMu sync.Mutex
+	xmlTypes   = map[reflect.Type]*xmlType{}
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	xmlUnmarshalerType  = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+	tagCodecType        = reflect.TypeOf((*tagCodec)(nil)).Elem()
+)
+
+// typeOf returns the description of t, nil when the content of the element
+// is opaque, e.g. decoded by a custom UnmarshalXML method which isn't
+// generated from the struct tags.
+func typeOf(t reflect.Type) *xmlType {
+	xmlTypesMu.Lock()
+	defer xmlTypesMu.Unlock()
+	return typeOfLocked(t)
+}
+
+func typeOfLocked(t reflect.Type) *xmlType {
+	if xt, ok := xmlTypes[t]; ok {
+		return xt
+	}
+	var xt *xmlType
+	switch {
+	case reflect.PtrTo(t).Implements(xmlUnmarshalerType) && !reflect.PtrTo(t).Implements(tagCodecType):
+	case reflect.PtrTo(t).Implements(textUnmarshalerType), t.Kind() != reflect.Struct:
+		xt = &xmlType{text: t}
+	default:
+		xt = &xmlType{attrs: map[string]reflect.Type{}, elems: map[string]*xmlChild{}}
+	}
+	xmlTypes[t] = xt
+	if xt == nil || xt.elems == nil {
+		return xt
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("xml")
+		if field.PkgPath != "" || field.Name == "XMLName" || tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+		if name == "" {
+			name = field.Name
+		}
+		kind := ""
+		for _, o := range opts[1:] {
+			if o == "any" {
+				// unknown attributes and elements, decoded as is
+				kind = o
+				break
+			}
+			if o != "omitempty" {
+				kind = o
+			}
+		}
+		ft, repeated := field.Type, false
+		for ft.Kind() == reflect.Ptr || (ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8) {
+			if ft.Kind() == reflect.Slice {
+				repeated = true
+			}
+			ft = ft.Elem()
+		}
+		switch kind {
+		case "attr":
+			xt.attrs[name] = ft
+		case "":
+			parent := xt
+			parts := strings.Split(name, ">")
+			for _, p := range parts[:len(parts)-1] {
+				c, ok := parent.elems[p]
+				if !ok {
+					c = &xmlChild{typ: &xmlType{attrs: map[string]reflect.Type{}, elems: map[string]*xmlChild{}}}
+					parent.elems[p] = c
+				}
+				parent = c.typ
+			}
+			parent.elems[parts[len(parts)-1]] = &xmlChild{typ: typeOfLocked(ft), repeated: repeated}
+		}
+	}
+	return xt
+}
+
+// checkText reports whether s can be decoded into a value of type t, the way
+// encoding/xml does.
+func checkText(t reflect.Type, s string) error {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return reflect.New(t).Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if s == "" {
+		return nil
+	}
+	var err error
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(strings.TrimSpace(s), 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(strings.TrimSpace(s), 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(strings.TrimSpace(s), t.Bits())
+	case reflect.Bool:
+		_, err = strconv.ParseBool(strings.TrimSpace(s))
+	}
+	return err
+}
+
+// decodeFrame is an element being scanned.
+type decodeFrame struct {
+	path   string
+	typ    *xmlType
+	counts map[string]int
+}
+
+// decodeScanner walks a document looking for the values which can't be
+// decoded.
+type decodeScanner struct {
+	dec      *xml.Decoder
+	data     []byte
+	mode     DecodeMode
+	stack    []decodeFrame
+	edits    [][2]int
+	warnings []*DecodeError
+
+	// the number of newlines before lineOffset
+	lineOffset int64
+	lines      int
+}
+
+func (s *decodeScanner) scan() error {
+	for {
+		start := s.dec.InputOffset()
+		tok, err := s.dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := s.start(t, start); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			s.stack = s.stack[:len(s.stack)-1]
+		}
+	}
+}
+
+// start checks the attributes of an element starting at offset, and its
+// content if it is decoded from text.
+func (s *decodeScanner) start(t xml.StartElement, offset int64) error {
+	line := s.line(offset)
+	frame := s.push(t.Name.Local)
+	if frame.typ == nil {
+		return nil
+	}
+	for i, attr := range t.Attr {
+		at, ok := frame.typ.attrs[attr.Name.Local]
+		if !ok || attr.Name.Space == "xmlns" {
+			continue
+		}
+		if err := checkText(at, attr.Value); err != nil {
+			if err := s.fail(frame.path+"/@"+attr.Name.Local, line, attr.Value, err); err != nil {
+				return err
+			}
+			if from, to, ok := findAttr(s.data[offset:s.dec.InputOffset()], i); ok {
+				s.edits = append(s.edits, [2]int{int(offset) + from, int(offset) + to})
+			}
+		}
+	}
+	if frame.typ.text == nil {
+		return nil
+	}
+
+	// read the content of the element to check it
+	var text []byte
+	nested := false
+	from, to := s.dec.InputOffset(), s.dec.InputOffset()
+	for depth := 1; depth > 0; {
+		to = s.dec.InputOffset()
+		tok, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch c := tok.(type) {
+		case xml.StartElement:
+			depth++
+			nested = true
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			text = append(text, c...)
+		}
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+	if nested {
+		return nil
+	}
+	if err := checkText(frame.typ.text, string(text)); err != nil {
+		if err := s.fail(frame.path, line, string(text), err); err != nil {
+			return err
+		}
+		s.edits = append(s.edits, [2]int{int(from), int(to)})
+	}
+	return nil
+}
+
+// findAttr returns the byte range of the n-th attribute, including its
+// leading space, in the start tag tag. The attributes of an
+// xml.StartElement are in the order of the tag, so n is the index of the
+// attribute in the token, which tells it apart from the attributes of the
+// same local name in other namespaces.
+func findAttr(tag []byte, n int) (int, int, bool) {
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\r' || c == '\n' }
+	i := 1
+	for i < len(tag) && !isSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+		i++
+	}
+	for i < len(tag) {
+		from := i
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		name := i
+		for i < len(tag) && tag[i] != '=' && !isSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+			i++
+		}
+		if i == name {
+			return 0, 0, false
+		}
+		for i < len(tag) && (isSpace(tag[i]) || tag[i] == '=') {
+			i++
+		}
+		if i == len(tag) || (tag[i] != '"' && tag[i] != '\'') {
+			return 0, 0, false
+		}
+		end := bytes.IndexByte(tag[i+1:], tag[i])
+		if end < 0 {
+			return 0, 0, false
+		}
+		i += end + 2
+		if n == 0 {
+			return from, i, true
+		}
+		n--
+	}
+	return 0, 0, false
+}
+
+// push enters the element name, returning its frame.
+func (s *decodeScanner) push(name string) *decodeFrame {
+	var frame decodeFrame
+	if len(s.stack) == 0 {
+		frame.path = "/" + name
+		if name == "VAST" {
+			frame.typ = typeOf(reflect.TypeOf(VAST{}))
+		}
+	} else {
+		parent := &s.stack[len(s.stack)-1]
+		frame.path = parent.path + "/" + name
+		if parent.typ != nil && parent.typ.elems != nil {
+			if c, ok := parent.typ.elems[name]; ok {
+				frame.typ = c.typ
+				if c.repeated {
+					if parent.counts == nil {
+						parent.counts = map[string]int{}
+					}
+					parent.counts[name]++
+					frame.path += "[" + strconv.Itoa(parent.counts[name]) + "]"
+				}
+			}
+		}
+	}
+	s.stack = append(s.stack, frame)
+	return &s.stack[len(s.stack)-1]
+}
+
+// fail records a value which couldn't be decoded, returning it as an error
+// in Strict mode.
+func (s *decodeScanner) fail(path string, line int, value string, err error) error {
+	de := &DecodeError{Path: path, Line: line, Value: value, Err: err}
+	if s.mode == Strict {
+		return de
+	}
+	s.warnings = append(s.warnings, de)
+	return nil
+}
+
+// line returns the line of the byte at offset, starting at 1.
+//
+// The offsets only grow while scanning, so the newlines are counted from
+// the previous offset rather than from the start of the document.
+func (s *decodeScanner) line(offset int64) int {
+	if offset < s.lineOffset {
+		s.lineOffset, s.lines = 0, 0
+	}
+	s.lines += bytes.Count(s.data[s.lineOffset:offset], []byte("\n"))
+	s.lineOffset = offset
+	return s.lines + 1
+}
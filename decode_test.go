@@ -0,0 +1,171 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeTestdata(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	samples, err := filepath.Glob("testdata/iab/*/*.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, file := range append(files, samples...) {
+		t.Run(file, func(t *testing.T) {
+			b, err := os.ReadFile(file)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var want VAST
+			if err := xml.Unmarshal(b, &want); err != nil {
+				return
+			}
+			v, warnings, err := Decode(bytes.NewReader(b), Strict)
+			if assert.NoError(t, err) {
+				assert.Empty(t, warnings)
+				assert.Equal(t, &want, v)
+			}
+		})
+	}
+}
+
+const malformedVAST = `<VAST version="4.2">
+  <Ad id="1">
+    <InLine>
+      <AdSystem>DSP</AdSystem>
+      <AdTitle>one</AdTitle>
+      <Creatives>
+        <Creative id="c1">
+          <Linear skipoffset="12,5%">
+            <Duration>00:00:60</Duration>
+          </Linear>
+        </Creative>
+      </Creatives>
+    </InLine>
+  </Ad>
+  <Ad id="2">
+    <InLine>
+      <AdSystem>DSP</AdSystem>
+      <AdTitle>two</AdTitle>
+      <Creatives>
+        <Creative id="c2">
+          <Linear>
+            <Duration>00:00:15</Duration>
+            <MediaFiles>
+              <MediaFile delivery="progressive" type="video/mp4" width="wide" height="360"><![CDATA[http://media]]></MediaFile>
+            </MediaFiles>
+          </Linear>
+        </Creative>
+        <Creative id="c3">
+          <Linear>
+            <Duration>1:02:03:04</Duration>
+          </Linear>
+        </Creative>
+      </Creatives>
+    </InLine>
+  </Ad>
+</VAST>`
+
+func TestDecodeLenient(t *testing.T) {
+	v, warnings, err := Decode(strings.NewReader(malformedVAST), Lenient)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, v.Ads, 2) {
+		assert.Equal(t, "two", v.Ads[1].InLine.AdTitle.CDATA)
+		creatives := v.Ads[1].InLine.Creatives
+		if assert.Len(t, creatives, 2) {
+			assert.Equal(t, Duration(15e9), creatives[0].Linear.Duration)
+			mf := creatives[0].Linear.MediaFiles.MediaFile[0]
+			assert.Equal(t, 0, mf.Width)
+			assert.Equal(t, 360, mf.Height)
+			assert.Equal(t, "http://media", mf.URI)
+			assert.Equal(t, Duration(0), creatives[1].Linear.Duration)
+		}
+		linear := v.Ads[0].InLine.Creatives[0].Linear
+		assert.Nil(t, linear.SkipOffset)
+		assert.Equal(t, Duration(0), linear.Duration)
+	}
+
+	type warning struct {
+		path  string
+		line  int
+		value string
+	}
+	var got []warning
+	for _, w := range warnings {
+		got = append(got, warning{w.Path, w.Line, w.Value})
+	}
+	assert.Equal(t, []warning{
+		{"/VAST/Ad[1]/InLine/Creatives/Creative[1]/Linear/@skipoffset", 8, "12,5%"},
+		{"/VAST/Ad[1]/InLine/Creatives/Creative[1]/Linear/Duration", 9, "00:00:60"},
+		{"/VAST/Ad[2]/InLine/Creatives/Creative[1]/Linear/MediaFiles/MediaFile[1]/@width", 24, "wide"},
+		{"/VAST/Ad[2]/InLine/Creatives/Creative[2]/Linear/Duration", 30, "1:02:03:04"},
+	}, got)
+}
+
+func TestDecodeLenientNamespacedAttr(t *testing.T) {
+	// only the invalid skipoffset is blanked, not the vendor one before it
+	const doc = `<VAST version="4.2" xmlns:x="http://vendor">
+	<Ad><InLine><Creatives><Creative>
+		<Linear x:skipoffset="00:00:05" skipoffset="soon"><Duration>00:00:15</Duration></Linear>
+	</Creative></Creatives></InLine></Ad>
+</VAST>`
+	v, warnings, err := Decode(strings.NewReader(doc), Lenient)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, "/VAST/Ad[1]/InLine/Creatives/Creative[1]/Linear/@skipoffset", warnings[0].Path)
+		assert.Equal(t, "soon", warnings[0].Value)
+	}
+	d := Duration(5e9)
+	assert.Equal(t, &Offset{Duration: &d}, v.Ads[0].InLine.Creatives[0].Linear.SkipOffset)
+}
+
+func TestDecodeStrict(t *testing.T) {
+	v, _, err := Decode(strings.NewReader(malformedVAST), Strict)
+	assert.Nil(t, v)
+	var de *DecodeError
+	if assert.True(t, errors.As(err, &de), "%v", err) {
+		assert.Equal(t, "/VAST/Ad[1]/InLine/Creatives/Creative[1]/Linear/@skipoffset", de.Path)
+		assert.Equal(t, 8, de.Line)
+		assert.Equal(t, "12,5%", de.Value)
+		assert.True(t, strings.HasPrefix(err.Error(), "line 8: /VAST/Ad[1]/InLine/Creatives/Creative[1]/Linear/@skipoffset: "), err.Error())
+	}
+	assert.Equal(t, ErrorCodeSchemaValidation, ErrorCodeFor(err))
+
+	_, _, err = Decode(strings.NewReader(`<VAST><Ad>`), Lenient)
+	assert.Error(t, err)
+}
+
+func BenchmarkDecodeLenient(b *testing.B) {
+	// a document of several megabytes with a warning in each ad
+	ad := malformedVAST[strings.Index(malformedVAST, "<Ad "):strings.LastIndex(malformedVAST, "</VAST>")]
+	var buf bytes.Buffer
+	buf.WriteString(`<VAST version="4.2">`)
+	for buf.Len() < 4<<20 {
+		buf.WriteString(ad)
+	}
+	buf.WriteString(`</VAST>`)
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := Decode(bytes.NewReader(data), Lenient); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Default limits of a Decoder returned by NewDecoder.
+const (
+	DefaultMaxDocumentSize = 10 << 20
+	DefaultMaxElementDepth = 64
+)
+
+var (
+	// ErrDocumentTooLarge is returned when a document is larger than the
+	// decoder's MaxSize.
+	ErrDocumentTooLarge = errors.New("document too large")
+	// ErrDocumentTooDeep is returned when elements are nested deeper than the
+	// decoder's MaxDepth.
+	ErrDocumentTooDeep = errors.New("document too deep")
+	// ErrTooManyAds is returned when a document has more ads than the
+	// decoder's MaxAds.
+	ErrTooManyAds = errors.New("too many ads")
+)
+
+// Decoder reads the ads of a VAST document one at a time, without holding
+// the whole document in memory.
+//
+// The limits must be set before the first call to Root or Next. Reading can
+// stop at any time; the rest of the input is then left unread.
+type Decoder struct {
+	// MaxSize is the maximum size of the document in bytes, unlimited when
+	// zero.
+	MaxSize int64
+	// MaxDepth is the maximum depth of elements, the root being at depth 1,
+	// unlimited when zero.
+	MaxDepth int
+	// MaxAds is the maximum number of ads, unlimited when zero.
+	MaxAds int
+	// KeepUnknown keeps the elements and attributes not modelled by the
+	// package in the Unknown and UnknownAttrs fields of the major elements.
+	// They are dropped by default.
+	KeepUnknown bool
+
+	r      io.Reader
+	dec    *xml.Decoder
+	root   *VAST
+	peeked *xml.StartElement
+	depth  int
+	ads    int
+	err    error
+}
+
+// NewDecoder returns a Decoder reading from r with the default size and
+// depth limits and no limit on the number of ads.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		MaxSize:  DefaultMaxDocumentSize,
+		MaxDepth: DefaultMaxElementDepth,
+		r:        r,
+	}
+}
+
+// Root returns the root element of the document with its attributes and
+// the Error elements found before the first ad. Its Ads are always empty.
+func (d *Decoder) Root() (*VAST, error) {
+	if err := d.start(); err != nil {
+		return nil, err
+	}
+	return d.root, nil
+}
+
+// Next returns the next ad of the document, or io.EOF when there are no
+// more ads. Errors found on the way are added to the Errors of Root.
+func (d *Decoder) Next() (*Ad, error) {
+	if err := d.start(); err != nil {
+		return nil, err
+	}
+	start, err := d.nextAd()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	if d.MaxAds > 0 && d.ads >= d.MaxAds {
+		d.err = ErrTooManyAds
+		return nil, d.err
+	}
+	if d.KeepUnknown {
+		defer keepUnknown(d.dec)()
+	}
+	var ad Ad
+	if err := d.dec.DecodeElement(&ad, start); err != nil {
+		d.err = err
+		return nil, err
+	}
+	d.ads++
+	return &ad, nil
+}
+
+// start reads the document up to its first ad.
+func (d *Decoder) start() error {
+	if d.err != nil || d.dec != nil {
+		return d.err
+	}
+	var r io.Reader = d.r
+	if d.MaxSize > 0 {
+		r = &limitReader{r: r, n: d.MaxSize}
+	}
+	d.dec = xml.NewTokenDecoder(&depthReader{dec: xml.NewDecoder(r), d: d})
+	for {
+		tok, err := d.dec.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			d.err = err
+			return err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != "VAST" {
+				d.err = fmt.Errorf("expected VAST element, got %s", start.Name.Local)
+				return d.err
+			}
+			d.root = &VAST{}
+			for _, attr := range start.Attr {
+				switch {
+				case attr.Name.Local == "version":
+					d.root.Version = attr.Value
+				case attr.Name.Local == "xmlns" && attr.Name.Space == "":
+					d.root.XMLNS = attr.Value
+				case attr.Name.Local == "mute":
+					d.root.Mute = attr.Value == "true" || attr.Value == "1"
+				}
+			}
+			break
+		}
+	}
+	start, err := d.nextAd()
+	if err != nil && err != io.EOF {
+		d.err = err
+		return err
+	}
+	d.peeked = start
+	return nil
+}
+
+// nextAd reads the children of the root element up to the next ad, decoding
+// the Error elements on the way.
+func (d *Decoder) nextAd() (*xml.StartElement, error) {
+	if d.peeked != nil {
+		start := d.peeked
+		d.peeked = nil
+		return start, nil
+	}
+	if d.depth == 0 {
+		// the root element was closed
+		return nil, io.EOF
+	}
+	for {
+		tok, err := d.dec.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "Ad":
+				return &t, nil
+			case "Error":
+				var e CDATAString
+				if err := d.dec.DecodeElement(&e, &t); err != nil {
+					return nil, err
+				}
+				d.root.Errors = append(d.root.Errors, e)
+			default:
+				if err := d.dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			return nil, io.EOF
+		}
+	}
+}
+
+// depthReader tracks the depth of the elements read from dec.
+type depthReader struct {
+	dec *xml.Decoder
+	d   *Decoder
+}
+
+func (r *depthReader) Token() (xml.Token, error) {
+	tok, err := r.dec.Token()
+	switch tok.(type) {
+	case xml.StartElement:
+		r.d.depth++
+		if r.d.MaxDepth > 0 && r.d.depth > r.d.MaxDepth {
+			return nil, ErrDocumentTooDeep
+		}
+	case xml.EndElement:
+		r.d.depth--
+	}
+	return tok, err
+}
+
+// limitReader reads from r until n bytes were read, then fails with
+// ErrDocumentTooLarge.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// tell a document of exactly n bytes from a larger one
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, ErrDocumentTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,170 @@
+package vast
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	b, err := os.ReadFile("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var want VAST
+	assert.NoError(t, xml.Unmarshal(b, &want))
+
+	d := NewDecoder(strings.NewReader(string(b)))
+	root, err := d.Root()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2.0", root.Version)
+		assert.Empty(t, root.Ads)
+	}
+	var ads []Ad
+	for {
+		ad, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		ads = append(ads, *ad)
+	}
+	assert.Equal(t, want.Ads, ads)
+	_, err = d.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func manyAds(n int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><VAST version="4.2" xmlns="http://www.iab.com/VAST"><Error><![CDATA[http://error]]></Error>`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `<Ad id="%d" sequence="%d"><InLine><AdTitle>ad %d</AdTitle></InLine></Ad>`, i, i+1, i)
+	}
+	b.WriteString(`<Error><![CDATA[http://error2]]></Error></VAST>`)
+	return b.String()
+}
+
+func TestDecoderRoot(t *testing.T) {
+	d := NewDecoder(strings.NewReader(manyAds(300)))
+	root, err := d.Root()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "4.2", root.Version)
+	assert.Equal(t, "http://www.iab.com/VAST", root.XMLNS)
+	assert.Equal(t, []CDATAString{{CDATA: "http://error"}}, root.Errors)
+
+	n := 0
+	for {
+		ad, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, fmt.Sprint(n), ad.ID)
+		n++
+	}
+	assert.Equal(t, 300, n)
+	assert.Len(t, root.Errors, 2)
+
+	d = NewDecoder(strings.NewReader(`<VAST version="3.0"><Error>http://noad</Error></VAST>`))
+	_, err = d.Next()
+	assert.Equal(t, io.EOF, err)
+	root, _ = d.Root()
+	assert.Equal(t, []CDATAString{{CDATA: "http://noad"}}, root.Errors)
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestDecoderEarlyTermination(t *testing.T) {
+	doc := manyAds(10000)
+	r := &countingReader{r: strings.NewReader(doc)}
+	d := NewDecoder(r)
+	ad, err := d.Next()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0", ad.ID)
+	}
+	assert.True(t, r.n < len(doc)/10, "read %d bytes out of %d", r.n, len(doc))
+}
+
+func TestDecoderLimits(t *testing.T) {
+	doc := manyAds(3)
+
+	d := NewDecoder(strings.NewReader(doc))
+	d.MaxSize = int64(len(doc))
+	n := 0
+	for {
+		_, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		n++
+	}
+	assert.Equal(t, 3, n)
+
+	d = NewDecoder(strings.NewReader(doc))
+	d.MaxSize = int64(len(doc) - 1)
+	err := drain(d)
+	assert.True(t, errors.Is(err, ErrDocumentTooLarge), "%v", err)
+	assert.Equal(t, ErrorCodeXMLParsing, ErrorCodeFor(err))
+
+	d = NewDecoder(strings.NewReader(doc))
+	d.MaxAds = 2
+	assert.Equal(t, ErrTooManyAds, drain(d))
+	_, err = d.Next()
+	assert.Equal(t, ErrTooManyAds, err)
+
+	d = NewDecoder(strings.NewReader(doc))
+	d.MaxDepth = 3
+	err = drain(d)
+	assert.True(t, errors.Is(err, ErrDocumentTooDeep), "%v", err)
+
+	d = NewDecoder(strings.NewReader(doc))
+	d.MaxDepth = 4
+	assert.Equal(t, io.EOF, drain(d))
+}
+
+func TestDecoderErrors(t *testing.T) {
+	_, err := NewDecoder(strings.NewReader(`<VMAP></VMAP>`)).Root()
+	assert.EqualError(t, err, "expected VAST element, got VMAP")
+
+	_, err = NewDecoder(strings.NewReader(``)).Root()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	d := NewDecoder(strings.NewReader(`<VAST><Ad><InLine>`))
+	_, err = d.Next()
+	var syntaxErr *xml.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr), "%v", err)
+}
+
+// drain reads the ads of d until an error occurs.
+func drain(d *Decoder) error {
+	for {
+		if _, err := d.Next(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package vast
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind tells how an element differs between two documents.
+type ChangeKind int
+
+const (
+	// ChangeAdded is an element only present in the second document.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is an element only present in the first document.
+	ChangeRemoved
+	// ChangeModified is an attribute or text whose value changed.
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return fmt.Sprintf("change(%d)", int(k))
+}
+
+// Change is a difference found by Diff.
+type Change struct {
+	Kind ChangeKind
+	// Path locates the element using the Go field names, like the Path of a
+	// Diagnostic. The elements of the lists matched by key are designated by
+	// their quoted key, e.g. `Ads["123"].InLine.Creatives["456"].Linear`,
+	// followed by their rank among the elements sharing it if there are
+	// several, e.g. `TrackingEvents.Tracking["start",1]`. The other ones are
+	// designated by their index, in the first document unless added.
+	Path string
+	// From and To are the values of a modified attribute or text.
+	From, To string
+}
+
+func (c Change) String() string {
+	if c.Kind == ChangeModified {
+		return fmt.Sprintf("%s: %s %q -> %q", c.Path, c.Kind, c.From, c.To)
+	}
+	return c.Path + ": " + c.Kind.String()
+}
+
+// Diff returns the semantic differences between the documents a and b, a nil
+// document standing for an empty one.
+//
+// The elements of the lists are matched by key rather than by position, so
+// that reordering them isn't a change: ads by ID, creatives by ID or else
+// AdID, media files and impressions by ID or else URI, tracking events by
+// Event and Offset, extensions by Type, companions by ID and URI lists by
+// URI. Unmatched elements are reported as added or removed, and matched ones
+// are compared recursively. Text is compared without its leading and
+// trailing white space, and the content of extensions regardless of its
+// white space.
+func Diff(a, b *VAST) []Change {
+	if a == nil {
+		a = &VAST{}
+	}
+	if b == nil {
+		b = &VAST{}
+	}
+	d := &differ{}
+	d.diff("", reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem())
+	return d.changes
+}
+
+var (
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	unknownElementType = reflect.TypeOf(UnknownElement{})
+)
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(kind ChangeKind, path, from, to string) {
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, From: from, To: to})
+}
+
+// equal reports whether a and b have no differences.
+func equal(a, b reflect.Value) bool {
+	d := &differ{}
+	d.diff("", a, b)
+	return len(d.changes) == 0
+}
+
+// diff compares the values a and b of the same type found at path.
+func (d *differ) diff(path string, a, b reflect.Value) {
+	switch {
+	case a.Kind() == reflect.Ptr:
+		switch absentA, absentB := absent(a), absent(b); {
+		case absentA && absentB:
+		case absentA:
+			d.add(ChangeAdded, path, "", "")
+		case absentB:
+			d.add(ChangeRemoved, path, "", "")
+		default:
+			d.diff(path, a.Elem(), b.Elem())
+		}
+	case a.Type().Implements(textMarshalerType):
+		if ta, tb := diffText(a), diffText(b); ta != tb {
+			d.add(ChangeModified, path, ta, tb)
+		}
+	case a.Kind() == reflect.Slice:
+		d.diffList(path, a, b)
+	case a.Kind() == reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Name == "XMLName" && t != unknownElementType {
+				continue
+			}
+			name := f.Name
+			if path != "" {
+				name = path + "." + f.Name
+			}
+			if strings.Contains(f.Tag.Get("xml"), ",innerxml") {
+				// compare XML content regardless of the indentation
+				ta, tb := a.Field(i).String(), b.Field(i).String()
+				if strings.Join(strings.Fields(ta), " ") != strings.Join(strings.Fields(tb), " ") {
+					d.add(ChangeModified, name, strings.TrimSpace(ta), strings.TrimSpace(tb))
+				}
+				continue
+			}
+			d.diff(name, a.Field(i), b.Field(i))
+		}
+	default:
+		if ta, tb := diffText(a), diffText(b); ta != tb {
+			d.add(ChangeModified, path, ta, tb)
+		}
+	}
+}
+
+// absent reports whether the pointer v is nil or points to an empty list.
+func absent(v reflect.Value) bool {
+	return v.IsNil() || v.Elem().Kind() == reflect.Slice && v.Elem().Len() == 0
+}
+
+// diffText returns the text of the attribute or text v, as compared by Diff.
+func diffText(v reflect.Value) string {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String())
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// diffList compares the lists a and b found at path, matching their
+// elements by key.
+func (d *differ) diffList(path string, a, b reflect.Value) {
+	keysA, keysB := diffKeys(a), diffKeys(b)
+	match := make([]int, a.Len())
+	matched := make([]bool, b.Len())
+	for i := range match {
+		match[i] = -1
+	}
+	// pair the equal elements first so that the order doesn't matter, then
+	// the remaining ones sharing a key in order
+	for _, exact := range []bool{true, false} {
+		for i := range match {
+			if match[i] >= 0 {
+				continue
+			}
+			for j := range matched {
+				if !matched[j] && keysA[i] == keysB[j] && (!exact || equal(a.Index(i), b.Index(j))) {
+					match[i], matched[j] = j, true
+					break
+				}
+			}
+		}
+	}
+	for i, j := range match {
+		if j < 0 {
+			d.add(ChangeRemoved, listPath(path, keysA, i), "", "")
+		} else {
+			d.diff(listPath(path, keysA, i), a.Index(i), b.Index(j))
+		}
+	}
+	for j, ok := range matched {
+		if !ok {
+			d.add(ChangeAdded, listPath(path, keysB, j), "", "")
+		}
+	}
+}
+
+// index returns the path of the i-th element of the list at path.
+func index(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// listPath returns the path of the i-th element of the list at path whose
+// elements have the given keys.
+func listPath(path string, keys []string, i int) string {
+	key := keys[i]
+	if key == "" {
+		return index(path, i)
+	}
+	rank, n := 0, 0
+	for j, k := range keys {
+		if k == key {
+			if j < i {
+				rank++
+			}
+			n++
+		}
+	}
+	if n == 1 {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return fmt.Sprintf("%s[%q,%d]", path, key, rank)
+}
+
+// diffKeys returns the keys of the elements of the list v, "" for the
+// elements matched by position.
+func diffKeys(v reflect.Value) []string {
+	keys := make([]string, v.Len())
+	for i := range keys {
+		keys[i] = diffKey(v.Index(i).Addr().Interface())
+	}
+	return keys
+}
+
+func diffKey(e interface{}) string {
+	switch e := e.(type) {
+	case *Ad:
+		return strings.TrimSpace(e.ID)
+	case *Creative:
+		return firstNonBlank(e.ID, e.AdID)
+	case *CreativeWrapper:
+		return firstNonBlank(e.ID, e.AdID)
+	case *MediaFile:
+		return firstNonBlank(e.ID, e.URI)
+	case *Impression:
+		return firstNonBlank(e.ID, e.URI)
+	case *Tracking:
+		if e.Offset != nil {
+			offset, _ := e.Offset.MarshalText()
+			return strings.TrimSpace(e.Event) + "@" + string(offset)
+		}
+		return strings.TrimSpace(e.Event)
+	case *Extension:
+		return strings.TrimSpace(e.Type)
+	case *Companion:
+		return strings.TrimSpace(e.ID)
+	case *CDATAString:
+		return strings.TrimSpace(e.CDATA)
+	}
+	return ""
+}
+
+func firstNonBlank(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTestdata(t *testing.T) {
+	for _, path := range codecFixtures(t) {
+		a, _, _, err := loadFixture(path)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		b, _, _, _ := loadFixture(path)
+		assert.Empty(t, Diff(a, b), path)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	const creative = `Ads["601364"].InLine.Creatives["601364"].Linear`
+	tests := []struct {
+		name   string
+		change func(v *VAST)
+		want   []Change
+	}{
+		{
+			name: "reordered and padded",
+			change: func(v *VAST) {
+				in := v.Ads[0].InLine
+				in.Impressions[0], in.Impressions[1] = in.Impressions[1], in.Impressions[0]
+				in.Errors[0].CDATA = "\n  " + in.Errors[0].CDATA + "  \n"
+				tracking := in.Creatives[0].Linear.TrackingEvents.Tracking
+				tracking[0], tracking[5] = tracking[5], tracking[0]
+				in.Creatives[0], in.Creatives[1] = in.Creatives[1], in.Creatives[0]
+			},
+		},
+		{
+			name: "modified",
+			change: func(v *VAST) {
+				in := v.Ads[0].InLine
+				in.AdTitle.CDATA = "Test 2"
+				in.Creatives[0].Linear.Duration = Duration(15 * time.Second)
+				in.Creatives[0].Linear.MediaFiles.MediaFile[0].Width = 640
+				in.Creatives[0].Linear.TrackingEvents.Tracking[1].URI = "http://other/start"
+			},
+			want: []Change{
+				{Kind: ChangeModified, Path: `Ads["601364"].InLine.AdTitle.CDATA`, From: "VAST 2.0 Instream Test 1", To: "Test 2"},
+				{Kind: ChangeModified, Path: creative + ".Duration", From: "00:00:30", To: "00:00:15"},
+				{Kind: ChangeModified, Path: creative + `.TrackingEvents.Tracking["start"].URI`, From: "http://myTrackingURL/start", To: "http://other/start"},
+				{Kind: ChangeModified, Path: creative + `.MediaFiles.MediaFile["http://cdnp.tremormedia.com/video/acudeo/Carrot_400x300_500kb.flv"].Width`, From: "400", To: "640"},
+			},
+		},
+		{
+			name: "added and removed",
+			change: func(v *VAST) {
+				in := v.Ads[0].InLine
+				in.Impressions = in.Impressions[1:]
+				linear := in.Creatives[0].Linear
+				five := Duration(5 * time.Second)
+				linear.TrackingEvents.Tracking = append(linear.TrackingEvents.Tracking,
+					Tracking{Event: EventTypeStart, URI: "http://other/start"},
+					Tracking{Event: EventTypeProgress, Offset: &Offset{Duration: &five}, URI: "http://other/progress"})
+				linear.VideoClicks = nil
+				v.Ads = append(v.Ads, Ad{ID: "2", InLine: &InLine{}})
+			},
+			want: []Change{
+				{Kind: ChangeRemoved, Path: `Ads["601364"].InLine.Impressions["http://myTrackingURL/impression"]`},
+				{Kind: ChangeAdded, Path: creative + `.TrackingEvents.Tracking["start",1]`},
+				{Kind: ChangeAdded, Path: creative + `.TrackingEvents.Tracking["progress@00:00:05"]`},
+				{Kind: ChangeRemoved, Path: creative + ".VideoClicks"},
+				{Kind: ChangeAdded, Path: `Ads["2"]`},
+			},
+		},
+		{
+			name: "unkeyed",
+			change: func(v *VAST) {
+				ca := v.Ads[0].InLine.Creatives[1].CompanionAds
+				ca.Companions[0], ca.Companions[1] = ca.Companions[1], ca.Companions[0]
+				ca.Companions[0].Height = 60
+			},
+			want: []Change{
+				{Kind: ChangeModified, Path: `Ads["601364"].InLine.Creatives["601364-Companion"].CompanionAds.Companions[1].Height`, From: "90", To: "60"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+			if !assert.NoError(t, err) {
+				return
+			}
+			b, _, _, _ := loadFixture("testdata/vast_inline_linear.xml")
+			tt.change(b)
+			assert.Equal(t, tt.want, Diff(a, b))
+		})
+	}
+}
+
+func TestDiffExtensions(t *testing.T) {
+	a := &VAST{Ads: []Ad{{InLine: &InLine{Extensions: &[]Extension{
+		{Type: "geo", Data: "<Country>US</Country>\n<Bandwidth>4</Bandwidth>"},
+		{Type: "waterfall", Data: `<Index>0</Index>`},
+	}}}}}
+	b := &VAST{Ads: []Ad{{InLine: &InLine{Extensions: &[]Extension{
+		{Type: "waterfall", Data: `<Index>1</Index>`},
+		{Type: "geo", Data: "\n  <Country>US</Country>\n  <Bandwidth>4</Bandwidth>\n"},
+	}}}}}
+	assert.Equal(t, []Change{
+		{Kind: ChangeModified, Path: `Ads[0].InLine.Extensions["waterfall"].Data`, From: "<Index>0</Index>", To: "<Index>1</Index>"},
+	}, Diff(a, b))
+}
+
+func TestDiffNil(t *testing.T) {
+	v := &VAST{Version: "4.0", Ads: []Ad{{ID: "1"}}}
+	assert.Empty(t, Diff(nil, nil))
+	assert.Empty(t, Diff(&VAST{Ads: []Ad{}}, nil))
+	assert.Equal(t, []Change{
+		{Kind: ChangeModified, Path: "Version", From: "", To: "4.0"},
+		{Kind: ChangeAdded, Path: `Ads["1"]`},
+	}, Diff(nil, v))
+	assert.Equal(t, `Ads["1"]: removed`, Diff(v, &VAST{Version: "4.0"})[0].String())
+	assert.Equal(t, `Version: modified "4.0" -> "3.0"`, Diff(v, &VAST{Version: "3.0", Ads: v.Ads})[0].String())
+}
@@ -0,0 +1,482 @@
+package vast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the Dispatcher.
+const (
+	DefaultDispatcherWorkers = 16
+	DefaultDispatcherQueue   = 1024
+	DefaultBeaconAttempts    = 3
+)
+
+var (
+	// ErrDuplicateBeacon is reported when a BeaconSession is given a URL it
+	// already sent.
+	ErrDuplicateBeacon = errors.New("duplicate beacon")
+	// ErrBeaconQueueFull is reported when a beacon is dropped because the
+	// queue of the Dispatcher is full.
+	ErrBeaconQueueFull = errors.New("beacon queue full")
+	// ErrDispatcherClosed is reported when a beacon is given to a Dispatcher
+	// being shut down.
+	ErrDispatcherClosed = errors.New("beacon dispatcher closed")
+)
+
+// BeaconRequest is a request of a tracking URL.
+type BeaconRequest struct {
+	// URL with its macros expanded.
+	URL string
+	// UserAgent of the request, the UserAgent of the Dispatcher if empty.
+	UserAgent string
+	// Event tracked by the URL, if known.
+	Event string
+}
+
+// Transport sends beacon requests.
+type Transport interface {
+	Send(ctx context.Context, r *BeaconRequest) error
+}
+
+// TransportFunc is an adapter to allow the use of ordinary functions as
+// Transport.
+type TransportFunc func(ctx context.Context, r *BeaconRequest) error
+
+// Send calls f(ctx, r).
+func (f TransportFunc) Send(ctx context.Context, r *BeaconRequest) error {
+	return f(ctx, r)
+}
+
+// HTTPTransport is a Transport issuing GET requests with an http.Client.
+type HTTPTransport struct {
+	// Client is the client used to issue requests. http.DefaultClient is used
+	// when nil.
+	Client *http.Client
+}
+
+// BeaconStatusError is returned by HTTPTransport when the response to a
+// beacon is an HTTP error.
+type BeaconStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *BeaconStatusError) Error() string {
+	return fmt.Sprintf("unexpected status sending %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Temporary reports whether the request may succeed if retried, for server
+// errors, timeouts and rate limiting.
+func (e *BeaconStatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Send implements the Transport interface.
+func (t HTTPTransport) Send(ctx context.Context, r *BeaconRequest) error {
+	req, err := http.NewRequest(http.MethodGet, r.URL, nil)
+	if err != nil {
+		return err
+	}
+	if r.UserAgent != "" {
+		req.Header.Set("User-Agent", r.UserAgent)
+	}
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain the pixel so that the connection is reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 400 {
+		return &BeaconStatusError{URL: r.URL, StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// BeaconResult is the outcome of a beacon request.
+type BeaconResult struct {
+	Request BeaconRequest
+	// Number of requests made.
+	Attempts int
+	// Time spent from the first request to the last response, backoffs
+	// included.
+	Duration time.Duration
+	// Error of the last attempt, nil if the beacon was sent.
+	Err error
+}
+
+// DispatcherStats are the counters of a Dispatcher.
+type DispatcherStats struct {
+	// Beacons queued.
+	Queued int64
+	// Beacons sent, and beacons given up on.
+	Sent, Failed int64
+	// Retried requests.
+	Retries int64
+	// Beacons dropped before being queued.
+	Dropped int64
+}
+
+// Dispatcher sends beacon requests in the background, with a bounded pool
+// of workers.
+//
+// The beacons are given to a BeaconSession, one per impression, which drops
+// the URLs it already sent. A request failing with a timeout, a connection
+// error or a Temporary BeaconStatusError is retried after a backoff; the
+// other errors, such as a malformed URL or an unknown host, are permanent.
+//
+// The configuration must not be changed once the first beacon is given.
+// The Dispatcher is safe for concurrent use, and its workers run until
+// Shutdown is called.
+type Dispatcher struct {
+	// first for the alignment of the atomic counters
+	stats DispatcherStats
+
+	// Transport sends the requests.
+	Transport Transport
+	// Workers is the number of requests sent concurrently.
+	// DefaultDispatcherWorkers is used when zero.
+	Workers int
+	// MaxPerHost, when not zero, limits the number of concurrent requests to
+	// a single host. The workers wait for the requests to a busy host.
+	MaxPerHost int
+	// QueueSize is the number of beacons waiting for a worker before new
+	// beacons are dropped. DefaultDispatcherQueue is used when zero.
+	QueueSize int
+	// MaxAttempts is the maximum number of requests made for a beacon.
+	// DefaultBeaconAttempts is used when zero. Only the timeouts, the
+	// connection errors and the temporary BeaconStatusErrors are retried.
+	MaxAttempts int
+	// Backoff returns the delay before the retry following the given
+	// attempt, starting from 1. Exponential from 100ms when nil.
+	Backoff func(attempt int) time.Duration
+	// Timeout, when not zero, bounds the duration of each request.
+	Timeout time.Duration
+	// UserAgent is the user agent of the requests which don't have one.
+	UserAgent string
+
+	// OnResult, if not nil, is called from the workers with the outcome of
+	// each queued beacon.
+	OnResult func(BeaconResult)
+	// OnDrop, if not nil, is called with the beacons which aren't queued and
+	// the reason why: ErrDuplicateBeacon, ErrBeaconQueueFull or
+	// ErrDispatcherClosed.
+	OnDrop func(BeaconRequest, error)
+
+	start  sync.Once
+	mu     sync.RWMutex // guards closed and the sends on queue
+	closed bool
+	queue  chan BeaconRequest
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+
+	hostsMu sync.Mutex
+	hosts   map[string]chan struct{}
+}
+
+// NewDispatcher returns a Dispatcher using t with the default settings.
+func NewDispatcher(t Transport) *Dispatcher {
+	return &Dispatcher{Transport: t}
+}
+
+// Stats returns the counters of d.
+func (d *Dispatcher) Stats() DispatcherStats {
+	return DispatcherStats{
+		Queued:  atomic.LoadInt64(&d.stats.Queued),
+		Sent:    atomic.LoadInt64(&d.stats.Sent),
+		Failed:  atomic.LoadInt64(&d.stats.Failed),
+		Retries: atomic.LoadInt64(&d.stats.Retries),
+		Dropped: atomic.LoadInt64(&d.stats.Dropped),
+	}
+}
+
+// init starts the workers.
+func (d *Dispatcher) init() {
+	size := d.QueueSize
+	if size <= 0 {
+		size = DefaultDispatcherQueue
+	}
+	workers := d.Workers
+	if workers <= 0 {
+		workers = DefaultDispatcherWorkers
+	}
+	d.queue = make(chan BeaconRequest, size)
+	d.hosts = make(map[string]chan struct{})
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	d.done.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+}
+
+// Shutdown stops accepting beacons and waits for the queued ones to be sent.
+// If ctx is done first, the pending requests are canceled and ctx's error
+// is returned once the workers have stopped.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.start.Do(d.init)
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.done.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// enqueue queues r, reporting whether it was.
+func (d *Dispatcher) enqueue(r BeaconRequest) bool {
+	d.start.Do(d.init)
+	if r.UserAgent == "" {
+		r.UserAgent = d.UserAgent
+	}
+	d.mu.RLock()
+	var err error
+	if d.closed {
+		err = ErrDispatcherClosed
+	} else {
+		select {
+		case d.queue <- r:
+			atomic.AddInt64(&d.stats.Queued, 1)
+		default:
+			err = ErrBeaconQueueFull
+		}
+	}
+	d.mu.RUnlock()
+	if err != nil {
+		d.drop(r, err)
+		return false
+	}
+	return true
+}
+
+func (d *Dispatcher) drop(r BeaconRequest, err error) {
+	atomic.AddInt64(&d.stats.Dropped, 1)
+	if d.OnDrop != nil {
+		d.OnDrop(r, err)
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.done.Done()
+	for r := range d.queue {
+		res := d.send(r)
+		if res.Err != nil {
+			atomic.AddInt64(&d.stats.Failed, 1)
+		} else {
+			atomic.AddInt64(&d.stats.Sent, 1)
+		}
+		if d.OnResult != nil {
+			d.OnResult(res)
+		}
+	}
+}
+
+// send sends r, retrying as configured.
+func (d *Dispatcher) send(r BeaconRequest) BeaconResult {
+	attempts := d.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultBeaconAttempts
+	}
+	res := BeaconResult{Request: r}
+	start := time.Now()
+	for {
+		res.Attempts++
+		res.Err = d.attempt(&r)
+		if res.Err == nil || res.Attempts >= attempts || !retryable(res.Err) || !d.sleep(d.backoff(res.Attempts)) {
+			break
+		}
+		atomic.AddInt64(&d.stats.Retries, 1)
+	}
+	res.Duration = time.Since(start)
+	return res
+}
+
+// attempt makes a single request of r, within the limit of its host.
+func (d *Dispatcher) attempt(r *BeaconRequest) error {
+	if d.MaxPerHost > 0 {
+		sem := d.host(r.URL)
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-d.ctx.Done():
+			return d.ctx.Err()
+		}
+	}
+	ctx := d.ctx
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+	return d.Transport.Send(ctx, r)
+}
+
+// host returns the semaphore of the host of the URL u.
+func (d *Dispatcher) host(u string) chan struct{} {
+	host := u
+	if parsed, err := url.Parse(u); err == nil {
+		host = strings.ToLower(parsed.Host)
+	}
+	d.hostsMu.Lock()
+	defer d.hostsMu.Unlock()
+	sem, ok := d.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, d.MaxPerHost)
+		d.hosts[host] = sem
+	}
+	return sem
+}
+
+// sleep waits for the duration, reporting false if d is shut down first.
+func (d *Dispatcher) sleep(duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	if d.Backoff != nil {
+		return d.Backoff(attempt)
+	}
+	const max = 10 * time.Second
+	b := 100 * time.Millisecond
+	for i := 1; i < attempt && b < max; i++ {
+		b *= 2
+	}
+	if b > max {
+		b = max
+	}
+	return b
+}
+
+// retryable reports whether a request failing with err may succeed if
+// retried: after a timeout, a connection error or a temporary status. The
+// other errors, such as a malformed URL or an unknown host, are permanent.
+func retryable(err error) bool {
+	var se *BeaconStatusError
+	if errors.As(err, &se) {
+		return se.Temporary()
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.As(err, &opErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		// the connection failed or was closed
+		return true
+	}
+	return false
+}
+
+// BeaconSession gives the beacons of an impression to a Dispatcher, sending
+// each URL once. It is safe for concurrent use.
+type BeaconSession struct {
+	d *Dispatcher
+	c *MacroContext
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// Session returns a new BeaconSession expanding the macros of the beacons
+// with c. The [CACHEBUSTING] macro has the same value for all the beacons of
+// the session.
+func (d *Dispatcher) Session(c *MacroContext) *BeaconSession {
+	return &BeaconSession{d: d, c: c.withDefaults(), seen: map[string]bool{}}
+}
+
+// Send queues the beacons, with their macros expanded as by Beacon.URL, and
+// returns the number of beacons queued.
+//
+// The URLs are compared once expanded: a beacon whose URI has a macro such as
+// [TIMESTAMP] or [ADPLAYHEAD] is sent again when its value changes, e.g. for
+// each pause.
+func (s *BeaconSession) Send(beacons ...Beacon) int {
+	n := 0
+	for _, b := range beacons {
+		if blank(b.URI) {
+			continue
+		}
+		b.URI = strings.TrimSpace(b.URI)
+		if s.Dispatch(BeaconRequest{URL: b.URL(s.c), UserAgent: strings.TrimSpace(b.UA), Event: b.Event}) {
+			n++
+		}
+	}
+	return n
+}
+
+// SendURLs queues the URLs whose macros are already expanded, such as those
+// returned by EventURLs, and returns the number of URLs queued.
+func (s *BeaconSession) SendURLs(event string, urls ...string) int {
+	n := 0
+	for _, u := range urls {
+		if blank(u) {
+			continue
+		}
+		if s.Dispatch(BeaconRequest{URL: strings.TrimSpace(u), Event: event}) {
+			n++
+		}
+	}
+	return n
+}
+
+// Dispatch queues r unless the session already sent its URL, whatever the
+// user agent, and reports whether it was queued.
+func (s *BeaconSession) Dispatch(r BeaconRequest) bool {
+	s.mu.Lock()
+	dup := s.seen[r.URL]
+	s.seen[r.URL] = true
+	s.mu.Unlock()
+	if dup {
+		s.d.drop(r, ErrDuplicateBeacon)
+		return false
+	}
+	if !s.d.enqueue(r) {
+		// the beacon may be given again
+		s.mu.Lock()
+		delete(s.seen, r.URL)
+		s.mu.Unlock()
+		return false
+	}
+	return true
+}
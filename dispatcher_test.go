@@ -0,0 +1,319 @@
+package vast
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// beaconServer records the requests it receives.
+type beaconServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	agents   map[string]string
+}
+
+func newBeaconServer(handler func(w http.ResponseWriter, r *http.Request)) *beaconServer {
+	s := &beaconServer{agents: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.RequestURI())
+		s.agents[r.URL.RequestURI()] = r.UserAgent()
+		s.mu.Unlock()
+		if handler != nil {
+			handler(w, r)
+		}
+	}))
+	return s
+}
+
+func (s *beaconServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := append([]string(nil), s.requests...)
+	sort.Strings(res)
+	return res
+}
+
+func TestDispatcher(t *testing.T) {
+	srv := newBeaconServer(nil)
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var results []BeaconResult
+	var dropped []error
+	d := NewDispatcher(HTTPTransport{})
+	d.UserAgent = "vast-test/1.0"
+	d.OnResult = func(res BeaconResult) {
+		mu.Lock()
+		results = append(results, res)
+		mu.Unlock()
+	}
+	d.OnDrop = func(r BeaconRequest, err error) {
+		mu.Lock()
+		dropped = append(dropped, err)
+		mu.Unlock()
+	}
+
+	l := &Linear{
+		Duration: Duration(10 * time.Second),
+		TrackingEvents: &TrackingEvents{Tracking: []Tracking{
+			{Event: EventTypeStart, URI: srv.URL + "/start?cb=[CACHEBUSTING]"},
+			// the same pixel from a wrapper
+			{Event: EventTypeStart, URI: srv.URL + "/start?cb=[CACHEBUSTING]"},
+			{Event: EventTypeStart, URI: srv.URL + "/start/ua", UA: "custom-ua/2.0"},
+			// the same URL is sent once, whatever the user agent
+			{Event: EventTypeStart, URI: srv.URL + "/start/ua", UA: "other-ua/1.0"},
+			{Event: EventTypePause, URI: srv.URL + "/pause?t=[ADPLAYHEAD]"},
+		}},
+	}
+	tr := NewTracker(l, nil)
+	s := d.Session(&MacroContext{CacheBusting: "12345678"})
+	assert.Equal(t, 2, s.Send(tr.Start()...))
+	assert.Equal(t, 2, s.SendURLs(EventTypeImpression, srv.URL+"/impression", " "+srv.URL+"/impression", srv.URL+"/impression/2"))
+	tr.Update(time.Second)
+	assert.Equal(t, 1, s.Send(tr.Pause()...))
+	tr.Resume()
+	tr.Update(2 * time.Second)
+	assert.Equal(t, 1, s.Send(tr.Pause()...))
+
+	// another impression sends the same URLs again
+	assert.Equal(t, 1, d.Session(&MacroContext{CacheBusting: "12345678"}).SendURLs(EventTypeImpression, srv.URL+"/impression"))
+
+	assert.NoError(t, d.Shutdown(context.Background()))
+	assert.Equal(t, []string{
+		"/impression",
+		"/impression",
+		"/impression/2",
+		"/pause?t=00%3A00%3A01.000",
+		"/pause?t=00%3A00%3A02.000",
+		"/start/ua",
+		"/start?cb=12345678",
+	}, srv.Requests())
+	assert.Equal(t, "custom-ua/2.0", srv.agents["/start/ua"])
+	assert.Equal(t, "vast-test/1.0", srv.agents["/start?cb=12345678"])
+
+	assert.Len(t, results, 7)
+	for _, res := range results {
+		assert.NoError(t, res.Err)
+		assert.Equal(t, 1, res.Attempts)
+	}
+	assert.Equal(t, []error{ErrDuplicateBeacon, ErrDuplicateBeacon, ErrDuplicateBeacon}, dropped)
+	assert.Equal(t, DispatcherStats{Queued: 7, Sent: 7, Dropped: 3}, d.Stats())
+
+	// a dispatcher shut down doesn't accept beacons
+	assert.Equal(t, 0, s.SendURLs(EventTypeImpression, srv.URL+"/late"))
+	assert.Equal(t, ErrDispatcherClosed, dropped[3])
+	assert.NoError(t, d.Shutdown(context.Background()))
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	var calls int32
+	srv := newBeaconServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/down":
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+	defer srv.Close()
+
+	var mu sync.Mutex
+	results := map[string]BeaconResult{}
+	var backoffs []int
+	d := &Dispatcher{
+		Transport:   HTTPTransport{Client: srv.Client()},
+		Workers:     1,
+		MaxAttempts: 3,
+		Backoff: func(attempt int) time.Duration {
+			backoffs = append(backoffs, attempt)
+			return time.Millisecond
+		},
+		OnResult: func(res BeaconResult) {
+			mu.Lock()
+			results[res.Request.URL[len(srv.URL):]] = res
+			mu.Unlock()
+		},
+	}
+	s := d.Session(nil)
+	assert.Equal(t, 3, s.SendURLs(EventTypeError, srv.URL+"/flaky", srv.URL+"/missing", srv.URL+"/down"))
+	assert.NoError(t, d.Shutdown(context.Background()))
+
+	assert.NoError(t, results["/flaky"].Err)
+	assert.Equal(t, 3, results["/flaky"].Attempts)
+	assert.Equal(t, EventTypeError, results["/flaky"].Request.Event)
+
+	var se *BeaconStatusError
+	if assert.True(t, errors.As(results["/missing"].Err, &se)) {
+		assert.Equal(t, http.StatusNotFound, se.StatusCode)
+		assert.False(t, se.Temporary())
+	}
+	assert.Equal(t, 1, results["/missing"].Attempts)
+
+	if assert.True(t, errors.As(results["/down"].Err, &se)) {
+		assert.True(t, se.Temporary())
+		assert.EqualError(t, se, "unexpected status sending "+srv.URL+"/down: 502 Bad Gateway")
+	}
+	assert.Equal(t, 3, results["/down"].Attempts)
+
+	assert.Equal(t, []int{1, 2, 1, 2}, backoffs)
+	assert.Equal(t, DispatcherStats{Queued: 3, Sent: 1, Failed: 2, Retries: 4}, d.Stats())
+}
+
+func TestDispatcherMaxPerHost(t *testing.T) {
+	var current, max int32
+	release := make(chan struct{})
+	srv := newBeaconServer(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&current, -1)
+	})
+	defer srv.Close()
+
+	d := &Dispatcher{Transport: HTTPTransport{}, Workers: 8, MaxPerHost: 2}
+	s := d.Session(nil)
+	for i := 0; i < 6; i++ {
+		s.SendURLs(EventTypeImpression, srv.URL+"/impression/"+string(rune('a'+i)))
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&current))
+	close(release)
+	assert.NoError(t, d.Shutdown(context.Background()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&max))
+	assert.Len(t, srv.Requests(), 6)
+}
+
+func TestDispatcherQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	d := &Dispatcher{
+		Transport: TransportFunc(func(ctx context.Context, r *BeaconRequest) error {
+			<-block
+			return nil
+		}),
+		Workers:   1,
+		QueueSize: 1,
+	}
+	var dropped []error
+	d.OnDrop = func(r BeaconRequest, err error) { dropped = append(dropped, err) }
+	s := d.Session(nil)
+	assert.Equal(t, 1, s.SendURLs("", "http://a/1"))
+	// wait for the worker to take the first beacon
+	for len(d.queue) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 1, s.SendURLs("", "http://a/2"))
+	assert.Equal(t, 0, s.SendURLs("", "http://a/3"))
+	assert.Equal(t, []error{ErrBeaconQueueFull}, dropped)
+	close(block)
+
+	// a beacon dropped may be given again
+	for len(d.queue) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 1, s.SendURLs("", "http://a/3"))
+	assert.NoError(t, d.Shutdown(context.Background()))
+	assert.Equal(t, DispatcherStats{Queued: 3, Sent: 3, Dropped: 1}, d.Stats())
+}
+
+func TestDispatcherShutdownTimeout(t *testing.T) {
+	var results []BeaconResult
+	d := &Dispatcher{
+		Transport: TransportFunc(func(ctx context.Context, r *BeaconRequest) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		Workers:  1,
+		OnResult: func(res BeaconResult) { results = append(results, res) },
+	}
+	d.Session(nil).SendURLs("", "http://a/1", "http://a/2")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, d.Shutdown(ctx))
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, context.Canceled, results[0].Err)
+		assert.Equal(t, 1, results[0].Attempts)
+	}
+}
+
+func TestDispatcherTimeout(t *testing.T) {
+	d := &Dispatcher{
+		Transport: TransportFunc(func(ctx context.Context, r *BeaconRequest) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		Timeout:     time.Millisecond,
+		MaxAttempts: 2,
+		Backoff:     func(int) time.Duration { return 0 },
+	}
+	var res BeaconResult
+	d.OnResult = func(r BeaconResult) { res = r }
+	d.Session(nil).SendURLs("", "http://a/1")
+	assert.NoError(t, d.Shutdown(context.Background()))
+	assert.Equal(t, context.DeadlineExceeded, res.Err)
+	assert.Equal(t, 2, res.Attempts)
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := &Dispatcher{}
+	assert.Equal(t, 100*time.Millisecond, d.backoff(1))
+	assert.Equal(t, 400*time.Millisecond, d.backoff(3))
+	assert.Equal(t, 10*time.Second, d.backoff(20))
+}
+
+func TestDispatcherPermanentErrors(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	var mu sync.Mutex
+	results := map[string]BeaconResult{}
+	d := &Dispatcher{
+		Transport:   HTTPTransport{},
+		MaxAttempts: 3,
+		Backoff:     func(int) time.Duration { return 0 },
+		OnResult: func(res BeaconResult) {
+			mu.Lock()
+			results[res.Request.URL] = res
+			mu.Unlock()
+		},
+	}
+	d.Session(nil).SendURLs(EventTypeError, "http://a b/%zz", down.URL+"/refused")
+	assert.NoError(t, d.Shutdown(context.Background()))
+
+	// a malformed URL is never retried
+	res := results["http://a b/%zz"]
+	assert.Error(t, res.Err)
+	assert.Equal(t, 1, res.Attempts)
+	// a connection error is
+	res = results[down.URL+"/refused"]
+	assert.Error(t, res.Err)
+	assert.Equal(t, 3, res.Attempts)
+
+	assert.False(t, retryable(errors.New("unknown")))
+	assert.False(t, retryable(context.Canceled))
+	assert.True(t, retryable(context.DeadlineExceeded))
+	assert.False(t, retryable(&BeaconStatusError{StatusCode: http.StatusNotFound}))
+	assert.True(t, retryable(&BeaconStatusError{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, retryable(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "a", IsNotFound: true}}))
+}
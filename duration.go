@@ -2,20 +2,24 @@ package vast
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Duration is a VAST duration expressed a hh:mm:ss
+// Duration is a VAST duration expressed a hh:mm:ss[.mmm]. Hours may exceed
+// 99 for long-form content.
 type Duration time.Duration
 
-// MarshalText implements the encoding.TextMarshaler interface.
+// MarshalText implements the encoding.TextMarshaler interface. The duration
+// is rounded to the millisecond.
 func (dur Duration) MarshalText() ([]byte, error) {
-	h := dur / Duration(time.Hour)
-	m := dur % Duration(time.Hour) / Duration(time.Minute)
-	s := dur % Duration(time.Minute) / Duration(time.Second)
-	ms := dur % Duration(time.Second) / Duration(time.Millisecond)
+	ms := (time.Duration(dur) + time.Millisecond/2) / time.Millisecond
+	h := ms / (60 * 60 * 1000)
+	m := ms / (60 * 1000) % 60
+	s := ms / 1000 % 60
+	ms %= 1000
 	if ms == 0 {
 		return []byte(fmt.Sprintf("%02d:%02d:%02d", h, m, s)), nil
 	}
@@ -23,6 +27,10 @@ func (dur Duration) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
+//
+// Minutes and seconds range from 0 to 59, hours are unbounded. The optional
+// fraction of second is decimal, e.g. "00:00:01.5" is 1500ms, and is kept up
+// to the nanosecond.
 func (dur *Duration) UnmarshalText(data []byte) (err error) {
 	s := string(data)
 	s = strings.TrimSpace(s)
@@ -34,22 +42,32 @@ func (dur *Duration) UnmarshalText(data []byte) (err error) {
 	if len(parts) != 3 {
 		return fmt.Errorf("invalid duration: %s", data)
 	}
+	var d time.Duration
 	if i := strings.IndexByte(parts[2], '.'); i > 0 {
-		ms, err := strconv.ParseInt(parts[2][i+1:], 10, 32)
-		if err != nil || ms < 0 || ms > 999 {
+		frac := parts[2][i+1:]
+		if frac == "" || strings.Trim(frac, "0123456789") != "" {
 			return fmt.Errorf("invalid duration: %s", data)
 		}
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		ns, _ := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		d = time.Duration(ns)
 		parts[2] = parts[2][:i]
-		*dur += Duration(ms) * Duration(time.Millisecond)
 	}
-	f := Duration(time.Second)
-	for i := 2; i >= 0; i-- {
-		n, err := strconv.ParseInt(parts[i], 10, 32)
-		if err != nil || n < 0 || n > 59 {
+	for i, max := range []int64{math.MaxInt64 / int64(time.Hour), 59, 59} {
+		if parts[i] == "" || strings.Trim(parts[i], "0123456789") != "" {
+			return fmt.Errorf("invalid duration: %s", data)
+		}
+		n, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil || n > max {
 			return fmt.Errorf("invalid duration: %s", data)
 		}
-		*dur += Duration(n) * f
-		f *= 60
+		d += time.Duration(n) * []time.Duration{time.Hour, time.Minute, time.Second}[i]
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid duration: %s", data)
 	}
+	*dur = Duration(d)
 	return nil
 }
@@ -28,6 +28,18 @@ func TestDurationMarshaler(t *testing.T) {
 	if assert.NoError(t, err) {
 		assert.Equal(t, "02:00:00", string(b))
 	}
+	b, err = Duration(123*time.Hour + 4*time.Minute).MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "123:04:00", string(b))
+	}
+	b, err = Duration(1999600 * time.Microsecond).MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "00:00:02", string(b))
+	}
+	b, err = Duration(1001400 * time.Microsecond).MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "00:00:01.001", string(b))
+	}
 }
 
 func TestDurationUnmarshal(t *testing.T) {
@@ -56,6 +68,22 @@ func TestDurationUnmarshal(t *testing.T) {
 		assert.Equal(t, Duration(123*time.Millisecond), d)
 	}
 	d = 0
+	if assert.NoError(t, d.UnmarshalText([]byte("00:00:00.5"))) {
+		assert.Equal(t, Duration(500*time.Millisecond), d)
+	}
+	d = 0
+	if assert.NoError(t, d.UnmarshalText([]byte("00:00:00.1000"))) {
+		assert.Equal(t, Duration(100*time.Millisecond), d)
+	}
+	d = 0
+	if assert.NoError(t, d.UnmarshalText([]byte("00:00:01.0015"))) {
+		assert.Equal(t, Duration(1001500*time.Microsecond), d)
+	}
+	d = 0
+	if assert.NoError(t, d.UnmarshalText([]byte("100:00:00"))) {
+		assert.Equal(t, Duration(100*time.Hour), d)
+	}
+	d = 0
 	if assert.NoError(t, d.UnmarshalText([]byte("undefined"))) {
 		assert.Equal(t, Duration(0), d)
 	}
@@ -66,6 +94,9 @@ func TestDurationUnmarshal(t *testing.T) {
 	assert.EqualError(t, d.UnmarshalText([]byte("00:00:60")), "invalid duration: 00:00:60")
 	assert.EqualError(t, d.UnmarshalText([]byte("00:60:00")), "invalid duration: 00:60:00")
 	assert.EqualError(t, d.UnmarshalText([]byte("00:00:00.-1")), "invalid duration: 00:00:00.-1")
-	assert.EqualError(t, d.UnmarshalText([]byte("00:00:00.1000")), "invalid duration: 00:00:00.1000")
+	assert.EqualError(t, d.UnmarshalText([]byte("00:00:00.")), "invalid duration: 00:00:00.")
+	assert.EqualError(t, d.UnmarshalText([]byte("-1:00:00")), "invalid duration: -1:00:00")
+	assert.EqualError(t, d.UnmarshalText([]byte("1:02:03:04")), "invalid duration: 1:02:03:04")
+	assert.EqualError(t, d.UnmarshalText([]byte("9999999:00:00")), "invalid duration: 9999999:00:00")
 	assert.EqualError(t, d.UnmarshalText([]byte("00h01m")), "invalid duration: 00h01m")
 }
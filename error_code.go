@@ -0,0 +1,297 @@
+package vast
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode is a VAST error code, used to expand the [ERRORCODE] macro of
+// the Error URIs.
+type ErrorCode int
+
+const (
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// XML and schema errors
+
+	// XML parsing error.
+	ErrorCodeXMLParsing ErrorCode = 100
+	// VAST schema validation error.
+	ErrorCodeSchemaValidation ErrorCode = 101
+	// VAST version of response not supported.
+	ErrorCodeUnsupportedVersion ErrorCode = 102
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Trafficking errors
+
+	// Trafficking error. Media player received an Ad type that it was not
+	// expecting and/or cannot play.
+	ErrorCodeTrafficking ErrorCode = 200
+	// Media player expecting different linearity.
+	ErrorCodeUnexpectedLinearity ErrorCode = 201
+	// Media player expecting different duration.
+	ErrorCodeUnexpectedDuration ErrorCode = 202
+	// Media player expecting different size.
+	ErrorCodeUnexpectedSize ErrorCode = 203
+	// Ad category was required but not provided.
+	ErrorCodeCategoryRequired ErrorCode = 204
+	// Inline Category violates Wrapper BlockedAdCategories.
+	ErrorCodeCategoryBlocked ErrorCode = 205
+	// Ad Break shortened. Ad was not served.
+	ErrorCodeBreakShortened ErrorCode = 206
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Wrapper errors
+
+	// General Wrapper error.
+	ErrorCodeWrapper ErrorCode = 300
+	// Timeout of VAST URI provided in Wrapper element, or of VAST URI provided
+	// in a subsequent Wrapper element. (URI was either unavailable or reached a
+	// timeout as defined by the media player.)
+	ErrorCodeWrapperTimeout ErrorCode = 301
+	// Wrapper limit reached, as defined by the media player. Too many Wrapper
+	// responses have been received with no InLine response.
+	ErrorCodeWrapperLimit ErrorCode = 302
+	// No VAST response after one or more Wrappers.
+	ErrorCodeNoAdsAfterWrapper ErrorCode = 303
+	// InLine response returned ad unit that failed to result in ad display
+	// within defined time limit.
+	ErrorCodeInLineTimeout ErrorCode = 304
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Linear errors
+
+	// General Linear error. Media player is unable to display the Linear Ad.
+	ErrorCodeLinear ErrorCode = 400
+	// File not found. Unable to find Linear/MediaFile from URI.
+	ErrorCodeMediaFileNotFound ErrorCode = 401
+	// Timeout of MediaFile URI.
+	ErrorCodeMediaFileTimeout ErrorCode = 402
+	// Couldn't find MediaFile that is supported by this media player, based on
+	// the attributes of the MediaFile element.
+	ErrorCodeMediaFileUnsupported ErrorCode = 403
+	// Problem displaying MediaFile.
+	ErrorCodeMediaFileDisplay ErrorCode = 405
+	// Mezzanine was required but not provided. Ad not served.
+	ErrorCodeMezzanineRequired ErrorCode = 406
+	// Mezzanine is in the process of being downloaded for the first time.
+	// Ad will not be served until mezzanine is downloaded and transcoded.
+	ErrorCodeMezzanineDownloading ErrorCode = 407
+	// Conditional ad rejected.
+	ErrorCodeConditionalAdRejected ErrorCode = 408
+	// Interactive unit in the InteractiveCreativeFile node was not executed.
+	ErrorCodeInteractiveNotExecuted ErrorCode = 409
+	// Verification unit in the Verification node was not executed.
+	ErrorCodeVerificationNotExecuted ErrorCode = 410
+	// Mezzanine was provided as required, but file did not meet required
+	// specification. Ad not served.
+	ErrorCodeMezzanineInvalid ErrorCode = 411
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// NonLinear errors
+
+	// General NonLinearAds error.
+	ErrorCodeNonLinear ErrorCode = 500
+	// Unable to display NonLinear Ad because creative dimensions do not align
+	// with creative display area.
+	ErrorCodeNonLinearDimensions ErrorCode = 501
+	// Unable to fetch NonLinearAds/NonLinear resource.
+	ErrorCodeNonLinearFetch ErrorCode = 502
+	// Couldn't find NonLinear resource with supported type.
+	ErrorCodeNonLinearUnsupported ErrorCode = 503
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Companion errors
+
+	// General CompanionAds error.
+	ErrorCodeCompanion ErrorCode = 600
+	// Unable to display Companion because creative dimensions do not fit within
+	// Companion display area.
+	ErrorCodeCompanionDimensions ErrorCode = 601
+	// Unable to display required Companion.
+	ErrorCodeCompanionRequired ErrorCode = 602
+	// Unable to fetch CompanionAds/Companion resource.
+	ErrorCodeCompanionFetch ErrorCode = 603
+	// Couldn't find Companion resource with supported type.
+	ErrorCodeCompanionUnsupported ErrorCode = 604
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Other
+
+	// Undefined Error.
+	ErrorCodeUndefined ErrorCode = 900
+	// General VPAID error.
+	ErrorCodeVPAID ErrorCode = 901
+	// General InteractiveCreativeFile error.
+	ErrorCodeInteractive ErrorCode = 902
+)
+
+var errorCodeDescriptions = map[ErrorCode]string{
+	ErrorCodeXMLParsing:              "XML parsing error",
+	ErrorCodeSchemaValidation:        "VAST schema validation error",
+	ErrorCodeUnsupportedVersion:      "VAST version of response not supported",
+	ErrorCodeTrafficking:             "Trafficking error",
+	ErrorCodeUnexpectedLinearity:     "Media player expecting different linearity",
+	ErrorCodeUnexpectedDuration:      "Media player expecting different duration",
+	ErrorCodeUnexpectedSize:          "Media player expecting different size",
+	ErrorCodeCategoryRequired:        "Ad category was required but not provided",
+	ErrorCodeCategoryBlocked:         "Inline Category violates Wrapper BlockedAdCategories",
+	ErrorCodeBreakShortened:          "Ad Break shortened, ad was not served",
+	ErrorCodeWrapper:                 "General Wrapper error",
+	ErrorCodeWrapperTimeout:          "Timeout of VAST URI provided in Wrapper element",
+	ErrorCodeWrapperLimit:            "Wrapper limit reached",
+	ErrorCodeNoAdsAfterWrapper:       "No VAST response after one or more Wrappers",
+	ErrorCodeInLineTimeout:           "InLine response returned ad unit that failed to result in ad display within defined time limit",
+	ErrorCodeLinear:                  "General Linear error",
+	ErrorCodeMediaFileNotFound:       "File not found",
+	ErrorCodeMediaFileTimeout:        "Timeout of MediaFile URI",
+	ErrorCodeMediaFileUnsupported:    "Couldn't find MediaFile that is supported by this media player",
+	ErrorCodeMediaFileDisplay:        "Problem displaying MediaFile",
+	ErrorCodeMezzanineRequired:       "Mezzanine was required but not provided",
+	ErrorCodeMezzanineDownloading:    "Mezzanine is in the process of being downloaded for the first time",
+	ErrorCodeConditionalAdRejected:   "Conditional ad rejected",
+	ErrorCodeInteractiveNotExecuted:  "Interactive unit in the InteractiveCreativeFile node was not executed",
+	ErrorCodeVerificationNotExecuted: "Verification unit in the Verification node was not executed",
+	ErrorCodeMezzanineInvalid:        "Mezzanine was provided as required, but file did not meet required specification",
+	ErrorCodeNonLinear:               "General NonLinearAds error",
+	ErrorCodeNonLinearDimensions:     "Unable to display NonLinear Ad because creative dimensions do not align with creative display area",
+	ErrorCodeNonLinearFetch:          "Unable to fetch NonLinearAds/NonLinear resource",
+	ErrorCodeNonLinearUnsupported:    "Couldn't find NonLinear resource with supported type",
+	ErrorCodeCompanion:               "General CompanionAds error",
+	ErrorCodeCompanionDimensions:     "Unable to display Companion because creative dimensions do not fit within Companion display area",
+	ErrorCodeCompanionRequired:       "Unable to display required Companion",
+	ErrorCodeCompanionFetch:          "Unable to fetch CompanionAds/Companion resource",
+	ErrorCodeCompanionUnsupported:    "Couldn't find Companion resource with supported type",
+	ErrorCodeUndefined:               "Undefined Error",
+	ErrorCodeVPAID:                   "General VPAID error",
+	ErrorCodeInteractive:             "General InteractiveCreativeFile error",
+}
+
+// String returns the description of the code given by the spec.
+func (c ErrorCode) String() string {
+	if d, ok := errorCodeDescriptions[c]; ok {
+		return d
+	}
+	return "unknown VAST error code " + strconv.Itoa(int(c))
+}
+
+// Valid reports whether c is one of the codes defined by the spec.
+func (c ErrorCode) Valid() bool {
+	_, ok := errorCodeDescriptions[c]
+	return ok
+}
+
+// ErrUnsupportedVersion is returned by CheckVersion when a document uses a
+// VAST version which isn't supported.
+var ErrUnsupportedVersion = errors.New("VAST version not supported")
+
+// CheckVersion returns an error wrapping ErrUnsupportedVersion if the version
+// of v isn't one of supported.
+func CheckVersion(v *VAST, supported ...string) error {
+	for _, s := range supported {
+		if v.Version == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedVersion, v.Version)
+}
+
+// ErrorCoder is implemented by errors knowing their VAST error code.
+type ErrorCoder interface {
+	ErrorCode() ErrorCode
+}
+
+// ErrorCodeFor returns the VAST error code matching an error returned by the
+// package, e.g. when unmarshaling or resolving a document. ErrorCodeUndefined
+// is returned for unknown errors, including the timeouts which aren't those
+// of a ResolveError fetching the VAST URI of a wrapper.
+func ErrorCodeFor(err error) ErrorCode {
+	if err == nil {
+		return 0
+	}
+	var coder ErrorCoder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return ErrorCodeXMLParsing
+	}
+	switch {
+	case errors.Is(err, ErrDocumentTooLarge), errors.Is(err, ErrDocumentTooDeep), errors.Is(err, ErrTooManyAds):
+		return ErrorCodeXMLParsing
+	case errors.Is(err, ErrUnsupportedVersion):
+		return ErrorCodeUnsupportedVersion
+	case errors.Is(err, ErrMissingAdTagURI):
+		return ErrorCodeSchemaValidation
+	case errors.Is(err, ErrWrapperLimit):
+		return ErrorCodeWrapperLimit
+	case errors.Is(err, ErrNoAdsAfterWrapper):
+		return ErrorCodeNoAdsAfterWrapper
+	case errors.Is(err, ErrWrapperNotAllowed):
+		return ErrorCodeWrapper
+	case errors.Is(err, ErrCompanionRequired):
+		return ErrorCodeCompanionRequired
+	}
+	var resolveErr *ResolveError
+	if errors.As(err, &resolveErr) && resolveErr.URI != "" {
+		// the VAST URI of the wrapper couldn't be fetched in time, or at all,
+		// e.g. refused or not found, and there is thus no response
+		var netErr net.Error
+		if errors.Is(resolveErr.Err, context.DeadlineExceeded) || errors.As(resolveErr.Err, &netErr) && netErr.Timeout() {
+			return ErrorCodeWrapperTimeout
+		}
+		return ErrorCodeNoAdsAfterWrapper
+	}
+	return ErrorCodeUndefined
+}
+
+// ErrorURLs returns every Error URI of v, from the root element and its ads,
+// with their macros expanded and [ERRORCODE] set to code.
+func ErrorURLs(v *VAST, code ErrorCode, c *MacroContext) []string {
+	return EventURLs(v, EventTypeError, withErrorCode(c, code))
+}
+
+// ErrorURLs returns the Error URIs of the InLine ad, including the ones
+// merged from its wrappers, with their macros expanded and [ERRORCODE] set to
+// code.
+func (ad *ResolvedAd) ErrorURLs(code ErrorCode, c *MacroContext) []string {
+	if ad.Ad.InLine == nil {
+		return nil
+	}
+	return expandErrors(ad.Ad.InLine.Errors, withErrorCode(c, code))
+}
+
+// ErrorURLs returns the Error URIs of the wrappers followed before the
+// failure, with their macros expanded and [ERRORCODE] set to the code
+// matching the failure.
+func (e *ResolveError) ErrorURLs(c *MacroContext) []string {
+	c = withErrorCode(c, ErrorCodeFor(e.Err))
+	var urls []string
+	for _, w := range e.Wrappers {
+		urls = append(urls, expandErrors(w.Errors, c)...)
+	}
+	return urls
+}
+
+// withErrorCode returns a copy of c, with its generated values filled in, for
+// the given error code.
+func withErrorCode(c *MacroContext, code ErrorCode) *MacroContext {
+	c = c.withDefaults()
+	c.ErrorCode = code
+	return c
+}
+
+func expandErrors(errs []CDATAString, c *MacroContext) []string {
+	var urls []string
+	for _, e := range errs {
+		if uri := strings.TrimSpace(e.CDATA); uri != "" {
+			urls = append(urls, c.expand(uri))
+		}
+	}
+	return urls
+}
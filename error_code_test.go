@@ -0,0 +1,130 @@
+package vast
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeString(t *testing.T) {
+	assert.Equal(t, "No VAST response after one or more Wrappers", ErrorCodeNoAdsAfterWrapper.String())
+	assert.Equal(t, "unknown VAST error code 404", ErrorCode(404).String())
+	assert.True(t, ErrorCodeInteractive.Valid())
+	assert.False(t, ErrorCode(404).Valid())
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+type codedError struct{}
+
+func (codedError) Error() string        { return "coded" }
+func (codedError) ErrorCode() ErrorCode { return ErrorCodeMezzanineRequired }
+
+func TestErrorCodeFor(t *testing.T) {
+	var v VAST
+	syntaxErr := xml.Unmarshal([]byte("<VAST"), &v)
+
+	// the failures of a Resolver fetching a document which doesn't parse, or
+	// isn't found
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<VAST version="4.2"><Ad><InLine><Creatives><Creative><Linear><Duration>soon</Duration></Linear></Creative></Creatives></InLine></Ad></VAST>`))
+	}))
+	defer srv.Close()
+	resolveErr := func(path string) error {
+		w := &VAST{Ads: []Ad{{Wrapper: &Wrapper{VASTAdTagURI: CDATAString{srv.URL + path}}}}}
+		_, err := NewResolver(HTTPFetcher{}).Resolve(context.Background(), w)
+		return err
+	}
+
+	tests := []struct {
+		err  error
+		want ErrorCode
+	}{
+		{nil, 0},
+		{syntaxErr, ErrorCodeXMLParsing},
+		{CheckVersion(&VAST{Version: "1.0"}, "3.0", "4.2"), ErrorCodeUnsupportedVersion},
+		{ErrWrapperLimit, ErrorCodeWrapperLimit},
+		{&ResolveError{URI: "http://a", Err: ErrNoAdsAfterWrapper}, ErrorCodeNoAdsAfterWrapper},
+		{&ResolveError{URI: "http://a", Err: syntaxErr}, ErrorCodeXMLParsing},
+		{&ResolveError{URI: "http://a", Err: context.DeadlineExceeded}, ErrorCodeWrapperTimeout},
+		{&ResolveError{URI: "http://a", Err: &net.OpError{Op: "dial", Err: timeoutError{}}}, ErrorCodeWrapperTimeout},
+		{&ResolveError{URI: "http://a", Err: errors.New("connection refused")}, ErrorCodeNoAdsAfterWrapper},
+		{resolveErr("/invalid"), ErrorCodeXMLParsing},
+		{resolveErr("/missing"), ErrorCodeNoAdsAfterWrapper},
+		{ResolveErrors{{Err: ErrWrapperNotAllowed}}, ErrorCodeWrapper},
+		{(&CompanionMatch{}).Err(), ErrorCodeCompanionRequired},
+		{fmt.Errorf("wrapped: %w", codedError{}), ErrorCodeMezzanineRequired},
+		{errors.New("boom"), ErrorCodeUndefined},
+		// timeouts unrelated to fetching a wrapper
+		{context.DeadlineExceeded, ErrorCodeUndefined},
+		{&net.OpError{Op: "dial", Err: timeoutError{}}, ErrorCodeUndefined},
+		{&ResolveError{Err: context.DeadlineExceeded}, ErrorCodeUndefined},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ErrorCodeFor(tt.err), "%v", tt.err)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	assert.NoError(t, CheckVersion(&VAST{Version: "4.2"}, "3.0", "4.2"))
+	err := CheckVersion(&VAST{Version: "5.0"}, "3.0", "4.2")
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+	assert.EqualError(t, err, `VAST version not supported: "5.0"`)
+}
+
+func TestErrorURLs(t *testing.T) {
+	v := &VAST{
+		Errors: []CDATAString{{CDATA: "http://root?e=[ERRORCODE]"}},
+		Ads: []Ad{
+			{InLine: &InLine{Errors: []CDATAString{{CDATA: " http://inline?e=[ERRORCODE]&r=[REASON] "}}}},
+			{Wrapper: &Wrapper{Errors: []CDATAString{{CDATA: "http://wrapper?e=[ERRORCODE]"}}}},
+		},
+	}
+	c := &MacroContext{Reason: 2}
+	assert.Equal(t, []string{
+		"http://root?e=303",
+		"http://inline?e=303&r=2",
+		"http://wrapper?e=303",
+	}, ErrorURLs(v, ErrorCodeNoAdsAfterWrapper, c))
+	assert.Equal(t, ErrorCode(0), c.ErrorCode)
+}
+
+func TestResolveErrorURLs(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	v.Ads[0].Wrapper.Errors[0].CDATA = "http://myErrorURL/wrapper/error?e=[ERRORCODE]"
+	empty := FetcherFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		return []byte(`<VAST version="3.0"></VAST>`), nil
+	})
+	_, err = NewResolver(empty).Resolve(context.Background(), v)
+	var errs ResolveErrors
+	if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 1) {
+		assert.Equal(t, []string{"http://myErrorURL/wrapper/error?e=303"}, errs[0].ErrorURLs(nil))
+	}
+
+	ads, err := NewResolver(wrapperFixtures).Resolve(context.Background(), v)
+	if assert.NoError(t, err) && assert.Len(t, ads, 1) {
+		assert.Equal(t, []string{
+			"http://myErrorURL/error",
+			"http://myErrorURL/error2",
+			"http://myErrorURL/wrapper/error?e=405",
+		}, ads[0].ErrorURLs(ErrorCodeMediaFileDisplay, nil))
+	}
+}
@@ -110,4 +110,15 @@ const (
 
 	EventTypeView    = "view"
 	EventTypeMonitor = "monitor"
+	// The icon was displayed. Not a Tracking event, it selects the
+	// IconViewTracking URIs of an icon, e.g. in the beacons of a Tracker.
+	EventTypeIconView = "iconView"
+)
+
+// The following are not tracking events but select the Impression, Error and
+// click tracking URIs of a document, e.g. in EventURLs.
+const (
+	EventTypeImpression    = "impression"
+	EventTypeError         = "error"
+	EventTypeClickTracking = "clickTracking"
 )
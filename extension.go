@@ -1,6 +1,13 @@
 package vast
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
 
 // Extension represent arbitrary XML provided by the platform to extend the
 // VAST response or by custom trackers.
@@ -51,3 +58,121 @@ func (e *Extension) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error
 	}
 	return nil
 }
+
+// The types of the extensions registered by default.
+const (
+	// ExtensionTypeGeo is the type of the extensions giving the location of
+	// the viewer as a GeoExtension.
+	ExtensionTypeGeo = "geo"
+	// ExtensionTypeWaterfall is the type of the extensions giving the
+	// position of the ad in a waterfall as a WaterfallExtension.
+	ExtensionTypeWaterfall = "waterfall"
+	// ExtensionTypeIABCount and ExtensionTypeSpotXCount are the types of the
+	// extensions giving the number of ads available as a CountExtension.
+	ExtensionTypeIABCount   = "iab-Count"
+	ExtensionTypeSpotXCount = "SpotX-Count"
+)
+
+// ErrUnknownExtension is returned by Extension.Value for the extensions whose
+// type isn't registered.
+var ErrUnknownExtension = errors.New("unknown extension type")
+
+// GeoExtension is the payload of the "geo" extensions.
+type GeoExtension struct {
+	Country       string `xml:"Country,omitempty"`
+	Bandwidth     int    `xml:"Bandwidth,omitempty"`
+	BandwidthKbps int    `xml:"BandwidthKbps,omitempty"`
+}
+
+// WaterfallExtension is the payload of the "waterfall" extensions.
+type WaterfallExtension struct {
+	Index int `xml:"Index"`
+}
+
+// CountExtension is the payload of the "iab-Count" and "SpotX-Count"
+// extensions.
+type CountExtension struct {
+	// The number of ads available for the request.
+	TotalAvailable int `xml:"total_available"`
+	// The number of ads requested.
+	Desired int `xml:"desired,omitempty"`
+}
+
+// ExtensionDecoder decodes the payload of the extension e into v, a non nil
+// pointer.
+type ExtensionDecoder func(e *Extension, v interface{}) error
+
+type extensionCodec struct {
+	typ    reflect.Type
+	decode ExtensionDecoder
+}
+
+var (
+	extensionsMu sync.RWMutex
+	extensions   = map[string]extensionCodec{}
+)
+
+func init() {
+	RegisterExtension(ExtensionTypeAdVerifications, AdVerifications{}, decodeExtensionElement)
+	RegisterExtension(ExtensionTypeGeo, GeoExtension{}, nil)
+	RegisterExtension(ExtensionTypeWaterfall, WaterfallExtension{}, nil)
+	RegisterExtension(ExtensionTypeIABCount, CountExtension{}, nil)
+	RegisterExtension(ExtensionTypeSpotXCount, CountExtension{}, nil)
+}
+
+// RegisterExtension registers the payload of the extensions of type typ: the
+// type of value is the one returned by Extension.Value and decode, if not
+// nil, replaces the default decoding of Extension.Decode. Registering a type
+// again replaces the previous registration.
+//
+// Registering doesn't change how extensions are marshaled: their payload is
+// kept in Data, which is written back as is.
+func RegisterExtension(typ string, value interface{}, decode ExtensionDecoder) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	extensions[typ] = extensionCodec{typ: reflect.TypeOf(value), decode: decode}
+}
+
+func lookupExtension(typ string) (extensionCodec, bool) {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+	c, ok := extensions[typ]
+	return c, ok
+}
+
+// Decode decodes the payload of e into v, a non nil pointer, using the
+// decoder registered for its type. By default, the content of the
+// <Extension> element is decoded with encoding/xml as if it were the content
+// of the element v is decoded from, so that the fields of a struct map to
+// the children of the extension.
+func (e *Extension) Decode(v interface{}) error {
+	if c, ok := lookupExtension(e.Type); ok && c.decode != nil {
+		return c.decode(e, v)
+	}
+	return decodeExtensionContent(e, v)
+}
+
+// Value returns a pointer to a new value of the type registered for the type
+// of e, filled by Decode.
+func (e *Extension) Value() (interface{}, error) {
+	c, ok := lookupExtension(e.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExtension, e.Type)
+	}
+	v := reflect.New(c.typ).Interface()
+	if err := e.Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// decodeExtensionContent decodes the content of the extension e into v.
+func decodeExtensionContent(e *Extension, v interface{}) error {
+	return xml.Unmarshal([]byte("<Extension>"+e.Data+"</Extension>"), v)
+}
+
+// decodeExtensionElement decodes the single element making the payload of
+// the extension e into v.
+func decodeExtensionElement(e *Extension, v interface{}) error {
+	return xml.Unmarshal([]byte(strings.TrimSpace(e.Data)), v)
+}
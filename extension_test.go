@@ -2,6 +2,8 @@ package vast
 
 import (
 	"encoding/xml"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -77,3 +79,93 @@ func TestExtensionGeneric(t *testing.T) {
 	// assert the resulting marshaled extension
 	assert.Equal(t, string(extensionData), string(xmlExtensionOutput))
 }
+
+func TestExtensionValue(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/inline_extensions.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	exts := *v.Ads[0].InLine.Extensions
+	geo, err := exts[0].Value()
+	assert.NoError(t, err)
+	assert.Equal(t, &GeoExtension{Country: "US", Bandwidth: 3, BandwidthKbps: 1680}, geo)
+
+	_, err = exts[2].Value()
+	assert.True(t, errors.Is(err, ErrUnknownExtension))
+	assert.EqualError(t, err, `unknown extension type: "DFP"`)
+
+	// unregistered types can still be decoded into a given type
+	var dfp struct {
+		SkippableAdType string
+	}
+	assert.NoError(t, exts[2].Decode(&dfp))
+	assert.Equal(t, "Generic", dfp.SkippableAdType)
+
+	v, _, _, err = loadFixture("testdata/iab/vast_4.2_samples/Event_Tracking-test.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	count, err := (*v.Ads[0].InLine.Extensions)[0].Value()
+	assert.NoError(t, err)
+	assert.Equal(t, &CountExtension{TotalAvailable: 2, Desired: 1}, count)
+
+	var spotx CountExtension
+	e := Extension{Type: ExtensionTypeSpotXCount, Data: "<total_available><![CDATA[1]]></total_available>"}
+	assert.NoError(t, e.Decode(&spotx))
+	assert.Equal(t, CountExtension{TotalAvailable: 1}, spotx)
+
+	e = Extension{Type: ExtensionTypeWaterfall, Data: "<Index>x</Index>"}
+	_, err = e.Value()
+	assert.Error(t, err)
+}
+
+func TestExtensionAdVerifications(t *testing.T) {
+	e := Extension{Type: ExtensionTypeAdVerifications, Data: `
+		<AdVerifications>
+			<Verification vendor="company.com-omid">
+				<JavaScriptResource apiFramework="omid" browserOptional="true"><![CDATA[https://verification.com/omid.js]]></JavaScriptResource>
+			</Verification>
+		</AdVerifications>
+	`}
+	av, err := e.Value()
+	if assert.NoError(t, err) && assert.IsType(t, &AdVerifications{}, av) {
+		ver := av.(*AdVerifications).Verification
+		if assert.Len(t, ver, 1) {
+			assert.Equal(t, "company.com-omid", ver[0].Vendor)
+			assert.Equal(t, "https://verification.com/omid.js", ver[0].JavaScriptResource[0].URI)
+		}
+	}
+}
+
+type testPricing struct {
+	Model string  `xml:"model,attr"`
+	Price float64 `xml:",chardata"`
+}
+
+func TestRegisterExtension(t *testing.T) {
+	const typ = "LR-Pricing"
+	defer func() {
+		extensionsMu.Lock()
+		delete(extensions, typ)
+		extensionsMu.Unlock()
+	}()
+	RegisterExtension(typ, testPricing{}, func(e *Extension, v interface{}) error {
+		return xml.Unmarshal([]byte(strings.TrimSpace(e.Data)), v)
+	})
+
+	v, _, _, err := loadFixture("testdata/spotx_vpaid.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	exts := *v.Ads[0].InLine.Extensions
+	price, err := exts[0].Value()
+	assert.NoError(t, err)
+	assert.Equal(t, &testPricing{Model: "CPM", Price: 3.06}, price)
+
+	// decoding leaves the payloads untouched
+	b, err := xml.Marshal(v)
+	assert.NoError(t, err)
+	for _, e := range exts {
+		assert.Contains(t, string(b), `<Extension type="`+e.Type+`">`+e.Data+`</Extension>`)
+	}
+}
@@ -0,0 +1,244 @@
+package vast
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IconProgramAdChoices is the program of the AdChoices industry icon.
+const IconProgramAdChoices = "AdChoices"
+
+// ScheduledIcon is an icon to display during a linear ad.
+type ScheduledIcon struct {
+	Icon *Icon
+	// Depth is the number of wrappers between the icon and the InLine ad, 0
+	// for the icons of the InLine ad.
+	Depth int
+	// Playheads at which the icon appears and disappears. End is zero if the
+	// icon stays until the end of the linear ad.
+	Start, End time.Duration
+}
+
+// Visible reports whether the icon is displayed at the playhead.
+func (s *ScheduledIcon) Visible(playhead time.Duration) bool {
+	return playhead >= s.Start && (s.End == 0 || playhead < s.End)
+}
+
+// IconSchedule tells when the icons of a linear ad are displayed.
+type IconSchedule struct {
+	// Icons, by Start then in document order.
+	Icons []ScheduledIcon
+}
+
+// NewIconSchedule returns the schedule of the icons of the linear ad l,
+// reached through the chain of wrappers, outermost first as in ResolvedAd,
+// on a screen of the given pixel ratio, 1 if zero.
+//
+// When the InLine ad and the wrappers have icons for the same program, only
+// the ones of the document closest to the InLine ad are kept, and among
+// them the one made for the pixel ratio of the screen, else the one with the
+// closest higher ratio, else the highest one. The icons without a program
+// are all kept. The icons whose offset can't be resolved, a percentage of a
+// linear ad without duration, are dropped.
+func NewIconSchedule(l *Linear, wrappers []*Wrapper, pxratio float64) *IconSchedule {
+	if pxratio <= 0 {
+		pxratio = 1
+	}
+	s := &IconSchedule{}
+	if l == nil {
+		return s
+	}
+	duration := time.Duration(l.Duration)
+
+	// the documents, closest to the InLine ad first
+	docs := [][]Icon{linearIcons(l.Icons)}
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		var icons []Icon
+		for _, c := range wrappers[i].Creatives {
+			if c.Linear != nil {
+				icons = append(icons, linearIcons(c.Linear.Icons)...)
+			}
+		}
+		docs = append(docs, icons)
+	}
+
+	// best[program] is the icon kept for the program
+	best := make(map[string]*Icon)
+	for _, icons := range docs {
+		found := make(map[string]bool)
+		for i := range icons {
+			icon := &icons[i]
+			p := iconProgram(icon)
+			if p == "" {
+				continue
+			}
+			cur, ok := best[p]
+			switch {
+			case !ok:
+				best[p] = icon
+				found[p] = true
+			case !found[p]:
+				// a document closer to the InLine ad has the program
+			case betterPxratio(iconPxratio(icon), iconPxratio(cur), pxratio):
+				best[p] = icon
+			}
+		}
+	}
+
+	for depth, icons := range docs {
+		for i := range icons {
+			icon := &icons[i]
+			if p := iconProgram(icon); p != "" && best[p] != icon {
+				continue
+			}
+			start, ok := icon.Offset.Resolve(duration)
+			if !ok {
+				continue
+			}
+			si := ScheduledIcon{Icon: icon, Depth: depth, Start: start}
+			if icon.Duration > 0 {
+				si.End = start + time.Duration(icon.Duration)
+			}
+			s.Icons = append(s.Icons, si)
+		}
+	}
+	sort.SliceStable(s.Icons, func(i, j int) bool {
+		return s.Icons[i].Start < s.Icons[j].Start
+	})
+	return s
+}
+
+// At returns the icons displayed at the playhead.
+func (s *IconSchedule) At(playhead time.Duration) []*ScheduledIcon {
+	var res []*ScheduledIcon
+	for i := range s.Icons {
+		if s.Icons[i].Visible(playhead) {
+			res = append(res, &s.Icons[i])
+		}
+	}
+	return res
+}
+
+// Program returns the icon of the given program, regardless of the case, or
+// nil if there is none, e.g. for the player to display its own AdChoices
+// icon.
+func (s *IconSchedule) Program(program string) *ScheduledIcon {
+	program = strings.ToLower(strings.TrimSpace(program))
+	for i := range s.Icons {
+		if iconProgram(s.Icons[i].Icon) == program {
+			return &s.Icons[i]
+		}
+	}
+	return nil
+}
+
+// Position returns the coordinates of the top left corner of the icon in a
+// player of the given size, in pixels. Positions other than a number of
+// pixels, left, right, top and bottom are read as left and top.
+func (icon *Icon) Position(width, height int) (x, y int) {
+	return iconPosition(icon.XPosition, "right", width-icon.Width),
+		iconPosition(icon.YPosition, "bottom", height-icon.Height)
+}
+
+// iconPosition returns the coordinate of the position pos, end being the
+// coordinate of the far keyword.
+func iconPosition(pos, far string, end int) int {
+	pos = strings.TrimSpace(pos)
+	if pos == far {
+		if end < 0 {
+			return 0
+		}
+		return end
+	}
+	n, err := strconv.Atoi(pos)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// FallbackImage returns the click fallback image of the icon to display in
+// an area of the given size in pixels on a screen of the given pixel ratio,
+// 1 if zero, or nil if the icon has none.
+//
+// It is the largest image fitting the area in physical pixels, else one
+// without size, else the smallest one.
+func (icon *Icon) FallbackImage(width, height int, pxratio float64) *IconClickFallbackImage {
+	if icon.IconClickFallbackImages == nil {
+		return nil
+	}
+	if pxratio <= 0 {
+		pxratio = 1
+	}
+	maxWidth := int(math.Round(float64(width) * pxratio))
+	maxHeight := int(math.Round(float64(height) * pxratio))
+
+	var fit, unsized, smallest *IconClickFallbackImage
+	for i := range icon.IconClickFallbackImages.IconClickFallbackImage {
+		img := &icon.IconClickFallbackImages.IconClickFallbackImage[i]
+		switch {
+		case img.StaticResource == nil || blank(img.StaticResource.CDATA):
+		case img.Width <= 0 || img.Height <= 0:
+			if unsized == nil {
+				unsized = img
+			}
+		case img.Width <= maxWidth && img.Height <= maxHeight:
+			if fit == nil || img.Width*img.Height > fit.Width*fit.Height {
+				fit = img
+			}
+		default:
+			if smallest == nil || img.Width*img.Height < smallest.Width*smallest.Height {
+				smallest = img
+			}
+		}
+	}
+	switch {
+	case fit != nil:
+		return fit
+	case unsized != nil:
+		return unsized
+	}
+	return smallest
+}
+
+func linearIcons(icons *Icons) []Icon {
+	if icons == nil || icons.Icon == nil {
+		return nil
+	}
+	return *icons.Icon
+}
+
+// iconProgram returns the program of the icon, in lower case.
+func iconProgram(icon *Icon) string {
+	return strings.ToLower(strings.TrimSpace(icon.Program))
+}
+
+// iconPxratio returns the pixel ratio the icon is made for, 1 by default.
+func iconPxratio(icon *Icon) float64 {
+	if r, err := strconv.ParseFloat(strings.TrimSpace(icon.Pxratio), 64); err == nil && r > 0 {
+		return r
+	}
+	return 1
+}
+
+// betterPxratio reports whether an icon made for the pixel ratio a suits a
+// screen of the given pixel ratio better than one made for b: the exact
+// ratio wins, then the closest higher one, then the highest one.
+func betterPxratio(a, b, screen float64) bool {
+	switch {
+	case a == b:
+		return false
+	case b == screen:
+		return false
+	case a == screen:
+		return true
+	case a > screen && b > screen:
+		return a < b
+	case a > screen || b > screen:
+		return a > screen
+	}
+	return a > b
+}
@@ -0,0 +1,168 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scheduledIcons(s *IconSchedule) []string {
+	res := make([]string, len(s.Icons))
+	for i, si := range s.Icons {
+		res[i] = si.Icon.AltText
+	}
+	return res
+}
+
+func TestIconSchedule(t *testing.T) {
+	d2, d5, d10 := Duration(2*time.Second), Duration(5*time.Second), Duration(10*time.Second)
+	inline := []Icon{
+		{AltText: "adchoices", Program: "AdChoices", Offset: Offset{Duration: &d2}, Duration: d5},
+		{AltText: "adchoices@2x", Program: "AdChoices", Pxratio: "2", Offset: Offset{Duration: &d2}, Duration: d5},
+		{AltText: "logo", Offset: Offset{Percent: 0.5}},
+	}
+	inner := []Icon{
+		{AltText: "inner-adchoices", Program: "adchoices"},
+		{AltText: "inner-brand", Program: "Brand", Offset: Offset{Duration: &d10}},
+	}
+	outer := []Icon{
+		{AltText: "outer-brand", Program: "Brand"},
+		{AltText: "outer-other", Program: "Other", Offset: Offset{Duration: &d5}},
+	}
+	l := &Linear{Duration: Duration(30 * time.Second), Icons: &Icons{Icon: &inline}}
+	wrappers := []*Wrapper{
+		{Creatives: []CreativeWrapper{{Linear: &LinearWrapper{Icons: &Icons{Icon: &outer}}}}},
+		{Creatives: []CreativeWrapper{{Linear: &LinearWrapper{Icons: &Icons{Icon: &inner}}}}},
+	}
+
+	s := NewIconSchedule(l, wrappers, 2)
+	assert.Equal(t, []string{"adchoices@2x", "outer-other", "inner-brand", "logo"}, scheduledIcons(s))
+	assert.Equal(t, ScheduledIcon{Icon: &inline[1], Start: 2 * time.Second, End: 7 * time.Second}, s.Icons[0])
+	assert.Equal(t, ScheduledIcon{Icon: &outer[1], Depth: 2, Start: 5 * time.Second}, s.Icons[1])
+	assert.Equal(t, ScheduledIcon{Icon: &inner[1], Depth: 1, Start: 10 * time.Second}, s.Icons[2])
+	assert.Equal(t, ScheduledIcon{Icon: &inline[2], Start: 15 * time.Second}, s.Icons[3])
+
+	var visible []string
+	for _, si := range s.At(6 * time.Second) {
+		visible = append(visible, si.Icon.AltText)
+	}
+	assert.Equal(t, []string{"adchoices@2x", "outer-other"}, visible)
+	assert.Len(t, s.At(7*time.Second), 1)
+	assert.Len(t, s.At(time.Minute), 3)
+	assert.Empty(t, s.At(time.Second))
+
+	assert.Equal(t, &s.Icons[0], s.Program("adchoices"))
+	assert.Nil(t, s.Program("none"))
+
+	// the screen ratio picks the icon
+	s = NewIconSchedule(l, wrappers, 0)
+	assert.Equal(t, "adchoices", s.Program(IconProgramAdChoices).Icon.AltText)
+	s = NewIconSchedule(l, wrappers, 3)
+	assert.Equal(t, "adchoices@2x", s.Program(IconProgramAdChoices).Icon.AltText)
+
+	// the percent offsets can't be resolved without duration
+	s = NewIconSchedule(&Linear{Icons: &Icons{Icon: &inline}}, nil, 1)
+	assert.Equal(t, []string{"adchoices"}, scheduledIcons(s))
+
+	// the closest wrapper wins without InLine icons
+	s = NewIconSchedule(&Linear{}, wrappers, 1)
+	assert.Equal(t, []string{"inner-adchoices", "outer-other", "inner-brand"}, scheduledIcons(s))
+
+	assert.Empty(t, NewIconSchedule(nil, wrappers, 1).Icons)
+}
+
+func TestBetterPxratio(t *testing.T) {
+	tests := []struct {
+		a, b, screen float64
+		want         bool
+	}{
+		{2, 1, 2, true},
+		{1, 2, 2, false},
+		{3, 4, 2, true},
+		{4, 3, 2, false},
+		{3, 1, 2, true},
+		{1, 3, 2, false},
+		{1.5, 1, 2, true},
+		{1, 1.5, 2, false},
+		{1, 1, 1, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, betterPxratio(tt.a, tt.b, tt.screen), "%v %v %v", tt.a, tt.b, tt.screen)
+	}
+}
+
+func TestIconPosition(t *testing.T) {
+	tests := []struct {
+		xpos, ypos string
+		x, y       int
+	}{
+		{"left", "top", 0, 0},
+		{"right", "bottom", 580, 340},
+		{"10", "20", 10, 20},
+		{" right ", "bottom", 580, 340},
+		{"", "", 0, 0},
+		{"middle", "-5", 0, 0},
+	}
+	for _, tt := range tests {
+		icon := &Icon{Width: 60, Height: 20, XPosition: tt.xpos, YPosition: tt.ypos}
+		x, y := icon.Position(640, 360)
+		assert.Equal(t, tt.x, x, "%q", tt.xpos)
+		assert.Equal(t, tt.y, y, "%q", tt.ypos)
+	}
+
+	x, y := (&Icon{Width: 60, Height: 20, XPosition: "right", YPosition: "bottom"}).Position(40, 10)
+	assert.Equal(t, 0, x)
+	assert.Equal(t, 0, y)
+}
+
+func TestIconFallbackImage(t *testing.T) {
+	image := func(alt string, w, h int) IconClickFallbackImage {
+		return IconClickFallbackImage{AltText: alt, Width: w, Height: h, StaticResource: &CDATAString{"http://i/" + alt}}
+	}
+	icon := &Icon{IconClickFallbackImages: &IconClickFallbackImages{IconClickFallbackImage: []IconClickFallbackImage{
+		image("small", 400, 300),
+		image("large", 800, 600),
+		image("huge", 1600, 1200),
+		{AltText: "empty", Width: 600, Height: 450},
+	}}}
+	tests := []struct {
+		width, height int
+		pxratio       float64
+		want          string
+	}{
+		{640, 480, 0, "small"},
+		{640, 480, 1.5, "large"},
+		{800, 600, 2, "huge"},
+		{200, 100, 1, "small"},
+	}
+	for _, tt := range tests {
+		img := icon.FallbackImage(tt.width, tt.height, tt.pxratio)
+		if assert.NotNil(t, img) {
+			assert.Equal(t, tt.want, img.AltText, "%dx%d@%v", tt.width, tt.height, tt.pxratio)
+		}
+	}
+
+	icon.IconClickFallbackImages.IconClickFallbackImage = append(icon.IconClickFallbackImages.IconClickFallbackImage, image("unsized", 0, 0))
+	assert.Equal(t, "unsized", icon.FallbackImage(200, 100, 1).AltText)
+
+	assert.Nil(t, (&Icon{}).FallbackImage(640, 480, 1))
+}
+
+func TestIconScheduleTestdata(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_adaptv_attempt_attr.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := NewIconSchedule(v.Ads[0].InLine.Creatives[0].Linear, nil, 1)
+	if assert.Len(t, s.Icons, 1) {
+		si := s.Program("DAA")
+		if assert.NotNil(t, si) {
+			assert.Equal(t, time.Duration(0), si.Start)
+			assert.Equal(t, time.Duration(0), si.End)
+			x, y := si.Icon.Position(640, 360)
+			assert.Equal(t, 563, x)
+			assert.Equal(t, 0, y)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import "fmt"
+
+// jsonReflect reports whether f is encoded by encoding/json, which is the
+// case of the other types and of the slices of non generated types.
+func jsonReflect(f field) bool {
+	switch f.shape {
+	case shapeSlice:
+		return f.kind != kindHot
+	case shapePtrSlice:
+		return true
+	}
+	return f.kind == kindOther
+}
+
+// jsonNotEmpty returns the condition for the field f not to be omitted by
+// encoding/json, "" if it never is.
+func jsonNotEmpty(f field) string {
+	v := "v." + f.name
+	switch f.shape {
+	case shapePtr, shapePtrSlice:
+		return v + " != nil"
+	case shapeSlice:
+		return "len(" + v + ") != 0"
+	}
+	return notEmpty(f, v)
+}
+
+func (g *generator) marshalJSON(name string, fields []field) {
+	g.printf("// MarshalJSON implements the json.Marshaler interface.\n")
+	g.printf("func (v *%s) MarshalJSON() ([]byte, error) {\nreturn v.appendJSON(nil)\n}\n\n", name)
+	g.printf("func (v *%s) appendJSON(b []byte) ([]byte, error) {\n", name)
+	for _, f := range fields {
+		if f.kind == kindHot || jsonReflect(f) {
+			g.printf("var err error\n")
+			break
+		}
+	}
+	g.printf("b = append(b, '{')\n")
+	for _, f := range fields {
+		cond := ""
+		if f.jsonOmitempty {
+			cond = jsonNotEmpty(f)
+		}
+		if cond != "" {
+			g.printf("if %s {\n", cond)
+		} else {
+			g.printf("{\n")
+		}
+		g.printf("b = append(b, `%q:`...)\n", f.jsonName)
+		g.marshalJSONValue(f, cond != "")
+		g.printf("b = append(b, ',')\n}\n")
+	}
+	g.printf("return closeJSON(b, '}'), nil\n}\n\n")
+}
+
+// marshalJSONValue writes the code appending the value of the field f,
+// known not to be empty if set is.
+func (g *generator) marshalJSONValue(f field, set bool) {
+	v := "v." + f.name
+	if jsonReflect(f) {
+		g.printf("if b, err = appendJSON(b, &%s); err != nil {\nreturn nil, err\n}\n", v)
+		return
+	}
+	switch f.shape {
+	case shapePtr:
+		if !set {
+			g.printf("if %s == nil {\nb = append(b, \"null\"...)\n} else {\n", v)
+			defer g.printf("}\n")
+		}
+		if f.kind != kindHot && f.kind != kindText {
+			v = "*" + v
+		}
+	case shapeSlice:
+		if !set {
+			g.printf("if %s == nil {\nb = append(b, \"null\"...)\n} else {\n", v)
+			defer g.printf("}\n")
+		}
+		g.printf("b = append(b, '[')\nfor i := range %s {\n", v)
+		g.printf("if b, err = %s[i].appendJSON(b); err != nil {\nreturn nil, err\n}\n", v)
+		g.printf("b = append(b, ',')\n}\nb = closeJSON(b, ']')\n")
+		return
+	}
+	switch f.kind {
+	case kindString:
+		g.printf("b = appendJSONString(b, %s)\n", v)
+	case kindInt:
+		g.printf("b = strconv.AppendInt(b, int64(%s), 10)\n", v)
+	case kindBool:
+		g.printf("b = strconv.AppendBool(b, %s)\n", v)
+	case kindText:
+		g.printf("text, err := %s.MarshalText()\nif err != nil {\nreturn nil, err\n}\n", v)
+		g.printf("b = appendJSONString(b, string(text))\n")
+	case kindHot:
+		g.printf("if b, err = %s.appendJSON(b); err != nil {\nreturn nil, err\n}\n", v)
+	}
+}
+
+func (g *generator) unmarshalJSON(name string, fields []field) {
+	g.printf("// UnmarshalJSON implements the json.Unmarshaler interface.\n")
+	g.printf("func (v *%s) UnmarshalJSON(data []byte) error {\nreturn unmarshalJSON(data, v)\n}\n\n", name)
+	g.printf("var jsonFields%s = []string{", name)
+	for i, f := range fields {
+		if i > 0 {
+			g.printf(", ")
+		}
+		g.printf("%q", f.jsonName)
+	}
+	g.printf("}\n\n")
+
+	g.printf("func (v *%s) decodeJSON(r *jsonReader) error {\n", name)
+	g.printf("if ok, err := r.object(v); !ok || err != nil {\nreturn err\n}\n")
+	g.printf("for first := true; ; first = false {\n")
+	g.printf("key, ok, err := r.key(first)\nif !ok || err != nil {\nreturn err\n}\n")
+	g.printf("switch jsonField(key, jsonFields%s) {\n", name)
+	for i, f := range fields {
+		g.printf("case %d:\n", i)
+		g.unmarshalJSONValue(f)
+	}
+	g.printf("default:\nif err := r.skip(); err != nil {\nreturn err\n}\n}\n}\n}\n\n")
+}
+
+// unmarshalJSONValue writes the code decoding the next value into the field
+// f.
+func (g *generator) unmarshalJSONValue(f field) {
+	v := "v." + f.name
+	if jsonReflect(f) {
+		g.printf("if err := r.unmarshal(&%s); err != nil {\nreturn err\n}\n", v)
+		return
+	}
+	switch f.shape {
+	case shapePtr:
+		g.printf("if r.null() {\n%s = nil\nbreak\n}\n", v)
+		g.printf("if %s == nil {\n%[1]s = new(%s)\n}\n", v, f.typ)
+		if f.kind != kindHot && f.kind != kindText {
+			v = "*" + v
+		}
+	case shapeSlice:
+		// decode the elements in place, as encoding/json does
+		g.printf("if r.null() {\n%s = nil\nbreak\n}\n", v)
+		g.printf("if ok, err := r.array(&%s); !ok || err != nil {\nif err != nil {\nreturn err\n}\nbreak\n}\n", v)
+		g.printf("i := 0\nfor ; ; i++ {\n")
+		g.printf("ok, err := r.next(i == 0)\nif err != nil {\nreturn err\n}\nif !ok {\nbreak\n}\n")
+		g.printf("if i == len(%s) {\nif i < cap(%[1]s) {\n%[1]s = %[1]s[:i+1]\n} else {\n%[1]s = append(%[1]s, %s{})\n}\n}\n", v, f.typ)
+		g.printf("if err := %s[i].decodeJSON(r); err != nil {\nreturn err\n}\n}\n", v)
+		g.printf("if i == 0 {\n%s = []%s{}\n} else {\n%[1]s = %[1]s[:i]\n}\n", v, f.typ)
+		return
+	}
+	ptr := "&" + v
+	switch {
+	case v[0] == '*':
+		ptr = v[1:]
+	case f.shape == shapePtr:
+		ptr = v
+	}
+	switch f.kind {
+	case kindString:
+		g.printf("if err := r.decodeString(%s); err != nil {\nreturn err\n}\n", ptr)
+	case kindInt:
+		g.printf("if err := r.decodeInt(%s); err != nil {\nreturn err\n}\n", ptr)
+	case kindBool:
+		g.printf("if err := r.decodeBool(%s); err != nil {\nreturn err\n}\n", ptr)
+	case kindText:
+		g.printf("if err := r.decodeText(%s); err != nil {\nreturn err\n}\n", ptr)
+	case kindHot:
+		g.printf("if err := %s.decodeJSON(r); err != nil {\nreturn err\n}\n", v)
+	default:
+		panic(fmt.Sprintf("unexpected kind %d", f.kind))
+	}
+}
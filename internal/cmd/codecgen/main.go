@@ -0,0 +1,482 @@
+// Command codecgen generates reflection-free MarshalXML, UnmarshalXML,
+// MarshalJSON and UnmarshalJSON methods for structs of the vast package,
+// producing the same XML and JSON as their tag-driven encoding/xml and
+// encoding/json codecs.
+//
+// Usage:
+//
+//	codecgen -type VAST,Ad -o vast_codec.go [-mirror vast_reflect_test.go] [dir]
+//
+// The source of the package in dir, the current directory by default, is
+// parsed rather than loaded, so that stale generated code never prevents the
+// generation.
+//
+// With -mirror, codecgen also writes a test file declaring, for each type T,
+// a method-free reflectT copy of T with the same layout and tags. Casting a
+// value to its mirror gets back the reflection-based encoding/xml and
+// encoding/json codecs, to check and benchmark the generated ones.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("codecgen: ")
+	types := flag.String("type", "", "comma separated list of the types to generate the codecs of")
+	output := flag.String("o", "", "output file")
+	mirror := flag.String("mirror", "", "output file of the mirror types, none by default")
+	flag.Parse()
+	if *types == "" || *output == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	pkg, err := load(dir, *output, *mirror)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &generator{pkg: pkg, hot: map[string]bool{}}
+	for _, name := range strings.Split(*types, ",") {
+		if pkg.structs[name] == nil {
+			log.Fatalf("struct type %s not found", name)
+		}
+		g.hot[name] = true
+		g.types = append(g.types, name)
+	}
+
+	src, err := g.codec()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, *output), src, 0644); err != nil {
+		log.Fatal(err)
+	}
+	if *mirror != "" {
+		src, err := g.mirror()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, *mirror), src, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// pkg is the parsed source of a package.
+type pkg struct {
+	name    string
+	fset    *token.FileSet
+	structs map[string]*ast.StructType
+	// text lists the types implementing both encoding.TextMarshaler and
+	// encoding.TextUnmarshaler, mapped to whether their underlying type is a
+	// struct.
+	text map[string]bool
+	// underlying maps the other named types to their underlying type.
+	underlying map[string]ast.Expr
+}
+
+// load parses the non test files of the package in dir, but the generated
+// ones.
+func load(dir string, generated ...string) (*pkg, error) {
+	skip := map[string]bool{}
+	for _, name := range generated {
+		skip[name] = true
+	}
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && !skip[fi.Name()]
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%d packages found in %s", len(pkgs), dir)
+	}
+	p := &pkg{
+		fset:       fset,
+		structs:    map[string]*ast.StructType{},
+		text:       map[string]bool{},
+		underlying: map[string]ast.Expr{},
+	}
+	methods := map[string]map[string]bool{}
+	for name, astPkg := range pkgs {
+		p.name = name
+		for _, file := range astPkg.Files {
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						ts, ok := spec.(*ast.TypeSpec)
+						if !ok {
+							continue
+						}
+						if st, ok := ts.Type.(*ast.StructType); ok {
+							p.structs[ts.Name.Name] = st
+						}
+						p.underlying[ts.Name.Name] = ts.Type
+					}
+				case *ast.FuncDecl:
+					if decl.Recv == nil || len(decl.Recv.List) != 1 {
+						continue
+					}
+					recv := decl.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if id, ok := recv.(*ast.Ident); ok {
+						if methods[id.Name] == nil {
+							methods[id.Name] = map[string]bool{}
+						}
+						methods[id.Name][decl.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	for name, m := range methods {
+		if m["MarshalText"] && m["UnmarshalText"] {
+			_, isStruct := p.underlying[name].(*ast.StructType)
+			p.text[name] = isStruct
+		}
+	}
+	return p, nil
+}
+
+// shape is the form of the type of a field.
+type shape int
+
+const (
+	shapeValue    shape = iota // T
+	shapePtr                   // *T
+	shapeSlice                 // []T
+	shapePtrSlice              // *[]T
+)
+
+// kind is the kind of the base type of a field.
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindBool
+	kindText  // implements encoding.TextMarshaler and TextUnmarshaler
+	kindHot   // has a generated codec
+	kindOther // encoded by encoding/xml and encoding/json
+)
+
+// field is an exported struct field.
+type field struct {
+	name  string
+	shape shape
+	kind  kind
+	typ   string // base type
+	// textStruct is set for the kindText types whose underlying type is a
+	// struct, and which are thus never empty.
+	textStruct bool
+
+	// XML encoding
+	xmlSkip   bool
+	xmlName   string
+	parents   []string
+	attr      bool
+	cdata     bool
+	chardata  bool
+	any       bool
+	omitempty bool
+
+	// JSON encoding
+	jsonSkip      bool
+	jsonName      string
+	jsonOmitempty bool
+}
+
+type generator struct {
+	pkg   *pkg
+	hot   map[string]bool
+	types []string
+	buf   bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// fields returns the exported fields of the struct type name.
+func (g *generator) fields(name string) ([]field, error) {
+	var fields []field
+	for _, f := range g.pkg.structs[name].Fields.List {
+		if len(f.Names) != 1 {
+			return nil, fmt.Errorf("%s: embedded and grouped fields are not supported", name)
+		}
+		fd := field{name: f.Names[0].Name, xmlName: f.Names[0].Name, jsonName: f.Names[0].Name}
+		if !ast.IsExported(fd.name) {
+			continue
+		}
+		if fd.name == "XMLName" {
+			return nil, fmt.Errorf("%s: XMLName is not supported", name)
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+		if err := parseXMLTag(&fd, tag.Get("xml")); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, fd.name, err)
+		}
+		if err := parseJSONTag(&fd, tag.Get("json")); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, fd.name, err)
+		}
+		if err := g.resolve(&fd, f.Type); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, fd.name, err)
+		}
+		if fd.xmlSkip {
+			fields = append(fields, fd)
+			continue
+		}
+		if (fd.cdata || fd.chardata) && (fd.kind != kindString || fd.shape != shapeValue) {
+			return nil, fmt.Errorf("%s.%s: only string character data is supported", name, fd.name)
+		}
+		switch {
+		case fd.any && fd.attr && (fd.shape != shapeValue || fd.kind != kindOther):
+			return nil, fmt.Errorf("%s.%s: any attributes must be held by a named xml.UnmarshalerAttr slice", name, fd.name)
+		case fd.any && !fd.attr && (fd.shape != shapeSlice || fd.kind != kindOther || len(fd.parents) > 0):
+			return nil, fmt.Errorf("%s.%s: any elements must be held by a slice of structs", name, fd.name)
+		}
+		if fd.attr && !fd.any && (fd.shape == shapeSlice || fd.shape == shapePtrSlice || fd.kind == kindHot || fd.kind == kindOther) {
+			return nil, fmt.Errorf("%s.%s: unsupported attribute type", name, fd.name)
+		}
+		if len(fd.parents) > 1 {
+			return nil, fmt.Errorf("%s.%s: nested parents are not supported", name, fd.name)
+		}
+		if prev := xmlFields(fields); len(fd.parents) > 0 && len(prev) > 0 {
+			if last := prev[len(prev)-1]; len(last.parents) > 0 && last.parents[0] == fd.parents[0] {
+				return nil, fmt.Errorf("%s.%s: shared parents are not supported", name, fd.name)
+			}
+		}
+		fields = append(fields, fd)
+	}
+	names := map[string]bool{}
+	for _, f := range jsonFields(fields) {
+		if names[f.jsonName] {
+			return nil, fmt.Errorf("%s: duplicate JSON name %s", name, f.jsonName)
+		}
+		names[f.jsonName] = true
+	}
+	return fields, nil
+}
+
+// parseXMLTag sets the XML encoding of fd from its xml tag.
+func parseXMLTag(fd *field, tag string) error {
+	if tag == "-" {
+		fd.xmlSkip = true
+		return nil
+	}
+	opts := strings.Split(tag, ",")
+	if opts[0] != "" {
+		path := strings.Split(opts[0], ">")
+		fd.xmlName, fd.parents = path[len(path)-1], path[:len(path)-1]
+	}
+	for _, o := range opts[1:] {
+		switch o {
+		case "attr":
+			fd.attr = true
+		case "cdata":
+			fd.cdata = true
+		case "chardata":
+			fd.chardata = true
+		case "any":
+			fd.any = true
+		case "omitempty":
+			fd.omitempty = true
+		default:
+			return fmt.Errorf("unsupported xml option %q", o)
+		}
+	}
+	return nil
+}
+
+// parseJSONTag sets the JSON encoding of fd from its json tag.
+func parseJSONTag(fd *field, tag string) error {
+	if tag == "-" {
+		fd.jsonSkip = true
+		return nil
+	}
+	opts := strings.Split(tag, ",")
+	if opts[0] != "" {
+		fd.jsonName = opts[0]
+	}
+	// restrict the names to the ones which need no escaping
+	for _, c := range fd.jsonName {
+		if !strings.ContainsRune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-", c) {
+			return fmt.Errorf("unsupported json name %q", fd.jsonName)
+		}
+	}
+	for _, o := range opts[1:] {
+		switch o {
+		case "omitempty":
+			fd.jsonOmitempty = true
+		default:
+			return fmt.Errorf("unsupported json option %q", o)
+		}
+	}
+	return nil
+}
+
+// xmlFields returns the fields of fields encoded in XML.
+func xmlFields(fields []field) []field {
+	var xml []field
+	for _, f := range fields {
+		if !f.xmlSkip {
+			xml = append(xml, f)
+		}
+	}
+	return xml
+}
+
+// jsonFields returns the fields of fields encoded in JSON.
+func jsonFields(fields []field) []field {
+	var json []field
+	for _, f := range fields {
+		if !f.jsonSkip {
+			json = append(json, f)
+		}
+	}
+	return json
+}
+
+// resolve sets the shape and kind of fd from its type expression.
+func (g *generator) resolve(fd *field, expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		fd.shape = shapePtr
+		if a, ok := e.X.(*ast.ArrayType); ok && a.Len == nil {
+			fd.shape = shapePtrSlice
+			expr = a.Elt
+		} else {
+			expr = e.X
+		}
+	case *ast.ArrayType:
+		if e.Len != nil {
+			return fmt.Errorf("arrays are not supported")
+		}
+		fd.shape = shapeSlice
+		expr = e.Elt
+	}
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return fmt.Errorf("unsupported type")
+	}
+	fd.typ = id.Name
+	switch isStruct, isText := g.pkg.text[id.Name]; {
+	case id.Name == "string":
+		fd.kind = kindString
+	case id.Name == "int":
+		fd.kind = kindInt
+	case id.Name == "bool":
+		fd.kind = kindBool
+	case isText:
+		fd.kind = kindText
+		fd.textStruct = isStruct
+	case g.hot[id.Name]:
+		fd.kind = kindHot
+	case g.pkg.structs[id.Name] != nil:
+		fd.kind = kindOther
+	case fd.any && fd.attr && g.pkg.underlying[id.Name] != nil:
+		// a named slice decoding the attributes itself
+		fd.kind = kindOther
+	default:
+		return fmt.Errorf("unsupported type %s", id.Name)
+	}
+	return nil
+}
+
+// codec returns the source of the XML and JSON codecs of the types.
+func (g *generator) codec() ([]byte, error) {
+	g.buf.Reset()
+	for _, name := range g.types {
+		fields, err := g.fields(name)
+		if err != nil {
+			return nil, err
+		}
+		g.marshalXML(name, xmlFields(fields))
+		g.unmarshalXML(name, xmlFields(fields))
+		g.marshalJSON(name, jsonFields(fields))
+		g.unmarshalJSON(name, jsonFields(fields))
+		g.printf("func (*%s) tagCodec() {}\n\n", name)
+	}
+	// import the packages referred to by the generated code
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "package %s\n\n", g.pkg.name)
+	src.Write(g.buf.Bytes())
+	f, err := parser.ParseFile(token.NewFileSet(), "", src.Bytes(), 0)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, id := range f.Unresolved {
+		used[id.Name] = true
+	}
+	src.Reset()
+	fmt.Fprintf(&src, "// Code generated by codecgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg.name)
+	for _, path := range []string{"encoding/json", "encoding/xml", "strconv"} {
+		if used[path[strings.LastIndex(path, "/")+1:]] {
+			fmt.Fprintf(&src, "%q\n", path)
+		}
+	}
+	src.WriteString(")\n\n")
+	src.Write(g.buf.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// notEmpty returns the condition for a value v of f not to be empty, "" if
+// it never is.
+func notEmpty(f field, v string) string {
+	switch f.kind {
+	case kindString:
+		return v + ` != ""`
+	case kindInt:
+		return v + " != 0"
+	case kindBool:
+		return v
+	case kindText:
+		if !f.textStruct {
+			return v + " != 0"
+		}
+	}
+	return ""
+}
+
+// zero returns the zero value of the base type of f.
+func zero(f field) string {
+	switch f.kind {
+	case kindString:
+		return `""`
+	case kindInt:
+		return "0"
+	case kindBool:
+		return "false"
+	}
+	return f.typ + "{}"
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"sort"
+)
+
+// mirror returns the source of the mirror types.
+func (g *generator) mirror() ([]byte, error) {
+	g.buf.Reset()
+	g.printf("// Code generated by codecgen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg.name)
+	names := append([]string(nil), g.types...)
+	sort.Strings(names)
+	for _, name := range names {
+		st := g.pkg.structs[name]
+		// rename the mirrored types in a copy of the struct
+		var fields []*ast.Field
+		for _, f := range st.Fields.List {
+			c := *f
+			c.Doc, c.Comment = nil, nil
+			c.Type = g.rename(f.Type)
+			fields = append(fields, &c)
+		}
+		var b bytes.Buffer
+		if err := printer.Fprint(&b, g.pkg.fset, &ast.StructType{Fields: &ast.FieldList{List: fields}}); err != nil {
+			return nil, err
+		}
+		g.printf("// reflect%s is %[1]s without its generated codecs.\n", name)
+		g.printf("type reflect%s %s\n\n", name, b.String())
+	}
+	return format.Source(g.buf.Bytes())
+}
+
+// rename returns a copy of the type expression expr referring to the mirror
+// types.
+func (g *generator) rename(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: g.rename(e.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: g.rename(e.Elt)}
+	case *ast.Ident:
+		if g.hot[e.Name] {
+			return ast.NewIdent("reflect" + e.Name)
+		}
+	}
+	return expr
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// charData returns the fields of fields holding the character data of the
+// element.
+func charData(fields []field) []field {
+	var text []field
+	for _, f := range fields {
+		if f.cdata || f.chardata {
+			text = append(text, f)
+		}
+	}
+	return text
+}
+
+func startElement(name string) string {
+	return fmt.Sprintf("xml.StartElement{Name: xml.Name{Local: %q}}", name)
+}
+
+// text returns the expression of the text of the value v of f, writing the
+// statements computing it first.
+func (g *generator) text(f field, v string) string {
+	switch f.kind {
+	case kindInt:
+		return "strconv.Itoa(" + v + ")"
+	case kindBool:
+		return "strconv.FormatBool(" + v + ")"
+	case kindText:
+		g.printf("b, err := %s.MarshalText()\nif err != nil {\nreturn err\n}\n", v)
+		return "string(b)"
+	}
+	return v
+}
+
+func (g *generator) marshalXML(name string, fields []field) {
+	g.printf("// MarshalXML implements the xml.Marshaler interface.\n")
+	g.printf("func (v *%s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n", name)
+	if text := charData(fields); len(text) > 0 {
+		var conds []string
+		for _, f := range text {
+			if f.cdata {
+				conds = append(conds, fmt.Sprintf("!cdataSafe(v.%s)", f.name))
+			} else {
+				conds = append(conds, fmt.Sprintf("!textSafe(v.%s)", f.name))
+			}
+		}
+		g.printf("if %s {\n", strings.Join(conds, " || "))
+		g.printf("type plain %s\nreturn e.EncodeElement((*plain)(v), start)\n}\n", name)
+	}
+	for _, f := range fields {
+		switch {
+		case f.attr && f.any:
+			g.printf("start.Attr = append(start.Attr, v.%s...)\n", f.name)
+		case f.attr:
+			g.marshalAttr(f)
+		}
+	}
+	g.printf("if err := e.EncodeToken(start); err != nil {\nreturn err\n}\n")
+	for _, f := range fields {
+		switch {
+		case f.attr:
+		case f.any:
+			g.printf("for i := range v.%s {\nif err := e.Encode(&v.%[1]s[i]); err != nil {\nreturn err\n}\n}\n", f.name)
+		case f.cdata:
+			g.printf("if err := encodeCDATA(e, v.%s); err != nil {\nreturn err\n}\n", f.name)
+		case f.chardata:
+			g.printf("if v.%s != \"\" {\nif err := e.EncodeToken(xml.CharData(v.%[1]s)); err != nil {\nreturn err\n}\n}\n", f.name)
+		default:
+			g.marshalElement(f)
+		}
+	}
+	g.printf("return e.EncodeToken(start.End())\n}\n\n")
+}
+
+func (g *generator) marshalAttr(f field) {
+	v := "v." + f.name
+	cond := ""
+	if f.shape == shapePtr {
+		cond = v + " != nil"
+		if f.kind != kindText {
+			v = "*" + v
+		}
+	} else if f.omitempty {
+		cond = notEmpty(f, v)
+	}
+	// scope the variables of text marshalers
+	block := cond != "" || f.kind == kindText
+	if cond != "" {
+		g.printf("if %s {\n", cond)
+	} else if block {
+		g.printf("{\n")
+	}
+	value := g.text(f, v)
+	g.printf("start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: %q}, Value: %s})\n", f.xmlName, value)
+	if block {
+		g.printf("}\n")
+	}
+}
+
+func (g *generator) marshalElement(f field) {
+	v := "v." + f.name
+	closing := 0
+	if f.shape == shapePtr || f.shape == shapePtrSlice {
+		g.printf("if %s != nil {\n", v)
+		closing++
+	}
+	if len(f.parents) > 0 {
+		g.printf("parent := %s\n", startElement(f.parents[0]))
+		g.printf("if err := e.EncodeToken(parent); err != nil {\nreturn err\n}\n")
+	}
+	switch f.shape {
+	case shapeValue:
+		if c := notEmpty(f, v); f.omitempty && c != "" {
+			g.printf("if %s {\n", c)
+			closing++
+		}
+	case shapePtr:
+		if f.kind != kindHot && f.kind != kindOther {
+			v = "*" + v
+		}
+	case shapeSlice:
+		g.printf("for i := range %s {\n", v)
+		v += "[i]"
+		closing++
+	case shapePtrSlice:
+		g.printf("for i := range *%s {\n", v)
+		v = "(*" + v + ")[i]"
+		closing++
+	}
+	start := startElement(f.xmlName)
+	switch f.kind {
+	case kindHot:
+		g.printf("if err := %s.MarshalXML(e, %s); err != nil {\nreturn err\n}\n", v, start)
+	case kindOther:
+		if f.shape != shapePtr {
+			v = "&" + v
+		}
+		g.printf("if err := e.EncodeElement(%s, %s); err != nil {\nreturn err\n}\n", v, start)
+	default:
+		// scope the variables of text marshalers
+		block := f.kind == kindText && closing == 0 && len(f.parents) == 0
+		if f.kind == kindText {
+			v = strings.TrimPrefix(v, "*")
+		}
+		if block {
+			g.printf("{\n")
+		}
+		value := g.text(f, v)
+		g.printf("if err := encodeText(e, %s, %s); err != nil {\nreturn err\n}\n", start, value)
+		if block {
+			g.printf("}\n")
+		}
+	}
+	// close the loops and conditions opened before the parent
+	if len(f.parents) > 0 {
+		inner := closing
+		if f.shape == shapePtr || f.shape == shapePtrSlice {
+			inner--
+		}
+		g.printf("%s", strings.Repeat("}\n", inner))
+		g.printf("if err := e.EncodeToken(parent.End()); err != nil {\nreturn err\n}\n")
+		closing -= inner
+	}
+	g.printf("%s", strings.Repeat("}\n", closing))
+}
+
+func (g *generator) unmarshalXML(name string, fields []field) {
+	g.printf("// UnmarshalXML implements the xml.Unmarshaler interface.\n")
+	g.printf("func (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", name)
+	// the attributes and elements matching no other field go to the first
+	// any field, as with encoding/xml, if the decoder keeps them
+	var attrs []field
+	var anyAttr, anyElem *field
+	for i, f := range fields {
+		switch {
+		case f.attr && f.any:
+			if anyAttr == nil {
+				anyAttr = &fields[i]
+			}
+		case f.attr:
+			attrs = append(attrs, f)
+		case f.any:
+			if anyElem == nil {
+				anyElem = &fields[i]
+			}
+		}
+	}
+	switch {
+	case len(attrs) > 0:
+		g.printf("for _, a := range start.Attr {\nswitch a.Name.Local {\n")
+		for _, f := range attrs {
+			g.printf("case %q:\n", f.xmlName)
+			g.parse(f, "a.Value", false)
+		}
+		if anyAttr != nil {
+			g.printf("default:\nif keepsUnknown(d) {\nif err := v.%s.UnmarshalXMLAttr(a); err != nil {\nreturn err\n}\n}\n", anyAttr.name)
+		}
+		g.printf("}\n}\n")
+	case anyAttr != nil:
+		g.printf("if keepsUnknown(d) {\nfor _, a := range start.Attr {\nif err := v.%s.UnmarshalXMLAttr(a); err != nil {\nreturn err\n}\n}\n}\n", anyAttr.name)
+	}
+	text := charData(fields)
+	if len(text) > 0 {
+		g.printf("var data []byte\n")
+	}
+	g.printf("for {\ntok, err := d.Token()\nif err != nil {\nreturn err\n}\n")
+	g.printf("switch t := tok.(type) {\ncase xml.StartElement:\nswitch t.Name.Local {\n")
+	for _, f := range fields {
+		if f.attr || f.any || f.cdata || f.chardata {
+			continue
+		}
+		if len(f.parents) == 0 {
+			g.printf("case %q:\n", f.xmlName)
+			g.unmarshalElement(f)
+			continue
+		}
+		g.printf("case %q:\n", f.parents[0])
+		g.printf("for {\ntok, err := d.Token()\nif err != nil {\nreturn err\n}\n")
+		g.printf("if _, ok := tok.(xml.EndElement); ok {\nbreak\n}\n")
+		g.printf("t, ok := tok.(xml.StartElement)\nif !ok {\ncontinue\n}\n")
+		g.printf("if t.Name.Local != %q {\nif err := d.Skip(); err != nil {\nreturn err\n}\ncontinue\n}\n", f.xmlName)
+		g.unmarshalElement(f)
+		g.printf("}\n")
+	}
+	if anyElem != nil {
+		// the unknown elements are only kept on demand, see keepUnknown
+		v := "v." + anyElem.name
+		g.printf("default:\nif !keepsUnknown(d) {\nif err := d.Skip(); err != nil {\nreturn err\n}\ncontinue\n}\n")
+		g.printf("%s = append(%[1]s, %s{})\n", v, anyElem.typ)
+		g.printf("if err := d.DecodeElement(&%s[len(%[1]s)-1], &t); err != nil {\nreturn err\n}\n}\n", v)
+	} else {
+		g.printf("default:\nif err := d.Skip(); err != nil {\nreturn err\n}\n}\n")
+	}
+	if len(text) > 0 {
+		g.printf("case xml.CharData:\ndata = append(data, t...)\n")
+	}
+	g.printf("case xml.EndElement:\n")
+	for _, f := range text {
+		g.printf("v.%s = string(data)\n", f.name)
+	}
+	g.printf("return nil\n}\n}\n}\n\n")
+}
+
+// parse writes the code decoding the text s, of type string or []byte as
+// given by bytes, into the field f.
+func (g *generator) parse(f field, s string, bytes bool) {
+	// ptr is a pointer to the value
+	ptr := "&v." + f.name
+	if f.shape == shapePtr {
+		g.printf("if v.%s == nil {\nv.%[1]s = new(%s)\n}\n", f.name, f.typ)
+		ptr = "v." + f.name
+	}
+	str, b := s, s
+	if bytes {
+		str = "string(" + s + ")"
+	} else {
+		b = "[]byte(" + s + ")"
+	}
+	switch f.kind {
+	case kindString:
+		if strings.HasPrefix(ptr, "&") {
+			g.printf("%s = %s\n", ptr[1:], str)
+		} else {
+			g.printf("*%s = %s\n", ptr, str)
+		}
+	case kindInt:
+		g.printf("if err := parseInt(%s, %s); err != nil {\nreturn err\n}\n", ptr, str)
+	case kindBool:
+		g.printf("if err := parseBool(%s, %s); err != nil {\nreturn err\n}\n", ptr, str)
+	case kindText:
+		g.printf("if err := %s.UnmarshalText(%s); err != nil {\nreturn err\n}\n", strings.TrimPrefix(ptr, "&"), b)
+	}
+}
+
+// unmarshalElement writes the code decoding the element t into the field f.
+func (g *generator) unmarshalElement(f field) {
+	v := "v." + f.name
+	switch f.shape {
+	case shapePtr:
+		if f.kind != kindHot && f.kind != kindOther {
+			g.printf("b, err := decodeText(d)\nif err != nil {\nreturn err\n}\n")
+			g.parse(f, "b", true)
+			return
+		}
+		g.printf("if %s == nil {\n%[1]s = new(%s)\n}\n", v, f.typ)
+	case shapeSlice:
+		g.printf("%s = append(%[1]s, %s)\n", v, zero(f))
+		v = fmt.Sprintf("%s[len(%[1]s)-1]", v)
+	case shapePtrSlice:
+		g.printf("if %s == nil {\n%[1]s = new([]%s)\n}\n", v, f.typ)
+		g.printf("*%s = append(*%[1]s, %s)\n", v, zero(f))
+		v = fmt.Sprintf("(*%s)[len(*%[1]s)-1]", v)
+	}
+	switch f.kind {
+	case kindHot:
+		g.printf("if err := %s.UnmarshalXML(d, t); err != nil {\nreturn err\n}\n", v)
+	case kindOther:
+		if f.shape != shapePtr {
+			v = "&" + v
+		}
+		g.printf("if err := d.DecodeElement(%s, &t); err != nil {\nreturn err\n}\n", v)
+	default:
+		g.printf("b, err := decodeText(d)\nif err != nil {\nreturn err\n}\n")
+		g.parse(field{name: strings.TrimPrefix(v, "v."), kind: f.kind}, "b", true)
+	}
+}
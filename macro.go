@@ -0,0 +1,526 @@
+package vast
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MacroPolicy defines how macros without a value are expanded.
+type MacroPolicy int
+
+const (
+	// KeepUnknownMacros leaves macros without a value untouched, so that they
+	// can be expanded later on by another party.
+	KeepUnknownMacros MacroPolicy = iota
+	// ReplaceUnknownMacros replaces macros without a value by -1, as the spec
+	// requires for unsupported or unknown values.
+	ReplaceUnknownMacros
+)
+
+const (
+	// macroUnknown is the value of an unsupported or unknown macro.
+	macroUnknown = "-1"
+	// macroRestricted is the value of a macro which is not shared because of a
+	// policy.
+	macroRestricted = "-2"
+)
+
+// TimestampFormat is the ISO 8601 layout used to expand the [TIMESTAMP] macro.
+const TimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// MacroContext holds the values used to expand the VAST 4.2 macros found in
+// tracking, error and click URIs.
+//
+// The zero value of a field means the value is unknown, except for
+// [TIMESTAMP] and [CACHEBUSTING] which are always generated when missing.
+type MacroContext struct {
+	// Policy defines how macros without a value are expanded.
+	Policy MacroPolicy
+	// Restricted lists the macros, without brackets, which value can't be
+	// shared because of a policy (e.g. "IFA"). They are replaced by -2.
+	Restricted []string
+	// Custom is called for the macros which have no value in the context,
+	// including those unknown to this package. The returned value is
+	// percent-encoded before being inserted.
+	Custom func(name string) (value string, ok bool)
+
+	// [TIMESTAMP]: the date and time at which the URI was generated.
+	Timestamp time.Time
+	// [CACHEBUSTING]: a random 8-digit integer.
+	CacheBusting string
+
+	// [ERRORCODE]: the VAST error code.
+	ErrorCode ErrorCode
+	// [REASON]: the reason code for verificationNotExecuted and error events.
+	Reason int
+
+	// [CONTENTPLAYHEAD] and [MEDIAPLAYHEAD]: the playhead of the content.
+	ContentPlayhead *Duration
+	// [ADPLAYHEAD]: the playhead of the ad.
+	AdPlayhead *Duration
+	// [ASSETURI]: the URI of the media file being played.
+	AssetURI string
+	// [BREAKPOSITION]: 1 for pre-roll, 2 for mid-roll, 3 for post-roll and
+	// 4 for stand-alone.
+	BreakPosition int
+	// [BREAKMAXDURATION]: the maximum duration of the ad break.
+	BreakMaxDuration *Duration
+	// [BREAKMINDURATION]: the minimum duration of the ad break.
+	BreakMinDuration *Duration
+	// [BREAKMAXADS]: the maximum number of ads in the ad break.
+	BreakMaxAds int
+	// [BREAKMINADLENGTH]: the minimum duration of an ad in the ad break.
+	BreakMinAdLength *Duration
+	// [BREAKMAXADLENGTH]: the maximum duration of an ad in the ad break.
+	BreakMaxAdLength *Duration
+	// [PODSEQUENCE]: the sequence attribute of the ad being played.
+	PodSequence int
+	// [ADCOUNT]: the number of ads played in the current ad break.
+	AdCount int
+	// [ADSERVINGID]: the AdServingId of the ad being played.
+	AdServingID string
+	// [ADTYPE]: the type of ad, either "video", "audio" or "hybrid".
+	AdType string
+	// [UNIVERSALADID]: the universal ad id, as "registry value".
+	UniversalAdID string
+	// [TRANSACTIONID]: an identifier shared by every party of the ad request.
+	TransactionID string
+	// [PLACEMENTTYPE]: the placement type of the ad (1 to 5).
+	PlacementType int
+	// [ADCATEGORIES]: the categories of the ad.
+	AdCategories []string
+	// [BLOCKEDADCATEGORIES]: the categories blocked by the publisher.
+	BlockedAdCategories []string
+
+	// [IFA]: the identifier for advertising.
+	IFA string
+	// [IFATYPE]: the kind of IFA, e.g. "aaid" or "idfa".
+	IFAType string
+	// [CLIENTUA]: the name and version of the client library.
+	ClientUA string
+	// [SERVERUA]: the user agent of the server making the request.
+	ServerUA string
+	// [DEVICEUA]: the user agent of the device.
+	DeviceUA string
+	// [SERVERSIDE]: 0 for client side requests, 1 for server side requests
+	// relaying the client context and 2 for server side requests.
+	ServerSide *int
+	// [DEVICEIP]: the IP address of the device.
+	DeviceIP string
+	// [LATLONG]: the location of the device, as "lat,long".
+	LatLong string
+	// [DOMAIN]: the domain of the page playing the ad.
+	Domain string
+	// [PAGEURL]: the URL of the page playing the ad.
+	PageURL string
+	// [APPBUNDLE]: the bundle id of the app playing the ad.
+	AppBundle string
+	// [CONTENTID]: the identifier of the content.
+	ContentID string
+	// [CONTENTURI]: the URI of the content.
+	ContentURI string
+	// [VASTVERSIONS]: the VAST versions supported by the player, as protocol
+	// numbers.
+	VASTVersions []int
+	// [APIFRAMEWORKS]: the API frameworks supported by the player.
+	APIFrameworks []int
+	// [EXTENSIONS]: the VAST extension types supported by the player.
+	Extensions []string
+	// [VERIFICATIONVENDORS]: the verification vendors supported by the
+	// player.
+	VerificationVendors []string
+	// [OMIDPARTNER]: the OM SDK partner name and version, as "name/version".
+	OMIDPartner string
+	// [MEDIAMIME]: the media MIME types supported by the player.
+	MediaMIME []string
+	// [PLAYERCAPABILITIES]: the capabilities of the player, e.g. "skip".
+	PlayerCapabilities []string
+	// [CLICKTYPE]: 0 for no click, 1 for a click opening a page, 2 for a
+	// click opening a page in the app and 3 for an external click.
+	ClickType int
+	// [PLAYERSTATE]: the state of the player, e.g. "muted" or "fullscreen".
+	PlayerState []string
+	// [INVENTORYSTATE]: the state of the inventory, e.g. "skippable".
+	InventoryState []string
+	// [PLAYERSIZE]: the size of the player, as "width,height".
+	PlayerSize string
+	// [CLICKPOS]: the position of the click, as "x,y".
+	ClickPos string
+
+	// [GDPRCONSENT]: the IAB TCF consent string.
+	GDPRConsent string
+	// [LIMITADTRACKING]: whether the user opted out of ad tracking.
+	LimitAdTracking *bool
+	// [REGULATIONS]: the regulations applying to the request, e.g. "gdpr".
+	Regulations []string
+}
+
+// macros maps the name of every macro known to the package to its value in a
+// MacroContext. A nil value means the value is unknown.
+var macros = map[string]func(c *MacroContext) []string{
+	"TIMESTAMP":    func(c *MacroContext) []string { return []string{c.Timestamp.Format(TimestampFormat)} },
+	"CACHEBUSTING": func(c *MacroContext) []string { return []string{c.CacheBusting} },
+
+	"ERRORCODE": func(c *MacroContext) []string { return intMacro(int(c.ErrorCode)) },
+	"REASON":    func(c *MacroContext) []string { return intMacro(c.Reason) },
+
+	"CONTENTPLAYHEAD":     func(c *MacroContext) []string { return durationMacro(c.ContentPlayhead) },
+	"MEDIAPLAYHEAD":       func(c *MacroContext) []string { return durationMacro(c.ContentPlayhead) },
+	"ADPLAYHEAD":          func(c *MacroContext) []string { return durationMacro(c.AdPlayhead) },
+	"ASSETURI":            func(c *MacroContext) []string { return stringMacro(c.AssetURI) },
+	"BREAKPOSITION":       func(c *MacroContext) []string { return intMacro(c.BreakPosition) },
+	"BREAKMAXDURATION":    func(c *MacroContext) []string { return secondsMacro(c.BreakMaxDuration) },
+	"BREAKMINDURATION":    func(c *MacroContext) []string { return secondsMacro(c.BreakMinDuration) },
+	"BREAKMAXADS":         func(c *MacroContext) []string { return intMacro(c.BreakMaxAds) },
+	"BREAKMINADLENGTH":    func(c *MacroContext) []string { return secondsMacro(c.BreakMinAdLength) },
+	"BREAKMAXADLENGTH":    func(c *MacroContext) []string { return secondsMacro(c.BreakMaxAdLength) },
+	"PODSEQUENCE":         func(c *MacroContext) []string { return intMacro(c.PodSequence) },
+	"ADCOUNT":             func(c *MacroContext) []string { return intMacro(c.AdCount) },
+	"ADSERVINGID":         func(c *MacroContext) []string { return stringMacro(c.AdServingID) },
+	"ADTYPE":              func(c *MacroContext) []string { return stringMacro(c.AdType) },
+	"UNIVERSALADID":       func(c *MacroContext) []string { return stringMacro(c.UniversalAdID) },
+	"TRANSACTIONID":       func(c *MacroContext) []string { return stringMacro(c.TransactionID) },
+	"PLACEMENTTYPE":       func(c *MacroContext) []string { return intMacro(c.PlacementType) },
+	"ADCATEGORIES":        func(c *MacroContext) []string { return c.AdCategories },
+	"BLOCKEDADCATEGORIES": func(c *MacroContext) []string { return c.BlockedAdCategories },
+
+	"IFA":                 func(c *MacroContext) []string { return stringMacro(c.IFA) },
+	"IFATYPE":             func(c *MacroContext) []string { return stringMacro(c.IFAType) },
+	"CLIENTUA":            func(c *MacroContext) []string { return stringMacro(c.ClientUA) },
+	"SERVERUA":            func(c *MacroContext) []string { return stringMacro(c.ServerUA) },
+	"DEVICEUA":            func(c *MacroContext) []string { return stringMacro(c.DeviceUA) },
+	"SERVERSIDE":          func(c *MacroContext) []string { return intPtrMacro(c.ServerSide) },
+	"DEVICEIP":            func(c *MacroContext) []string { return stringMacro(c.DeviceIP) },
+	"LATLONG":             func(c *MacroContext) []string { return stringMacro(c.LatLong) },
+	"DOMAIN":              func(c *MacroContext) []string { return stringMacro(c.Domain) },
+	"PAGEURL":             func(c *MacroContext) []string { return stringMacro(c.PageURL) },
+	"APPBUNDLE":           func(c *MacroContext) []string { return stringMacro(c.AppBundle) },
+	"CONTENTID":           func(c *MacroContext) []string { return stringMacro(c.ContentID) },
+	"CONTENTURI":          func(c *MacroContext) []string { return stringMacro(c.ContentURI) },
+	"VASTVERSIONS":        func(c *MacroContext) []string { return intsMacro(c.VASTVersions) },
+	"APIFRAMEWORKS":       func(c *MacroContext) []string { return intsMacro(c.APIFrameworks) },
+	"EXTENSIONS":          func(c *MacroContext) []string { return c.Extensions },
+	"VERIFICATIONVENDORS": func(c *MacroContext) []string { return c.VerificationVendors },
+	"OMIDPARTNER":         func(c *MacroContext) []string { return stringMacro(c.OMIDPartner) },
+	"MEDIAMIME":           func(c *MacroContext) []string { return c.MediaMIME },
+	"PLAYERCAPABILITIES":  func(c *MacroContext) []string { return c.PlayerCapabilities },
+	"CLICKTYPE":           func(c *MacroContext) []string { return intMacro(c.ClickType) },
+	"PLAYERSTATE":         func(c *MacroContext) []string { return c.PlayerState },
+	"INVENTORYSTATE":      func(c *MacroContext) []string { return c.InventoryState },
+	"PLAYERSIZE":          func(c *MacroContext) []string { return stringMacro(c.PlayerSize) },
+	"CLICKPOS":            func(c *MacroContext) []string { return stringMacro(c.ClickPos) },
+
+	"GDPRCONSENT":     func(c *MacroContext) []string { return stringMacro(c.GDPRConsent) },
+	"LIMITADTRACKING": func(c *MacroContext) []string { return boolMacro(c.LimitAdTracking) },
+	"REGULATIONS":     func(c *MacroContext) []string { return c.Regulations },
+}
+
+func stringMacro(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+func intMacro(n int) []string {
+	if n == 0 {
+		return nil
+	}
+	return []string{strconv.Itoa(n)}
+}
+
+func intPtrMacro(n *int) []string {
+	if n == nil {
+		return nil
+	}
+	return []string{strconv.Itoa(*n)}
+}
+
+func intsMacro(ns []int) []string {
+	if len(ns) == 0 {
+		return nil
+	}
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return s
+}
+
+func boolMacro(b *bool) []string {
+	if b == nil {
+		return nil
+	}
+	if *b {
+		return []string{"1"}
+	}
+	return []string{"0"}
+}
+
+// durationMacro formats a playhead as HH:MM:SS.mmm, milliseconds included.
+func durationMacro(d *Duration) []string {
+	if d == nil {
+		return nil
+	}
+	b, _ := d.MarshalText()
+	if !strings.Contains(string(b), ".") {
+		b = append(b, ".000"...)
+	}
+	return []string{string(b)}
+}
+
+// secondsMacro formats the break durations, which are expressed in seconds.
+func secondsMacro(d *Duration) []string {
+	if d == nil {
+		return nil
+	}
+	return []string{strconv.FormatInt(int64(time.Duration(*d)/time.Second), 10)}
+}
+
+// Expand returns uri with every [MACRO] replaced by its percent-encoded value.
+// Multi-valued macros are expanded as a comma separated list. A nil context
+// only expands [TIMESTAMP] and [CACHEBUSTING].
+func (c *MacroContext) Expand(uri string) string {
+	if strings.IndexByte(uri, '[') < 0 {
+		return uri
+	}
+	return c.withDefaults().expand(uri)
+}
+
+// withDefaults returns a copy of c with the generated values filled, so that
+// every URI expanded from it shares the same timestamp and cache buster.
+func (c *MacroContext) withDefaults() *MacroContext {
+	var c2 MacroContext
+	if c != nil {
+		c2 = *c
+	}
+	if c2.Timestamp.IsZero() {
+		c2.Timestamp = time.Now()
+	}
+	if c2.CacheBusting == "" {
+		c2.CacheBusting = strconv.Itoa(10000000 + rand.Intn(90000000))
+	}
+	return &c2
+}
+
+func (c *MacroContext) expand(uri string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(uri, '[')
+		if i < 0 {
+			break
+		}
+		b.WriteString(uri[:i])
+		uri = uri[i:]
+		j := strings.IndexByte(uri, ']')
+		if j < 0 {
+			break
+		}
+		if name := uri[1:j]; isMacroName(name) {
+			if value, ok := c.value(name); ok {
+				b.WriteString(value)
+				uri = uri[j+1:]
+				continue
+			}
+		}
+		// not a macro, or one which must be left as is: keep the bracket
+		// and look for the next one
+		b.WriteByte('[')
+		uri = uri[1:]
+	}
+	b.WriteString(uri)
+	return b.String()
+}
+
+// value returns the encoded value of the named macro and whether it should be
+// replaced at all.
+func (c *MacroContext) value(name string) (string, bool) {
+	for _, r := range c.Restricted {
+		if r == name {
+			return macroRestricted, true
+		}
+	}
+	if f, ok := macros[name]; ok {
+		if values := f(c); len(values) > 0 {
+			encoded := make([]string, len(values))
+			for i, v := range values {
+				encoded[i] = EncodeMacroValue(v)
+			}
+			return strings.Join(encoded, ","), true
+		}
+	}
+	if c.Custom != nil {
+		if v, ok := c.Custom(name); ok {
+			return EncodeMacroValue(v), true
+		}
+	}
+	if c.Policy == ReplaceUnknownMacros {
+		return macroUnknown, true
+	}
+	return "", false
+}
+
+func isMacroName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodeMacroValue percent-encodes s as required by the spec for macro
+// values: every byte but the RFC 3986 unreserved characters is encoded.
+func EncodeMacroValue(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
+
+// EventURLs walks v and returns the URIs to request for event, with their
+// macros expanded using c.
+//
+// event is either the name of a tracking event, such as EventTypeStart, or one
+// of EventTypeImpression, EventTypeError and EventTypeClickTracking. All the
+// URIs share the same [TIMESTAMP] and [CACHEBUSTING] values.
+func EventURLs(v *VAST, event string, c *MacroContext) []string {
+	var urls []string
+	c = c.withDefaults()
+	add := func(uri string) {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			urls = append(urls, c.expand(uri))
+		}
+	}
+	addTracking := func(te *TrackingEvents) {
+		if te == nil {
+			return
+		}
+		for _, t := range te.Tracking {
+			if t.Event == event {
+				add(t.URI)
+			}
+		}
+	}
+	// addAd adds the URIs of an InLine or Wrapper ad
+	addAd := func(impressions []Impression, errors []CDATAString, av *AdVerifications, creatives []eventCreative) {
+		switch event {
+		case EventTypeImpression:
+			for _, imp := range impressions {
+				add(imp.URI)
+			}
+		case EventTypeError:
+			for _, e := range errors {
+				add(e.CDATA)
+			}
+		}
+		if av != nil {
+			for _, ver := range av.Verification {
+				addTracking(ver.TrackingEvents)
+			}
+		}
+		for _, cr := range creatives {
+			for _, te := range cr.tracking {
+				addTracking(te)
+			}
+			if event != EventTypeClickTracking {
+				continue
+			}
+			for _, uri := range cr.clicks {
+				add(uri)
+			}
+		}
+	}
+
+	if event == EventTypeError {
+		for _, e := range v.Errors {
+			add(e.CDATA)
+		}
+	}
+	for _, ad := range v.Ads {
+		if in := ad.InLine; in != nil {
+			creatives := make([]eventCreative, len(in.Creatives))
+			for i, cr := range in.Creatives {
+				ec := &creatives[i]
+				if cr.Linear != nil {
+					ec.addLinear(cr.Linear.TrackingEvents, cr.Linear.VideoClicks)
+				}
+				if cr.NonLinearAds != nil {
+					ec.tracking = append(ec.tracking, cr.NonLinearAds.TrackingEvents)
+					for _, nl := range cr.NonLinearAds.NonLinears {
+						for _, ct := range nl.NonLinearClickTrackings {
+							ec.clicks = append(ec.clicks, ct.URI)
+						}
+					}
+				}
+				ec.addCompanions(cr.CompanionAds)
+			}
+			addAd(in.Impressions, in.Errors, in.AdVerifications, creatives)
+		}
+		if w := ad.Wrapper; w != nil {
+			creatives := make([]eventCreative, len(w.Creatives))
+			for i, cr := range w.Creatives {
+				ec := &creatives[i]
+				if cr.Linear != nil {
+					ec.addLinear(cr.Linear.TrackingEvents, cr.Linear.VideoClicks)
+				}
+				if cr.NonLinearAds != nil {
+					ec.tracking = append(ec.tracking, cr.NonLinearAds.TrackingEvents)
+					for _, nl := range cr.NonLinearAds.NonLinears {
+						ec.tracking = append(ec.tracking, nl.TrackingEvents)
+						for _, ct := range nl.NonLinearClickTracking {
+							ec.clicks = append(ec.clicks, ct.CDATA)
+						}
+					}
+				}
+				ec.addCompanions(cr.CompanionAds)
+			}
+			addAd(w.Impressions, w.Errors, w.AdVerifications, creatives)
+		}
+	}
+	return urls
+}
+
+// eventCreative holds the event URIs of an InLine or Wrapper creative: its
+// tracking events, and its click tracking URIs.
+type eventCreative struct {
+	tracking []*TrackingEvents
+	clicks   []string
+}
+
+func (ec *eventCreative) addLinear(te *TrackingEvents, vc *VideoClicks) {
+	ec.tracking = append(ec.tracking, te)
+	if vc != nil {
+		for _, ct := range vc.ClickTrackings {
+			ec.clicks = append(ec.clicks, ct.URI)
+		}
+	}
+}
+
+func (ec *eventCreative) addCompanions(ca *CompanionAds) {
+	if ca == nil {
+		return
+	}
+	for _, comp := range ca.Companions {
+		ec.tracking = append(ec.tracking, comp.TrackingEvents)
+		for _, ct := range comp.CompanionClickTrackings {
+			ec.clicks = append(ec.clicks, ct.URI)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMacroExpand(t *testing.T) {
+	ts := time.Date(2016, 1, 17, 8, 15, 7, 127*int(time.Millisecond), time.FixedZone("", -5*3600))
+	playhead := Duration(5*time.Minute + 2*time.Second)
+	c := &MacroContext{
+		Timestamp:       ts,
+		CacheBusting:    "12345678",
+		ErrorCode:       303,
+		ContentPlayhead: &playhead,
+		AssetURI:        "http://cdn.com/video.mp4?a=b c",
+		AdCategories:    []string{"IAB1", "IAB2-3"},
+	}
+	assert.Equal(t,
+		"http://t.com/e?e=303&t=2016-01-17T08%3A15%3A07.127-05%3A00&cb=12345678&ch=00%3A05%3A02.000&m=00%3A05%3A02.000&a=http%3A%2F%2Fcdn.com%2Fvideo.mp4%3Fa%3Db%20c&c=IAB1,IAB2-3",
+		c.Expand("http://t.com/e?e=[ERRORCODE]&t=[TIMESTAMP]&cb=[CACHEBUSTING]&ch=[CONTENTPLAYHEAD]&m=[MEDIAPLAYHEAD]&a=[ASSETURI]&c=[ADCATEGORIES]"))
+}
+
+func TestMacroExpandUnknown(t *testing.T) {
+	uri := "http://t.com/e?r=[REASON]&x=[FOO]&ifa=[IFA]&[not a macro]&[]"
+	c := &MacroContext{IFA: "abc"}
+	assert.Equal(t, "http://t.com/e?r=[REASON]&x=[FOO]&ifa=abc&[not a macro]&[]", c.Expand(uri))
+
+	c.Policy = ReplaceUnknownMacros
+	assert.Equal(t, "http://t.com/e?r=-1&x=-1&ifa=abc&[not a macro]&[]", c.Expand(uri))
+
+	c.Restricted = []string{"IFA"}
+	assert.Equal(t, "http://t.com/e?r=-1&x=-1&ifa=-2&[not a macro]&[]", c.Expand(uri))
+
+	c.Custom = func(name string) (string, bool) {
+		if name == "FOO" {
+			return "b&r", true
+		}
+		return "", false
+	}
+	assert.Equal(t, "http://t.com/e?r=-1&x=b%26r&ifa=-2&[not a macro]&[]", c.Expand(uri))
+}
+
+func TestMacroExpandDefaults(t *testing.T) {
+	var c *MacroContext
+	got := c.Expand("[CACHEBUSTING]")
+	assert.Len(t, got, 8)
+	assert.NotEqual(t, "[CACHEBUSTING]", got)
+	assert.Equal(t, "[[ERRORCODE]", c.Expand("[[ERRORCODE]"))
+	assert.Equal(t, "no macro", c.Expand("no macro"))
+}
+
+func TestEncodeMacroValue(t *testing.T) {
+	assert.Equal(t, "aZ09-._~", EncodeMacroValue("aZ09-._~"))
+	assert.Equal(t, "%2F%3F%26%3D%2B%20%C3%A9", EncodeMacroValue("/?&=+ é"))
+}
+
+func TestEventURLs(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	v.Errors = []CDATAString{{CDATA: "http://root/error?e=[ERRORCODE]"}}
+	c := &MacroContext{ErrorCode: 100}
+
+	assert.Equal(t, []string{"http://root/error?e=100", "http://myErrorURL/error", "http://myErrorURL/error2"}, EventURLs(v, EventTypeError, c))
+	assert.Equal(t, []string{"http://myTrackingURL/impression", "http://myTrackingURL/impression2"}, EventURLs(v, EventTypeImpression, c))
+	assert.Equal(t, []string{"http://myTrackingURL/start"}, EventURLs(v, EventTypeStart, c))
+	assert.Equal(t, []string{"http://myTrackingURL/click"}, EventURLs(v, EventTypeClickTracking, c))
+	assert.Equal(t, []string{"http://myTrackingURL/creativeView", "http://myTrackingURL/firstCompanionCreativeView"}, EventURLs(v, EventTypeCreativeView, c))
+	assert.Empty(t, EventURLs(v, EventTypeSkip, c))
+
+	w, _, _, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"http://myTrackingURL/wrapper/click"}, EventURLs(w, EventTypeClickTracking, nil))
+	assert.Equal(t, []string{"http://myTrackingURL/wrapper/impression"}, EventURLs(w, EventTypeImpression, nil))
+}
@@ -0,0 +1,293 @@
+package vast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Media delivery methods.
+const (
+	DeliveryProgressive = "progressive"
+	DeliveryStreaming   = "streaming"
+)
+
+// PlayerCapabilities describes what a player can play, for SelectMedia.
+// Empty fields don't constrain the selection, except APIFrameworks.
+type PlayerCapabilities struct {
+	// Supported MIME types, e.g. "video/mp4".
+	MIMETypes []string
+	// Supported delivery methods, progressive or streaming.
+	Delivery []string
+	// Supported codecs, matched as prefixes of the codec attribute, e.g.
+	// "avc1" matches "avc1.42E01E". Files without codec are accepted.
+	Codecs []string
+	// Size of the player in pixels.
+	Width, Height int
+	// Available bandwidth in Kbps.
+	Bandwidth int
+	// Supported API frameworks, e.g. "VPAID" or "SIMID". Files requiring an
+	// API framework are rejected unless it is listed.
+	APIFrameworks []string
+	// API framework preferred when several files are playable.
+	PreferredAPIFramework string
+	// Whether mezzanine files are accepted, typically by ad stitching
+	// servers transcoding them.
+	Mezzanine bool
+}
+
+// MediaKind is the kind of a media candidate.
+type MediaKind int
+
+// Media kinds, in order of preference.
+const (
+	MediaKindFile MediaKind = iota
+	MediaKindInteractive
+	MediaKindMezzanine
+)
+
+// String implements the fmt.Stringer interface.
+func (k MediaKind) String() string {
+	switch k {
+	case MediaKindFile:
+		return "MediaFile"
+	case MediaKindInteractive:
+		return "InteractiveCreativeFile"
+	case MediaKindMezzanine:
+		return "Mezzanine"
+	}
+	return fmt.Sprintf("MediaKind(%d)", int(k))
+}
+
+// MediaCandidate is a media file considered by SelectMedia. Only the field
+// matching its Kind is set.
+type MediaCandidate struct {
+	Kind                    MediaKind
+	MediaFile               *MediaFile
+	Mezzanine               *Mezzanine
+	InteractiveCreativeFile *InteractiveCreativeFile
+	// Why the candidate was rejected, empty for accepted candidates.
+	Reasons []string
+
+	preferred bool
+	fit       float64
+	bitrate   int
+}
+
+// MediaSelection is the result of SelectMedia.
+type MediaSelection struct {
+	// Accepted candidates, best first.
+	Candidates []MediaCandidate
+	// Rejected candidates, in document order.
+	Rejected []MediaCandidate
+}
+
+// MediaFile returns the best media file, or nil if none was accepted.
+func (s *MediaSelection) MediaFile() *MediaFile {
+	for _, c := range s.Candidates {
+		if c.Kind == MediaKindFile {
+			return c.MediaFile
+		}
+	}
+	return nil
+}
+
+// InteractiveCreativeFile returns the best interactive creative file, or nil
+// if none was accepted.
+func (s *MediaSelection) InteractiveCreativeFile() *InteractiveCreativeFile {
+	for _, c := range s.Candidates {
+		if c.Kind == MediaKindInteractive {
+			return c.InteractiveCreativeFile
+		}
+	}
+	return nil
+}
+
+// Mezzanine returns the best mezzanine file, or nil if none was accepted.
+func (s *MediaSelection) Mezzanine() *Mezzanine {
+	for _, c := range s.Candidates {
+		if c.Kind == MediaKindMezzanine {
+			return c.Mezzanine
+		}
+	}
+	return nil
+}
+
+// SelectMedia ranks the files of m against the capabilities of a player.
+//
+// Candidates are ordered by kind (media files, then interactive creative
+// files, then mezzanine files), then files using the preferred API framework
+// come first, then files whose size best fits the player and last the files
+// with the highest bitrate the bandwidth allows. The files ranking equal keep
+// their document order.
+//
+// A file larger than the player which isn't scalable ranks below the files
+// which can cover the player, and a file which may be stretched, because its
+// aspect ratio differs from the player's and doesn't have to be maintained,
+// is penalized.
+func SelectMedia(m *MediaFiles, caps *PlayerCapabilities) *MediaSelection {
+	s := &MediaSelection{}
+	if m == nil {
+		return s
+	}
+	if caps == nil {
+		caps = &PlayerCapabilities{}
+	}
+	var all []MediaCandidate
+	for i := range m.MediaFile {
+		f := &m.MediaFile[i]
+		c := MediaCandidate{Kind: MediaKindFile, MediaFile: f}
+		caps.checkType(&c, f.Type)
+		caps.checkDelivery(&c, f.Delivery)
+		caps.checkCodec(&c, f.Codec)
+		if f.APIFramework != "" {
+			caps.checkAPIFramework(&c, f.APIFramework)
+		}
+		caps.checkBitrate(&c, f)
+		c.fit = caps.fit(f.Width, f.Height, f.Scalable, f.MaintainAspectRatio)
+		all = append(all, c)
+	}
+	for i := range m.InteractiveCreativeFile {
+		f := &m.InteractiveCreativeFile[i]
+		c := MediaCandidate{Kind: MediaKindInteractive, InteractiveCreativeFile: f}
+		caps.checkAPIFramework(&c, f.ApiFramework)
+		all = append(all, c)
+	}
+	for i := range m.Mezzanine {
+		f := &m.Mezzanine[i]
+		c := MediaCandidate{Kind: MediaKindMezzanine, Mezzanine: f}
+		if !caps.Mezzanine {
+			c.reject("mezzanine files not accepted")
+		}
+		caps.checkType(&c, f.Type)
+		caps.checkDelivery(&c, f.Delivery)
+		caps.checkCodec(&c, f.Codec)
+		c.fit = caps.fit(f.Width, f.Height, true, true)
+		all = append(all, c)
+	}
+
+	for _, c := range all {
+		if len(c.Reasons) > 0 {
+			s.Rejected = append(s.Rejected, c)
+		} else {
+			s.Candidates = append(s.Candidates, c)
+		}
+	}
+	sort.SliceStable(s.Candidates, func(i, j int) bool {
+		a, b := &s.Candidates[i], &s.Candidates[j]
+		switch {
+		case a.Kind != b.Kind:
+			return a.Kind < b.Kind
+		case a.preferred != b.preferred:
+			return a.preferred
+		case a.fit != b.fit:
+			return a.fit > b.fit
+		}
+		return a.bitrate > b.bitrate
+	})
+	return s
+}
+
+func (c *MediaCandidate) reject(format string, args ...interface{}) {
+	c.Reasons = append(c.Reasons, fmt.Sprintf(format, args...))
+}
+
+func (caps *PlayerCapabilities) checkType(c *MediaCandidate, mimeType string) {
+	t := strings.TrimSpace(mimeType)
+	if i := strings.IndexByte(t, ';'); i >= 0 {
+		t = strings.TrimSpace(t[:i])
+	}
+	if len(caps.MIMETypes) > 0 && !containsFold(caps.MIMETypes, t) {
+		c.reject("unsupported type %q", mimeType)
+	}
+}
+
+func (caps *PlayerCapabilities) checkDelivery(c *MediaCandidate, delivery string) {
+	if len(caps.Delivery) > 0 && !containsFold(caps.Delivery, strings.TrimSpace(delivery)) {
+		c.reject("unsupported delivery %q", delivery)
+	}
+}
+
+func (caps *PlayerCapabilities) checkCodec(c *MediaCandidate, codec string) {
+	codec = strings.ToLower(strings.TrimSpace(codec))
+	if codec == "" || len(caps.Codecs) == 0 {
+		return
+	}
+	for _, supported := range caps.Codecs {
+		if strings.HasPrefix(codec, strings.ToLower(supported)) {
+			return
+		}
+	}
+	c.reject("unsupported codec %q", codec)
+}
+
+func (caps *PlayerCapabilities) checkAPIFramework(c *MediaCandidate, api string) {
+	api = strings.TrimSpace(api)
+	if api == "" {
+		c.reject("missing apiFramework")
+		return
+	}
+	if !containsFold(caps.APIFrameworks, api) {
+		c.reject("unsupported apiFramework %q", api)
+		return
+	}
+	c.preferred = strings.EqualFold(api, caps.PreferredAPIFramework)
+}
+
+// checkBitrate rejects files which need more than the bandwidth and sets the
+// bitrate the file will be played at.
+func (caps *PlayerCapabilities) checkBitrate(c *MediaCandidate, f *MediaFile) {
+	min, max := f.Bitrate, f.Bitrate
+	if f.Bitrate == 0 {
+		min, max = f.MinBitrate, f.MaxBitrate
+	}
+	c.bitrate = max
+	if caps.Bandwidth <= 0 {
+		return
+	}
+	if min > caps.Bandwidth {
+		c.reject("bitrate %d Kbps exceeds bandwidth %d Kbps", min, caps.Bandwidth)
+		return
+	}
+	if max > caps.Bandwidth {
+		// adaptive streams play at the highest bitrate the bandwidth allows
+		c.bitrate = caps.Bandwidth
+	}
+}
+
+// fit scores how well a file of the given size fits the player, from 0 to 1.
+// Files at least as large as the player score above 0.5, the closest to its
+// size being the best, and smaller ones score below 0.5, the larger being
+// the best.
+func (caps *PlayerCapabilities) fit(width, height int, scalable, maintainAspectRatio bool) float64 {
+	if caps.Width <= 0 || caps.Height <= 0 || width <= 0 || height <= 0 {
+		return 0
+	}
+	scale := math.Min(float64(width)/float64(caps.Width), float64(height)/float64(caps.Height))
+	var fit float64
+	if scale >= 1 {
+		fit = 0.5 + 0.5/scale
+		if !scalable && scale > 1 {
+			fit -= 0.5
+		}
+	} else {
+		fit = 0.5 * scale
+	}
+	aspect := float64(width) / float64(height)
+	playerAspect := float64(caps.Width) / float64(caps.Height)
+	if !maintainAspectRatio && math.Abs(aspect-playerAspect)/playerAspect > 0.01 {
+		// the file may be stretched to the player's aspect ratio
+		fit *= 0.9
+	}
+	return fit
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package vast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func candidateIDs(cs []MediaCandidate) []string {
+	ids := make([]string, len(cs))
+	for i, c := range cs {
+		switch c.Kind {
+		case MediaKindFile:
+			ids[i] = c.MediaFile.ID
+		case MediaKindInteractive:
+			ids[i] = c.InteractiveCreativeFile.URI
+		case MediaKindMezzanine:
+			ids[i] = c.Mezzanine.ID
+		}
+	}
+	return ids
+}
+
+func TestSelectMedia(t *testing.T) {
+	m := &MediaFiles{
+		MediaFile: []MediaFile{
+			{ID: "flv", Delivery: "progressive", Type: "video/x-flv", Width: 640, Height: 360, Bitrate: 500},
+			{ID: "360p", Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, Bitrate: 800, Scalable: true, MaintainAspectRatio: true},
+			{ID: "360p-low", Delivery: "progressive", Type: "video/mp4", Width: 640, Height: 360, Bitrate: 400, Scalable: true, MaintainAspectRatio: true},
+			{ID: "720p", Delivery: "progressive", Type: "video/mp4", Width: 1280, Height: 720, Bitrate: 2000, Scalable: true, MaintainAspectRatio: true},
+			{ID: "1080p", Delivery: "progressive", Type: "video/mp4", Width: 1920, Height: 1080, Bitrate: 4000, Scalable: true, MaintainAspectRatio: true},
+			{ID: "hls", Delivery: "streaming", Type: "application/x-mpegURL", Width: 1920, Height: 1080, MinBitrate: 400, MaxBitrate: 4000},
+			{ID: "hevc", Delivery: "progressive", Type: "video/mp4", Codec: "hvc1.1.6.L93.90", Width: 640, Height: 360, Bitrate: 300},
+			{ID: "vpaid", Delivery: "progressive", Type: "application/javascript", APIFramework: "VPAID", Width: 640, Height: 360},
+			{ID: "big", Delivery: "progressive", Type: "video/mp4", Width: 3840, Height: 2160, Bitrate: 500},
+		},
+		InteractiveCreativeFile: []InteractiveCreativeFile{
+			{ApiFramework: "SIMID", URI: "simid.js"},
+			{ApiFramework: "VPAID", URI: "vpaid.js"},
+		},
+		Mezzanine: []Mezzanine{
+			{ID: "mezz", Delivery: "progressive", Type: "video/mp4", Width: 1920, Height: 1080},
+		},
+	}
+	caps := &PlayerCapabilities{
+		MIMETypes:     []string{"video/mp4", "application/x-mpegurl"},
+		Codecs:        []string{"avc1"},
+		Width:         1280,
+		Height:        720,
+		Bandwidth:     2500,
+		APIFrameworks: []string{"SIMID"},
+	}
+	s := SelectMedia(m, caps)
+	assert.Equal(t, []string{"720p", "hls", "360p", "360p-low", "big", "simid.js"}, candidateIDs(s.Candidates))
+	assert.Equal(t, "720p", s.MediaFile().ID)
+	assert.Equal(t, "simid.js", s.InteractiveCreativeFile().URI)
+	assert.Nil(t, s.Mezzanine())
+
+	rejected := map[string][]string{}
+	for _, c := range s.Rejected {
+		rejected[candidateIDs([]MediaCandidate{c})[0]] = c.Reasons
+	}
+	assert.Equal(t, map[string][]string{
+		"flv":      {`unsupported type "video/x-flv"`},
+		"1080p":    {"bitrate 4000 Kbps exceeds bandwidth 2500 Kbps"},
+		"hevc":     {`unsupported codec "hvc1.1.6.l93.90"`},
+		"vpaid":    {`unsupported type "application/javascript"`, `unsupported apiFramework "VPAID"`},
+		"vpaid.js": {`unsupported apiFramework "VPAID"`},
+		"mezz":     {"mezzanine files not accepted"},
+	}, rejected)
+
+	caps = &PlayerCapabilities{
+		APIFrameworks:         []string{"VPAID", "SIMID"},
+		PreferredAPIFramework: "VPAID",
+		Mezzanine:             true,
+	}
+	s = SelectMedia(m, caps)
+	assert.Equal(t, "vpaid", s.MediaFile().ID)
+	assert.Equal(t, "vpaid.js", s.InteractiveCreativeFile().URI)
+	assert.Equal(t, "mezz", s.Mezzanine().ID)
+	assert.Empty(t, s.Rejected)
+
+	assert.Empty(t, SelectMedia(nil, nil).Candidates)
+}
@@ -0,0 +1,195 @@
+package vast
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// trackingEvents maps the lower case names of the tracking events to their
+// canonical spelling.
+var trackingEvents = map[string]string{}
+
+func init() {
+	for _, event := range []string{
+		EventTypeMute, EventTypeUnmute, EventTypePause, EventTypeResume,
+		EventTypeRewind, EventTypeSkip, EventTypePlayerExpand,
+		EventTypePlayerCollapse, EventTypeNotUsed, EventTypeLoaded,
+		EventTypeStart, EventTypeFirstQuartile, EventTypeMidpoint,
+		EventTypeThirdQuartile, EventTypeComplete, EventTypeOtherAdInteraction,
+		EventTypeProgress, EventTypeCloseLinear, EventTypeCreativeView,
+		EventTypeAcceptInvitation, EventTypeAdExpand, EventTypeAdCollapse,
+		EventTypeMinimize, EventTypeClose, EventTypeOverlayViewDuration,
+		EventTypeInteractiveStart, EventTypeView, EventTypeMonitor,
+	} {
+		trackingEvents[strings.ToLower(event)] = event
+	}
+}
+
+// Normalize rewrites v in place into a canonical form, so that documents
+// meaning the same thing compare and hash the same:
+//
+//   - the leading and trailing white space of the text of the elements, such
+//     as URIs, is trimmed;
+//   - the tracking events named like one of the EventType constants
+//     regardless of the case are renamed to it;
+//   - the duplicate impressions, tracking events and URIs are removed, and
+//     the remaining ones are sorted, as are the click tracking URIs;
+//   - the empty lists, such as an empty <Extensions>, and the empty optional
+//     containers, such as <TrackingEvents> or <VideoClicks> without children,
+//     are removed.
+//
+// The order of the other lists, such as the ads, creatives, media files and
+// extensions, is left untouched, as is the content of the extensions and of
+// the unknown elements.
+func Normalize(v *VAST) {
+	if v == nil {
+		return
+	}
+	normalize(reflect.ValueOf(v).Elem())
+}
+
+// normalize normalizes the addressable value v.
+func normalize(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			normalize(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			normalize(v.Index(i))
+		}
+		normalizeList(v)
+	case reflect.Struct:
+		t := v.Type()
+		if t == unknownElementType {
+			// leave the mixed content as is
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" || f.Name == "XMLName" {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.String {
+				if tag := f.Tag.Get("xml"); strings.Contains(tag, ",cdata") || strings.Contains(tag, ",chardata") {
+					fv.SetString(strings.TrimSpace(fv.String()))
+				}
+				continue
+			}
+			normalize(fv)
+			switch {
+			case fv.Kind() == reflect.Slice && fv.Len() == 0:
+				fv.Set(reflect.Zero(fv.Type()))
+			case fv.Kind() == reflect.Ptr && !fv.IsNil() && emptyContainer(fv):
+				fv.Set(reflect.Zero(fv.Type()))
+			}
+		}
+		switch e := v.Addr().Interface().(type) {
+		case *Tracking:
+			e.Event = strings.TrimSpace(e.Event)
+			if event, ok := trackingEvents[strings.ToLower(e.Event)]; ok {
+				e.Event = event
+			}
+		case *VideoClicks:
+			// only the first click through is followed, the order of the
+			// other clicks doesn't matter
+			sortUnique(reflect.ValueOf(&e.ClickTrackings).Elem(), func(i int) string {
+				return e.ClickTrackings[i].URI + "\x00" + e.ClickTrackings[i].ID
+			})
+			sortUnique(reflect.ValueOf(&e.CustomClicks).Elem(), func(i int) string {
+				return e.CustomClicks[i].URI + "\x00" + e.CustomClicks[i].ID
+			})
+		}
+	}
+}
+
+// normalizeList sorts the addressable list v and removes its duplicates if
+// the order of its elements doesn't matter.
+func normalizeList(v reflect.Value) {
+	switch l := v.Interface().(type) {
+	case []Tracking:
+		sortUnique(v, func(i int) string {
+			t := l[i]
+			offset := ""
+			if t.Offset != nil {
+				b, _ := t.Offset.MarshalText()
+				offset = string(b)
+			}
+			return t.Event + "\x00" + offset + "\x00" + t.URI + "\x00" + t.UA
+		})
+	case []Impression:
+		sortUnique(v, func(i int) string { return l[i].URI + "\x00" + l[i].ID })
+	case []CDATAString:
+		// all the lists of CDATAString are lists of URIs
+		sortUnique(v, func(i int) string { return l[i].CDATA })
+	case []CompanionClickTracking:
+		sortUnique(v, func(i int) string { return l[i].URI + "\x00" + l[i].ID })
+	case []NonLinearClickTracking:
+		sortUnique(v, func(i int) string { return l[i].URI + "\x00" + l[i].ID })
+	}
+}
+
+// sortUnique sorts the addressable list v by the keys of its elements, given
+// by key before sorting, and removes the elements with the same key as the
+// previous one.
+func sortUnique(v reflect.Value, key func(i int) string) {
+	if v.Len() < 2 {
+		return
+	}
+	keys := make([]string, v.Len())
+	for i := range keys {
+		keys[i] = key(i)
+	}
+	sort.Stable(keyedList{keys, reflect.Swapper(v.Interface())})
+	n := 1
+	for i := 1; i < len(keys); i++ {
+		if keys[i] != keys[n-1] {
+			v.Index(n).Set(v.Index(i))
+			keys[n] = keys[i]
+			n++
+		}
+	}
+	v.Set(v.Slice(0, n))
+}
+
+type keyedList struct {
+	keys []string
+	swap func(i, j int)
+}
+
+func (l keyedList) Len() int           { return len(l.keys) }
+func (l keyedList) Less(i, j int) bool { return l.keys[i] < l.keys[j] }
+func (l keyedList) Swap(i, j int) {
+	l.keys[i], l.keys[j] = l.keys[j], l.keys[i]
+	l.swap(i, j)
+}
+
+// emptyContainer reports whether the non nil pointer v points to an empty
+// list or to an optional element whose only purpose is to hold a list of
+// children and that has none.
+func emptyContainer(v reflect.Value) bool {
+	switch e := v.Interface().(type) {
+	case *TrackingEvents:
+		return len(e.Tracking) == 0
+	case *VideoClicks:
+		return len(e.ClickTrackings) == 0 && len(e.CustomClicks) == 0 && len(e.ClickThroughs) == 0
+	case *Icons:
+		return e.Icon == nil
+	case *CompanionAds:
+		return len(e.Companions) == 0
+	case *NonLinearAds:
+		return e.TrackingEvents == nil && len(e.NonLinears) == 0
+	case *NonLinearAdsWrapper:
+		return e.TrackingEvents == nil && len(e.NonLinears) == 0
+	case *AdVerifications:
+		return len(e.Verification) == 0
+	case *ViewableImpression:
+		return len(e.Viewable) == 0 && len(e.NotViewable) == 0 && len(e.ViewUndetermined) == 0
+	case *IconClickFallbackImages:
+		return len(e.IconClickFallbackImage) == 0
+	}
+	return v.Elem().Kind() == reflect.Slice && v.Elem().Len() == 0
+}
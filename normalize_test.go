@@ -0,0 +1,101 @@
+package vast
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTestdata(t *testing.T) {
+	for _, path := range codecFixtures(t) {
+		v, _, _, err := loadFixture(path)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		Normalize(v)
+		once, err := xml.Marshal(v)
+		assert.NoError(t, err, path)
+		Normalize(v)
+		twice, _ := xml.Marshal(v)
+		assert.Equal(t, string(once), string(twice), path)
+
+		// the normalized form survives a round trip
+		var w VAST
+		if assert.NoError(t, xml.Unmarshal(once, &w), path) {
+			Normalize(&w)
+			assert.Empty(t, Diff(v, &w), path)
+		}
+	}
+}
+
+func TestNormalizeExtraSpaces(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/extraspaces_vpaid.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	Normalize(v)
+	linear := v.Ads[0].InLine.Creatives[0].Linear
+	assert.Equal(t, "https://dummy.com/dummmy.js", linear.MediaFiles.MediaFile[0].URI)
+	assert.Equal(t, "<VAST></VAST>", linear.AdParameters.Parameters)
+	assert.Equal(t, "SpotXchange", v.Ads[0].InLine.AdSystem.Name)
+}
+
+func TestNormalize(t *testing.T) {
+	five := Duration(5 * time.Second)
+	v := &VAST{Ads: []Ad{{InLine: &InLine{
+		Errors:     []CDATAString{{" http://e/2 "}, {"http://e/1"}, {"http://e/2"}},
+		Extensions: &[]Extension{},
+		Impressions: []Impression{
+			{URI: "http://i/2"},
+			{ID: "a", URI: "\n  http://i/1\n"},
+			{URI: "http://i/2 "},
+			{ID: "b", URI: "http://i/1"},
+		},
+		Creatives: []Creative{{
+			CreativeExtensions: &[]Extension{},
+			Linear: &Linear{
+				Icons: &Icons{Icon: &[]Icon{}},
+				TrackingEvents: &TrackingEvents{Tracking: []Tracking{
+					{Event: "START", URI: " http://t/start"},
+					{Event: "progress", Offset: &Offset{Duration: &five}, URI: "http://t/5s"},
+					{Event: "firstquartile", URI: "http://t/q1"},
+					{Event: EventTypeStart, URI: "http://t/start"},
+					{Event: "fullscreen", URI: "http://t/fs"},
+				}},
+				VideoClicks: &VideoClicks{
+					ClickThroughs:  []VideoClick{{URI: " http://c/2"}, {URI: "http://c/1"}},
+					ClickTrackings: []VideoClick{{URI: "http://ct/2"}, {URI: "http://ct/1"}, {URI: "http://ct/2"}},
+				},
+			},
+			NonLinearAds: &NonLinearAds{TrackingEvents: &TrackingEvents{}, NonLinears: []NonLinear{}},
+		}},
+	}}}}
+	Normalize(v)
+
+	assert.Equal(t, &VAST{Ads: []Ad{{InLine: &InLine{
+		Errors: []CDATAString{{"http://e/1"}, {"http://e/2"}},
+		Impressions: []Impression{
+			{ID: "a", URI: "http://i/1"},
+			{ID: "b", URI: "http://i/1"},
+			{URI: "http://i/2"},
+		},
+		Creatives: []Creative{{
+			Linear: &Linear{
+				TrackingEvents: &TrackingEvents{Tracking: []Tracking{
+					{Event: EventTypeFirstQuartile, URI: "http://t/q1"},
+					{Event: "fullscreen", URI: "http://t/fs"},
+					{Event: EventTypeProgress, Offset: &Offset{Duration: &five}, URI: "http://t/5s"},
+					{Event: EventTypeStart, URI: "http://t/start"},
+				}},
+				VideoClicks: &VideoClicks{
+					ClickThroughs:  []VideoClick{{URI: "http://c/2"}, {URI: "http://c/1"}},
+					ClickTrackings: []VideoClick{{URI: "http://ct/1"}, {URI: "http://ct/2"}},
+				},
+			},
+		}},
+	}}}}, v)
+
+	Normalize(nil)
+}
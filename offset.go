@@ -2,15 +2,18 @@ package vast
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Offset represents either a vast.Duration or a percentage of the video duration.
 type Offset struct {
 	// If not nil, the Offset is duration based
 	Duration *Duration
-	// If Duration is nil, the Offset is percent based
+	// If Duration is nil, the Offset is percent based, as a fraction of the
+	// video duration between 0 and 1: 12.5% is 0.125
 	Percent float32
 }
 
@@ -19,20 +22,47 @@ func (o Offset) MarshalText() ([]byte, error) {
 	if o.Duration != nil {
 		return o.Duration.MarshalText()
 	}
-	return []byte(fmt.Sprintf("%d%%", int(o.Percent*100))), nil
+	// round to a hundred thousandth of a percent, about the precision of a
+	// float32, to hide its error, e.g. float32(0.1) is 0.10000000149
+	p := math.Round(float64(o.Percent)*100*1e5) / 1e5
+	return []byte(strconv.FormatFloat(p, 'f', -1, 64) + "%"), nil
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 func (o *Offset) UnmarshalText(data []byte) error {
-	if strings.HasSuffix(string(data), "%") {
-		p, err := strconv.ParseInt(string(data[:len(data)-1]), 10, 8)
-		if err != nil {
+	s := strings.TrimSpace(string(data))
+	if strings.HasSuffix(s, "%") {
+		p, err := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-1]), 64)
+		if err != nil || !(p >= 0 && p <= 100) {
 			return fmt.Errorf("invalid offset: %s", data)
 		}
-		o.Percent = float32(p) / 100
+		o.Duration = nil
+		o.Percent = float32(p / 100)
 		return nil
 	}
 	var d Duration
 	o.Duration = &d
+	o.Percent = 0
 	return o.Duration.UnmarshalText(data)
 }
+
+// Resolve returns the position of the offset in a creative of the given
+// duration, rounded to the millisecond. It returns false for a percent
+// offset when the duration is unknown, that is not positive.
+func (o Offset) Resolve(duration time.Duration) (time.Duration, bool) {
+	switch {
+	case o.Duration != nil:
+		return time.Duration(*o.Duration), true
+	case o.Percent == 0:
+		return 0, true
+	case duration <= 0:
+		return 0, false
+	}
+	ms := math.Round(float64(duration) * float64(o.Percent) / float64(time.Millisecond))
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// ResolveDuration is like Resolve for a VAST duration.
+func (o Offset) ResolveDuration(duration Duration) (time.Duration, bool) {
+	return o.Resolve(time.Duration(duration))
+}
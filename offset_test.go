@@ -2,6 +2,7 @@ package vast
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,6 +16,14 @@ func TestOffsetMarshaler(t *testing.T) {
 	if assert.NoError(t, err) {
 		assert.Equal(t, "10%", string(b))
 	}
+	b, err = Offset{Percent: .125}.MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "12.5%", string(b))
+	}
+	b, err = Offset{Percent: 1. / 3}.MarshalText()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "33.33333%", string(b))
+	}
 	d := Duration(0)
 	b, err = Offset{Duration: &d}.MarshalText()
 	if assert.NoError(t, err) {
@@ -26,7 +35,7 @@ func TestOffsetUnmarshaler(t *testing.T) {
 	var o Offset
 	if assert.NoError(t, o.UnmarshalText([]byte("0%"))) {
 		assert.Nil(t, o.Duration)
-		assert.Equal(t, float32(0.0), o.Percent)
+		assert.Equal(t, float32(0), o.Percent)
 	}
 	o = Offset{}
 	if assert.NoError(t, o.UnmarshalText([]byte("10%"))) {
@@ -41,5 +50,48 @@ func TestOffsetUnmarshaler(t *testing.T) {
 		assert.Equal(t, float32(0), o.Percent)
 	}
 	o = Offset{}
+	if assert.NoError(t, o.UnmarshalText([]byte("12.5%"))) {
+		assert.Nil(t, o.Duration)
+		assert.Equal(t, float32(0.125), o.Percent)
+	}
 	assert.EqualError(t, o.UnmarshalText([]byte("abc%")), "invalid offset: abc%")
+	assert.EqualError(t, o.UnmarshalText([]byte("-1%")), "invalid offset: -1%")
+	assert.EqualError(t, o.UnmarshalText([]byte("100.5%")), "invalid offset: 100.5%")
+}
+
+func TestOffsetRoundTrip(t *testing.T) {
+	for _, s := range []string{"0%", "12.5%", "33.333%", "99.999%", "100%", "00:00:01.001", "123:59:59.999"} {
+		var o Offset
+		if assert.NoError(t, o.UnmarshalText([]byte(s)), s) {
+			b, err := o.MarshalText()
+			if assert.NoError(t, err) {
+				assert.Equal(t, s, string(b))
+			}
+		}
+	}
+}
+
+func TestOffsetResolve(t *testing.T) {
+	d := Duration(5 * time.Second)
+	for _, tt := range []struct {
+		offset   Offset
+		duration time.Duration
+		want     time.Duration
+		ok       bool
+	}{
+		{Offset{Duration: &d}, 0, 5 * time.Second, true},
+		{Offset{Duration: &d}, time.Minute, 5 * time.Second, true},
+		{Offset{}, 0, 0, true},
+		{Offset{Percent: .5}, 0, 0, false},
+		{Offset{Percent: .125}, 30 * time.Second, 3750 * time.Millisecond, true},
+		{Offset{Percent: 1. / 3}, 10 * time.Second, 3333 * time.Millisecond, true},
+		{Offset{Percent: 1}, 2 * time.Hour, 2 * time.Hour, true},
+	} {
+		got, ok := tt.offset.Resolve(tt.duration)
+		assert.Equal(t, tt.ok, ok)
+		assert.Equal(t, tt.want, got)
+	}
+	got, ok := Offset{Percent: .25}.ResolveDuration(Duration(time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Second, got)
 }
@@ -0,0 +1,101 @@
+package vast
+
+import (
+	"sort"
+	"time"
+)
+
+// Pod holds the ads of a VAST response split between an ad pod, played in
+// sequence, and a buffet of standalone ads.
+type Pod struct {
+	// Ads with a sequence attribute, in play order.
+	Ads []Ad
+	// Standalone ads, in document order. They can be played on their own or
+	// replace pod ads which failed.
+	Buffet []Ad
+}
+
+// SplitPod splits ads between a pod and a buffet.
+//
+// Pod ads are ordered by sequence. Gaps in the sequence numbers are ignored
+// and ads sharing a sequence number keep their document order.
+func SplitPod(ads []Ad) Pod {
+	var p Pod
+	for _, ad := range ads {
+		if ad.Sequence > 0 {
+			p.Ads = append(p.Ads, ad)
+		} else {
+			p.Buffet = append(p.Buffet, ad)
+		}
+	}
+	sort.SliceStable(p.Ads, func(i, j int) bool {
+		return p.Ads[i].Sequence < p.Ads[j].Sequence
+	})
+	return p
+}
+
+// IsPod reports whether the response contains an ad pod. A response without
+// one should be played as a single ad picked from the buffet.
+func (p Pod) IsPod() bool {
+	return len(p.Ads) > 0
+}
+
+// WithoutConditional returns the pod without its conditional ads, for
+// placements where an ad must be served.
+func (p Pod) WithoutConditional() Pod {
+	return Pod{
+		Ads:    withoutConditional(p.Ads),
+		Buffet: withoutConditional(p.Buffet),
+	}
+}
+
+func withoutConditional(ads []Ad) []Ad {
+	var res []Ad
+	for _, ad := range ads {
+		if !ad.ConditionalAd {
+			res = append(res, ad)
+		}
+	}
+	return res
+}
+
+// Fallback replaces the pod ad at index i, which failed, with the first ad
+// of the buffet. The buffet ad takes the sequence of the ad it replaces and
+// is removed from the buffet.
+//
+// It returns false when the buffet is empty or when the failed ad is a
+// wrapper whose fallbackOnNoAd attribute is false.
+func (p *Pod) Fallback(i int) (Ad, bool) {
+	failed := p.Ads[i]
+	if len(p.Buffet) == 0 || (failed.Wrapper != nil && !boolDefault(failed.Wrapper.FallbackOnNoAd, true)) {
+		return Ad{}, false
+	}
+	ad := p.Buffet[0]
+	p.Buffet = p.Buffet[1:]
+	ad.Sequence = failed.Sequence
+	p.Ads[i] = ad
+	return ad, true
+}
+
+// Duration returns the total duration of the pod, summing the duration of
+// the first linear creative of each InLine ad. Wrapper ads don't carry a
+// duration and count for zero until resolved.
+func (p Pod) Duration() time.Duration {
+	var total time.Duration
+	for _, ad := range p.Ads {
+		total += adDuration(ad)
+	}
+	return total
+}
+
+func adDuration(ad Ad) time.Duration {
+	if ad.InLine == nil {
+		return 0
+	}
+	for _, cr := range ad.InLine.Creatives {
+		if cr.Linear != nil {
+			return time.Duration(cr.Linear.Duration)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func linearAd(id string, seq int, d time.Duration) Ad {
+	return Ad{
+		ID:       id,
+		Sequence: seq,
+		InLine: &InLine{
+			Creatives: []Creative{
+				{CompanionAds: &CompanionAds{}},
+				{Linear: &Linear{Duration: Duration(d)}},
+			},
+		},
+	}
+}
+
+func adIDs(ads []Ad) []string {
+	ids := make([]string, len(ads))
+	for i, ad := range ads {
+		ids[i] = ad.ID
+	}
+	return ids
+}
+
+func TestSplitPod(t *testing.T) {
+	p := SplitPod([]Ad{
+		linearAd("c", 5, 15*time.Second),
+		linearAd("buffet1", 0, 30*time.Second),
+		linearAd("a", 1, 10*time.Second),
+		linearAd("b1", 2, 5*time.Second),
+		{ID: "wrapper", Sequence: 3, Wrapper: &Wrapper{}},
+		linearAd("b2", 2, 5*time.Second),
+		{ID: "buffet2", ConditionalAd: true},
+	})
+	assert.True(t, p.IsPod())
+	assert.Equal(t, []string{"a", "b1", "b2", "wrapper", "c"}, adIDs(p.Ads))
+	assert.Equal(t, []string{"buffet1", "buffet2"}, adIDs(p.Buffet))
+	assert.Equal(t, 35*time.Second, p.Duration())
+
+	p = p.WithoutConditional()
+	assert.Equal(t, []string{"buffet1"}, adIDs(p.Buffet))
+
+	p = SplitPod([]Ad{linearAd("a", 0, 0), linearAd("b", 0, 0)})
+	assert.False(t, p.IsPod())
+	assert.Empty(t, p.Ads)
+	assert.Len(t, p.Buffet, 2)
+}
+
+func TestPodFallback(t *testing.T) {
+	no := false
+	p := SplitPod([]Ad{
+		{ID: "w1", Sequence: 1, Wrapper: &Wrapper{}},
+		{ID: "w2", Sequence: 2, Wrapper: &Wrapper{FallbackOnNoAd: &no}},
+		{ID: "i3", Sequence: 3, InLine: &InLine{}},
+		linearAd("buffet", 0, 20*time.Second),
+	})
+
+	_, ok := p.Fallback(1)
+	assert.False(t, ok)
+
+	ad, ok := p.Fallback(0)
+	if assert.True(t, ok) {
+		assert.Equal(t, "buffet", ad.ID)
+		assert.Equal(t, 1, ad.Sequence)
+	}
+	assert.Equal(t, []string{"buffet", "w2", "i3"}, adIDs(p.Ads))
+	assert.Empty(t, p.Buffet)
+	assert.Equal(t, 20*time.Second, p.Duration())
+
+	_, ok = p.Fallback(2)
+	assert.False(t, ok)
+}
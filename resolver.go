@@ -0,0 +1,392 @@
+package vast
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultMaxWrapperDepth is the number of wrappers a Resolver follows before
+// giving up. The spec asks players to support a chain of at least 5 wrappers.
+const DefaultMaxWrapperDepth = 5
+
+var (
+	// ErrWrapperLimit is returned when a wrapper chain is deeper than the
+	// resolver's MaxDepth.
+	ErrWrapperLimit = errors.New("wrapper limit reached")
+	// ErrNoAdsAfterWrapper is returned when the response to a VASTAdTagURI
+	// doesn't contain any usable ad.
+	ErrNoAdsAfterWrapper = errors.New("no ads VAST response after one or more wrappers")
+	// ErrWrapperNotAllowed is returned when a wrapper returns another wrapper
+	// while its followAdditionalWrappers attribute is false.
+	ErrWrapperNotAllowed = errors.New("additional wrappers are not allowed")
+	// ErrMissingAdTagURI is returned when a wrapper has an empty VASTAdTagURI.
+	ErrMissingAdTagURI = errors.New("wrapper has no VASTAdTagURI")
+)
+
+// Fetcher retrieves the raw VAST document referenced by a wrapper's
+// VASTAdTagURI.
+type Fetcher interface {
+	Fetch(ctx context.Context, uri string) ([]byte, error)
+}
+
+// FetcherFunc is an adapter to allow the use of ordinary functions as Fetcher.
+type FetcherFunc func(ctx context.Context, uri string) ([]byte, error)
+
+// Fetch calls f(ctx, uri).
+func (f FetcherFunc) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	return f(ctx, uri)
+}
+
+// HTTPFetcher is a Fetcher issuing GET requests with an http.Client.
+type HTTPFetcher struct {
+	// Client is the client used to issue requests. http.DefaultClient is used
+	// when nil.
+	Client *http.Client
+}
+
+// Fetch implements the Fetcher interface.
+func (f HTTPFetcher) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", uri, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Resolver follows the VASTAdTagURI of wrapper ads until it reaches InLine ads.
+type Resolver struct {
+	// Fetcher retrieves the documents referenced by wrappers.
+	Fetcher Fetcher
+	// MaxDepth is the maximum number of wrappers followed for a single ad.
+	// DefaultMaxWrapperDepth is used when zero.
+	MaxDepth int
+	// Timeout, when not zero, bounds the duration of each fetch.
+	Timeout time.Duration
+}
+
+// NewResolver returns a Resolver using f with the default wrapper depth.
+func NewResolver(f Fetcher) *Resolver {
+	return &Resolver{Fetcher: f, MaxDepth: DefaultMaxWrapperDepth}
+}
+
+// ResolvedAd is an InLine ad reached by following zero or more wrappers.
+type ResolvedAd struct {
+	// Ad holds the InLine ad. The Impressions, Errors, TrackingEvents,
+	// VideoClicks.ClickTrackings, ViewableImpression and AdVerifications of
+	// every wrapper of the chain are merged into it.
+	Ad Ad
+	// Wrappers is the chain of wrappers followed to reach the InLine ad,
+	// outermost first. It is empty when the ad was an InLine ad in the first
+	// place.
+	Wrappers []*Wrapper
+}
+
+// ResolveError describes the failure to resolve a single ad.
+type ResolveError struct {
+	// AdID is the id of the ad of the resolved document which couldn't be
+	// resolved.
+	AdID string
+	// URI is the VASTAdTagURI being followed when the failure occurred, if any.
+	URI string
+	// Depth is the number of wrappers followed when the failure occurred.
+	Depth int
+	// Wrappers is the chain of wrappers leading to the failure, outermost
+	// first. It ends with the wrapper which couldn't be resolved.
+	Wrappers []*Wrapper
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ResolveError) Error() string {
+	if e.URI == "" {
+		return fmt.Sprintf("ad %q: %v", e.AdID, e.Err)
+	}
+	return fmt.Sprintf("ad %q: %s: %v", e.AdID, e.URI, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// ResolveErrors is the list of failures encountered while resolving a VAST
+// document.
+type ResolveErrors []*ResolveError
+
+func (e ResolveErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any error in e matches target.
+func (e ResolveErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in e that matches target.
+func (e ResolveErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve follows the wrappers of v and returns its InLine ads.
+//
+// Ads are returned in document order. When v holds an ad pod, its stand-alone
+// ads are only used to replace pod ads failing to resolve whose wrapper allows
+// it through fallbackOnNoAd. A wrapper's followAdditionalWrappers and
+// allowMultipleAds attributes are honoured for the response it points to.
+//
+// If some ads couldn't be resolved, the returned error is a ResolveErrors
+// and the ads which could be resolved are returned alongside it.
+func (r *Resolver) Resolve(ctx context.Context, v *VAST) ([]ResolvedAd, error) {
+	var errs ResolveErrors
+	ads := r.resolveAds(ctx, v.Ads, 0, "", nil, &errs)
+	if len(errs) > 0 {
+		return ads, errs
+	}
+	return ads, nil
+}
+
+// resolveAds resolves a list of sibling ads, handling pods and the
+// fallbackOnNoAd attribute.
+func (r *Resolver) resolveAds(ctx context.Context, ads []Ad, depth int, rootID string, chain []*Wrapper, errs *ResolveErrors) []ResolvedAd {
+	pod := SplitPod(ads)
+	if !pod.IsPod() {
+		var res []ResolvedAd
+		for _, ad := range ads {
+			res = append(res, r.resolveAd(ctx, ad, depth, rootID, chain, errs)...)
+		}
+		return res
+	}
+
+	var res []ResolvedAd
+	for i, ad := range pod.Ads {
+		n := len(*errs)
+		resolved := r.resolveAd(ctx, ad, depth, rootID, chain, errs)
+		if len(resolved) == 0 && len(*errs) > n && ad.Wrapper != nil {
+			for len(resolved) == 0 {
+				fallback, ok := pod.Fallback(i)
+				if !ok {
+					break
+				}
+				resolved = r.resolveAd(ctx, fallback, depth, rootID, chain, errs)
+			}
+		}
+		res = append(res, resolved...)
+	}
+	return res
+}
+
+// resolveAd resolves a single ad, possibly into several ones when a wrapper
+// allows multiple ads.
+func (r *Resolver) resolveAd(ctx context.Context, ad Ad, depth int, rootID string, chain []*Wrapper, errs *ResolveErrors) []ResolvedAd {
+	if ad.InLine != nil {
+		return []ResolvedAd{{Ad: ad, Wrappers: chain}}
+	}
+	if depth == 0 {
+		rootID = ad.ID
+	}
+	w := ad.Wrapper
+	if w == nil {
+		*errs = append(*errs, &ResolveError{AdID: rootID, Depth: depth, Wrappers: chain, Err: ErrNoAdsAfterWrapper})
+		return nil
+	}
+
+	// copy the chain so that sibling ads don't share its backing array
+	next := make([]*Wrapper, len(chain)+1)
+	copy(next, chain)
+	next[len(chain)] = w
+
+	fail := func(uri string, err error) []ResolvedAd {
+		*errs = append(*errs, &ResolveError{AdID: rootID, URI: uri, Depth: depth, Wrappers: next, Err: err})
+		return nil
+	}
+	uri := strings.TrimSpace(w.VASTAdTagURI.CDATA)
+	if uri == "" {
+		return fail("", ErrMissingAdTagURI)
+	}
+	maxDepth := r.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxWrapperDepth
+	}
+	if depth >= maxDepth {
+		return fail(uri, ErrWrapperLimit)
+	}
+
+	doc, err := r.fetch(ctx, uri)
+	if err != nil {
+		return fail(uri, err)
+	}
+
+	ads := doc.Ads
+	if !boolDefault(w.AllowMultipleAds, false) {
+		ads = firstStandaloneAd(ads)
+	}
+	if !boolDefault(w.FollowAdditionalWrappers, true) {
+		var inlines []Ad
+		for _, a := range ads {
+			if a.Wrapper != nil {
+				*errs = append(*errs, &ResolveError{AdID: rootID, URI: uri, Depth: depth + 1, Wrappers: append(next[:len(next):len(next)], a.Wrapper), Err: ErrWrapperNotAllowed})
+				continue
+			}
+			inlines = append(inlines, a)
+		}
+		ads = inlines
+	}
+	if len(ads) == 0 {
+		return fail(uri, ErrNoAdsAfterWrapper)
+	}
+
+	n := len(*errs)
+	resolved := r.resolveAds(ctx, ads, depth+1, rootID, next, errs)
+	if len(resolved) == 0 {
+		if len(*errs) > n {
+			// the failure has already been reported deeper in the chain
+			return nil
+		}
+		return fail(uri, ErrNoAdsAfterWrapper)
+	}
+	for i := range resolved {
+		if ad.Sequence > 0 {
+			resolved[i].Ad.Sequence = ad.Sequence
+		}
+		mergeWrapper(resolved[i].Ad.InLine, w)
+	}
+	return resolved
+}
+
+func (r *Resolver) fetch(ctx context.Context, uri string) (*VAST, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	b, err := r.Fetcher.Fetch(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	var v VAST
+	if err := xml.Unmarshal(b, &v); err != nil {
+		return nil, &parseError{err}
+	}
+	return &v, nil
+}
+
+// parseError is returned when a document fetched by a Resolver can't be
+// decoded.
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *parseError) Unwrap() error {
+	return e.err
+}
+
+// ErrorCode implements the ErrorCoder interface.
+func (e *parseError) ErrorCode() ErrorCode {
+	return ErrorCodeXMLParsing
+}
+
+// firstStandaloneAd returns the first ad without sequence, as allowed by
+// wrappers whose allowMultipleAds attribute is false.
+func firstStandaloneAd(ads []Ad) []Ad {
+	for _, ad := range ads {
+		if ad.Sequence == 0 {
+			return []Ad{ad}
+		}
+	}
+	return nil
+}
+
+func boolDefault(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
+
+// mergeWrapper adds the trackers of w to the InLine ad in.
+func mergeWrapper(in *InLine, w *Wrapper) {
+	in.Impressions = append(in.Impressions, w.Impressions...)
+	in.Errors = append(in.Errors, w.Errors...)
+
+	if w.ViewableImpression != nil {
+		if in.ViewableImpression == nil {
+			in.ViewableImpression = &ViewableImpression{ID: w.ViewableImpression.ID}
+		}
+		vi := in.ViewableImpression
+		vi.Viewable = append(vi.Viewable, w.ViewableImpression.Viewable...)
+		vi.NotViewable = append(vi.NotViewable, w.ViewableImpression.NotViewable...)
+		vi.ViewUndetermined = append(vi.ViewUndetermined, w.ViewableImpression.ViewUndetermined...)
+	}
+
+	if w.AdVerifications != nil && len(w.AdVerifications.Verification) > 0 {
+		if in.AdVerifications == nil {
+			in.AdVerifications = &AdVerifications{}
+		}
+		in.AdVerifications.Verification = append(in.AdVerifications.Verification, w.AdVerifications.Verification...)
+	}
+
+	for _, wc := range w.Creatives {
+		for i := range in.Creatives {
+			c := &in.Creatives[i]
+			if wc.Linear != nil && c.Linear != nil {
+				mergeTracking(&c.Linear.TrackingEvents, wc.Linear.TrackingEvents)
+				if wc.Linear.VideoClicks != nil && len(wc.Linear.VideoClicks.ClickTrackings) > 0 {
+					if c.Linear.VideoClicks == nil {
+						c.Linear.VideoClicks = &VideoClicks{}
+					}
+					c.Linear.VideoClicks.ClickTrackings = append(c.Linear.VideoClicks.ClickTrackings, wc.Linear.VideoClicks.ClickTrackings...)
+				}
+			}
+			if wc.NonLinearAds != nil && c.NonLinearAds != nil {
+				mergeTracking(&c.NonLinearAds.TrackingEvents, wc.NonLinearAds.TrackingEvents)
+			}
+		}
+	}
+}
+
+func mergeTracking(dst **TrackingEvents, src *TrackingEvents) {
+	if src == nil || len(src.Tracking) == 0 {
+		return
+	}
+	if *dst == nil {
+		*dst = &TrackingEvents{}
+	}
+	(*dst).Tracking = append((*dst).Tracking, src.Tracking...)
+}
@@ -0,0 +1,219 @@
+package vast
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureFetcher serves testdata files keyed by URI.
+type fixtureFetcher map[string]string
+
+func (f fixtureFetcher) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	path, ok := f[uri]
+	if !ok {
+		return nil, fmt.Errorf("unknown uri %s", uri)
+	}
+	return ioutil.ReadFile(path)
+}
+
+var wrapperFixtures = fixtureFetcher{
+	"http://demo.tremormedia.com/proddev/vast/vast_inline_linear.xml":     "testdata/vast_inline_linear.xml",
+	"http://demo.tremormedia.com/proddev/vast/vast_inline_nonlinear2.xml": "testdata/vast_inline_nonlinear.xml",
+	"http://demo.tremormedia.com/proddev/vast/vast_inline_nonlinear3.xml": "testdata/vast_wrapper_nonlinear_1.xml",
+}
+
+func TestResolveInLine(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_inline_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ads, err := NewResolver(wrapperFixtures).Resolve(context.Background(), v)
+	assert.NoError(t, err)
+	if assert.Len(t, ads, 1) {
+		assert.Empty(t, ads[0].Wrappers)
+		assert.Equal(t, v.Ads[0].InLine, ads[0].Ad.InLine)
+	}
+}
+
+func TestResolveWrapperLinear(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ads, err := NewResolver(wrapperFixtures).Resolve(context.Background(), v)
+	if !assert.NoError(t, err) || !assert.Len(t, ads, 1) {
+		return
+	}
+	ad := ads[0]
+	assert.Equal(t, "601364", ad.Ad.ID)
+	assert.Nil(t, ad.Ad.Wrapper)
+	if assert.Len(t, ad.Wrappers, 1) {
+		assert.Equal(t, v.Ads[0].Wrapper, ad.Wrappers[0])
+	}
+	in := ad.Ad.InLine
+	if assert.Len(t, in.Impressions, 3) {
+		assert.Equal(t, "http://myTrackingURL/impression", in.Impressions[0].URI)
+		assert.Equal(t, "http://myTrackingURL/wrapper/impression", in.Impressions[2].URI)
+	}
+	if assert.Len(t, in.Errors, 3) {
+		assert.Equal(t, "http://myErrorURL/wrapper/error", in.Errors[2].CDATA)
+	}
+	linear := in.Creatives[0].Linear
+	if assert.NotNil(t, linear) {
+		assert.Len(t, linear.TrackingEvents.Tracking, 6+11)
+		if assert.Len(t, linear.VideoClicks.ClickTrackings, 2) {
+			assert.Equal(t, "http://myTrackingURL/wrapper/click", linear.VideoClicks.ClickTrackings[1].URI)
+		}
+	}
+
+	// the original document is left untouched
+	assert.Len(t, v.Ads[0].Wrapper.Impressions, 1)
+}
+
+func TestResolveWrapperChain(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_wrapper_nonlinear_2.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ads, err := NewResolver(wrapperFixtures).Resolve(context.Background(), v)
+	if !assert.NoError(t, err) || !assert.Len(t, ads, 1) {
+		return
+	}
+	ad := ads[0]
+	if assert.Len(t, ad.Wrappers, 2) {
+		assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/vast_inline_nonlinear3.xml", ad.Wrappers[0].VASTAdTagURI.CDATA)
+		assert.Equal(t, "http://demo.tremormedia.com/proddev/vast/vast_inline_nonlinear2.xml", ad.Wrappers[1].VASTAdTagURI.CDATA)
+	}
+	in := ad.Ad.InLine
+	assert.Len(t, in.Impressions, 3)
+	assert.Len(t, in.Errors, 2)
+	nonLinear := in.Creatives[0].NonLinearAds
+	if assert.NotNil(t, nonLinear) {
+		assert.Len(t, nonLinear.TrackingEvents.Tracking, 5+5)
+	}
+}
+
+func TestResolveWrapperLimit(t *testing.T) {
+	loop := fixtureFetcher{"http://demo.tremormedia.com/proddev/vast/vast_inline_linear.xml": "testdata/vast_wrapper_linear_1.xml"}
+	v, _, _, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	r := NewResolver(loop)
+	r.MaxDepth = 3
+	ads, err := r.Resolve(context.Background(), v)
+	assert.Empty(t, ads)
+	assert.True(t, errors.Is(err, ErrWrapperLimit))
+	var errs ResolveErrors
+	if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 1) {
+		assert.Equal(t, 3, errs[0].Depth)
+		assert.Len(t, errs[0].Wrappers, 4)
+	}
+}
+
+func TestResolveFollowAdditionalWrappers(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/vast_wrapper_nonlinear_2.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	no := false
+	v.Ads[0].Wrapper.FollowAdditionalWrappers = &no
+	ads, err := NewResolver(wrapperFixtures).Resolve(context.Background(), v)
+	assert.Empty(t, ads)
+	assert.True(t, errors.Is(err, ErrWrapperNotAllowed))
+}
+
+func TestResolveAllowMultipleAds(t *testing.T) {
+	pod := []byte(`<VAST version="3.0"><Ad id="1" sequence="1"><InLine><AdTitle>1</AdTitle></InLine></Ad><Ad id="2" sequence="2"><InLine><AdTitle>2</AdTitle></InLine></Ad><Ad id="3"><InLine><AdTitle>3</AdTitle></InLine></Ad></VAST>`)
+	f := FetcherFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		return pod, nil
+	})
+	yes, no := true, false
+	v := &VAST{Ads: []Ad{{ID: "w", Wrapper: &Wrapper{VASTAdTagURI: CDATAString{"http://pod"}, AllowMultipleAds: &yes}}}}
+	ads, err := NewResolver(f).Resolve(context.Background(), v)
+	assert.NoError(t, err)
+	if assert.Len(t, ads, 2) {
+		assert.Equal(t, "1", ads[0].Ad.ID)
+		assert.Equal(t, "2", ads[1].Ad.ID)
+	}
+
+	v.Ads[0].Wrapper.AllowMultipleAds = &no
+	ads, err = NewResolver(f).Resolve(context.Background(), v)
+	assert.NoError(t, err)
+	if assert.Len(t, ads, 1) {
+		assert.Equal(t, "3", ads[0].Ad.ID)
+	}
+}
+
+func TestResolveFallbackOnNoAd(t *testing.T) {
+	f := FetcherFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		return []byte(`<VAST version="3.0"></VAST>`), nil
+	})
+	yes, no := true, false
+	v := &VAST{Ads: []Ad{
+		{ID: "1", Sequence: 1, Wrapper: &Wrapper{VASTAdTagURI: CDATAString{"http://empty"}, FallbackOnNoAd: &yes}},
+		{ID: "2", Sequence: 2, Wrapper: &Wrapper{VASTAdTagURI: CDATAString{"http://empty"}, FallbackOnNoAd: &no}},
+		{ID: "3", Sequence: 3, InLine: &InLine{}},
+		{ID: "buffet", InLine: &InLine{}},
+	}}
+	ads, err := NewResolver(f).Resolve(context.Background(), v)
+	if assert.Len(t, ads, 2) {
+		assert.Equal(t, "buffet", ads[0].Ad.ID)
+		assert.Equal(t, 1, ads[0].Ad.Sequence)
+		assert.Equal(t, "3", ads[1].Ad.ID)
+	}
+	var errs ResolveErrors
+	if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 2) {
+		assert.Equal(t, "1", errs[0].AdID)
+		assert.Equal(t, "2", errs[1].AdID)
+	}
+}
+
+func TestResolveTimeout(t *testing.T) {
+	f := FetcherFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	v, _, _, err := loadFixture("testdata/vast_wrapper_linear_1.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	r := NewResolver(f)
+	r.Timeout = time.Millisecond
+	_, err = r.Resolve(context.Background(), v)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inline" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/vast_inline_linear.xml")
+	}))
+	defer ts.Close()
+
+	v := &VAST{Ads: []Ad{{ID: "w", Wrapper: &Wrapper{VASTAdTagURI: CDATAString{ts.URL + "/inline"}}}}}
+	ads, err := NewResolver(HTTPFetcher{}).Resolve(context.Background(), v)
+	assert.NoError(t, err)
+	assert.Len(t, ads, 1)
+
+	_, err = HTTPFetcher{}.Fetch(context.Background(), ts.URL+"/missing")
+	assert.Error(t, err)
+
+	var parsed VAST
+	b, err := HTTPFetcher{}.Fetch(context.Background(), ts.URL+"/inline")
+	if assert.NoError(t, err) {
+		assert.NoError(t, xml.Unmarshal(b, &parsed))
+	}
+}
@@ -0,0 +1,290 @@
+package vast
+
+import (
+	"sort"
+	"time"
+)
+
+// Clock tells the current time. It lets tests control time.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to allow the use of ordinary functions as a Clock.
+type ClockFunc func() time.Time
+
+// Now calls f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock returning the current local time.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// Beacon is a tracking URI to ping.
+type Beacon struct {
+	// The event the URI tracks.
+	Event string
+	// The offset of progress events.
+	Offset *Offset
+	// The URI, with its macros unexpanded.
+	URI string
+	// The user agent to request the URI with, from the ua attribute of the
+	// tracking event, if any.
+	UA string
+	// The playhead position when the beacon was emitted.
+	Playhead time.Duration
+	// The time at which the beacon was emitted.
+	Time time.Time
+}
+
+// URL returns the URI of the beacon with its macros expanded using c, the
+// [TIMESTAMP] and [ADPLAYHEAD] macros being set from the beacon.
+func (b Beacon) URL(c *MacroContext) string {
+	var bc MacroContext
+	if c != nil {
+		bc = *c
+	}
+	bc.Timestamp = b.Time
+	playhead := Duration(b.Playhead)
+	bc.AdPlayhead = &playhead
+	return bc.Expand(b.URI)
+}
+
+// trackerState is the playback state of a Tracker.
+type trackerState int
+
+const (
+	stateIdle trackerState = iota
+	statePlaying
+	statePaused
+	stateDone
+)
+
+// cue is a beacon emitted once the playhead reaches a position.
+type cue struct {
+	at     time.Duration
+	beacon Beacon
+	fired  bool
+}
+
+// Tracker tells which tracking URIs of a linear creative to ping as its
+// playback progresses.
+//
+// The player calls Start when the creative starts, Update with the playhead
+// position (or Tick with the clock) while it plays, and the methods matching
+// the user actions. Each of them returns the beacons to ping. Quartiles,
+// progress offsets, icon views and one-shot events are emitted once; pause,
+// resume, mute and unmute are emitted on each change of state.
+//
+// A Tracker isn't safe for concurrent use.
+type Tracker struct {
+	clock    Clock
+	duration time.Duration
+	events   map[string][]Tracking
+	cues     []cue
+
+	state    trackerState
+	muted    bool
+	playhead time.Duration
+	last     time.Time
+	fired    map[string]bool
+}
+
+// NewTracker returns a Tracker for the tracking events and icons of l. A nil
+// clock is the SystemClock.
+//
+// Percent offsets and quartiles are resolved against l.Duration; they are
+// never emitted when the duration is unknown.
+func NewTracker(l *Linear, clock Clock) *Tracker {
+	if clock == nil {
+		clock = SystemClock
+	}
+	t := &Tracker{
+		clock:    clock,
+		duration: time.Duration(l.Duration),
+		events:   map[string][]Tracking{},
+		fired:    map[string]bool{},
+	}
+	quartiles := map[string]float32{
+		EventTypeFirstQuartile: 0.25,
+		EventTypeMidpoint:      0.5,
+		EventTypeThirdQuartile: 0.75,
+	}
+	if l.TrackingEvents != nil {
+		for _, tr := range l.TrackingEvents.Tracking {
+			switch {
+			case tr.Event == EventTypeProgress && tr.Offset != nil:
+				t.addCue(*tr.Offset, Beacon{Event: tr.Event, Offset: tr.Offset, URI: tr.URI, UA: tr.UA})
+			case quartiles[tr.Event] > 0:
+				t.addCue(Offset{Percent: quartiles[tr.Event]}, Beacon{Event: tr.Event, URI: tr.URI, UA: tr.UA})
+			default:
+				t.events[tr.Event] = append(t.events[tr.Event], tr)
+			}
+		}
+	}
+	if l.Icons != nil && l.Icons.Icon != nil {
+		for _, icon := range *l.Icons.Icon {
+			for _, view := range icon.IconViewTracking {
+				t.addCue(icon.Offset, Beacon{Event: EventTypeIconView, URI: view.CDATA})
+			}
+		}
+	}
+	sort.SliceStable(t.cues, func(i, j int) bool {
+		return t.cues[i].at < t.cues[j].at
+	})
+	return t
+}
+
+// addCue schedules b at offset o, unless o can't be resolved.
+func (t *Tracker) addCue(o Offset, b Beacon) {
+	at, ok := o.Resolve(t.duration)
+	if !ok {
+		return
+	}
+	t.cues = append(t.cues, cue{at: at, beacon: b})
+}
+
+// Playhead returns the last known playhead position.
+func (t *Tracker) Playhead() time.Duration {
+	return t.playhead
+}
+
+// Start starts the playback, emitting creativeView, start and the beacons at
+// the beginning of the creative.
+func (t *Tracker) Start() []Beacon {
+	if t.state != stateIdle {
+		return nil
+	}
+	t.state = statePlaying
+	t.last = t.clock.Now()
+	var res []Beacon
+	res = t.once(res, EventTypeCreativeView)
+	res = t.once(res, EventTypeStart)
+	return t.advance(res)
+}
+
+// Update moves the playhead, emitting the beacons of the positions it
+// reached. Moving the playhead backward doesn't emit anything again.
+func (t *Tracker) Update(playhead time.Duration) []Beacon {
+	if t.state == stateIdle || t.state == stateDone {
+		return nil
+	}
+	t.playhead = playhead
+	t.last = t.clock.Now()
+	return t.advance(nil)
+}
+
+// Tick moves the playhead by the time elapsed since the previous call to
+// Start, Update, Tick or Resume, for players which don't report the playhead.
+// It doesn't do anything while paused.
+func (t *Tracker) Tick() []Beacon {
+	if t.state != statePlaying {
+		return nil
+	}
+	now := t.clock.Now()
+	if elapsed := now.Sub(t.last); elapsed > 0 {
+		t.playhead += elapsed
+	}
+	t.last = now
+	return t.advance(nil)
+}
+
+// Pause emits pause if the creative was playing.
+func (t *Tracker) Pause() []Beacon {
+	if t.state != statePlaying {
+		return nil
+	}
+	t.state = statePaused
+	return t.emit(nil, EventTypePause)
+}
+
+// Resume emits resume if the creative was paused.
+func (t *Tracker) Resume() []Beacon {
+	if t.state != statePaused {
+		return nil
+	}
+	t.state = statePlaying
+	t.last = t.clock.Now()
+	return t.emit(nil, EventTypeResume)
+}
+
+// Mute emits mute if the creative wasn't muted.
+func (t *Tracker) Mute() []Beacon {
+	if t.state == stateDone || t.muted {
+		return nil
+	}
+	t.muted = true
+	return t.emit(nil, EventTypeMute)
+}
+
+// Unmute emits unmute if the creative was muted.
+func (t *Tracker) Unmute() []Beacon {
+	if t.state == stateDone || !t.muted {
+		return nil
+	}
+	t.muted = false
+	return t.emit(nil, EventTypeUnmute)
+}
+
+// Skip emits skip and ends the playback.
+func (t *Tracker) Skip() []Beacon {
+	if t.state != statePlaying && t.state != statePaused {
+		return nil
+	}
+	t.state = stateDone
+	return t.once(nil, EventTypeSkip)
+}
+
+// Complete moves the playhead to the end of the creative, emitting the
+// beacons not emitted yet, then emits complete and ends the playback.
+func (t *Tracker) Complete() []Beacon {
+	if t.state != statePlaying && t.state != statePaused {
+		return nil
+	}
+	if t.duration > t.playhead {
+		t.playhead = t.duration
+	}
+	t.last = t.clock.Now()
+	res := t.advance(nil)
+	t.state = stateDone
+	return t.once(res, EventTypeComplete)
+}
+
+// advance appends the beacons of the cues reached by the playhead to res.
+func (t *Tracker) advance(res []Beacon) []Beacon {
+	for i := range t.cues {
+		c := &t.cues[i]
+		if c.at > t.playhead {
+			break
+		}
+		if !c.fired {
+			c.fired = true
+			res = append(res, t.beacon(c.beacon))
+		}
+	}
+	return res
+}
+
+// once appends the beacons of event to res, unless it was emitted before.
+func (t *Tracker) once(res []Beacon, event string) []Beacon {
+	if t.fired[event] {
+		return res
+	}
+	t.fired[event] = true
+	return t.emit(res, event)
+}
+
+// emit appends the beacons of event to res.
+func (t *Tracker) emit(res []Beacon, event string) []Beacon {
+	for _, tr := range t.events[event] {
+		res = append(res, t.beacon(Beacon{Event: event, Offset: tr.Offset, URI: tr.URI, UA: tr.UA}))
+	}
+	return res
+}
+
+func (t *Tracker) beacon(b Beacon) Beacon {
+	b.Playhead = t.playhead
+	b.Time = t.clock.Now()
+	return b
+}
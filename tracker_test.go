@@ -0,0 +1,124 @@
+package vast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func beaconEvents(bs []Beacon) []string {
+	events := make([]string, len(bs))
+	for i, b := range bs {
+		events[i] = b.Event
+		if b.Offset != nil {
+			offset, _ := b.Offset.MarshalText()
+			events[i] += "@" + string(offset)
+		}
+	}
+	return events
+}
+
+func testLinear() *Linear {
+	d5 := Duration(5 * time.Second)
+	icons := []Icon{
+		{Program: "AdChoices", Offset: Offset{Duration: &d5}, IconViewTracking: []CDATAString{{CDATA: "http://icon/view"}}},
+	}
+	return &Linear{
+		Duration: Duration(20 * time.Second),
+		Icons:    &Icons{Icon: &icons},
+		TrackingEvents: &TrackingEvents{Tracking: []Tracking{
+			{Event: EventTypeCreativeView, URI: "http://creativeView"},
+			{Event: EventTypeStart, URI: "http://start"},
+			{Event: EventTypeFirstQuartile, URI: "http://firstQuartile"},
+			{Event: EventTypeMidpoint, URI: "http://midpoint"},
+			{Event: EventTypeThirdQuartile, URI: "http://thirdQuartile"},
+			{Event: EventTypeComplete, URI: "http://complete"},
+			{Event: EventTypeProgress, Offset: &Offset{Duration: &d5}, URI: "http://progress/5s"},
+			{Event: EventTypeProgress, Offset: &Offset{Percent: 0.1}, URI: "http://progress/10"},
+			{Event: EventTypePause, URI: "http://pause"},
+			{Event: EventTypeResume, URI: "http://resume"},
+			{Event: EventTypeMute, URI: "http://mute"},
+			{Event: EventTypeUnmute, URI: "http://unmute"},
+			{Event: EventTypeSkip, URI: "http://skip?t=[ADPLAYHEAD]"},
+		}},
+	}
+}
+
+func TestTracker(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tr := NewTracker(testLinear(), clock)
+
+	assert.Empty(t, tr.Update(time.Second))
+	assert.Equal(t, []string{EventTypeCreativeView, EventTypeStart}, beaconEvents(tr.Start()))
+	assert.Empty(t, tr.Start())
+
+	clock.Advance(time.Second)
+	assert.Empty(t, tr.Update(time.Second))
+	bs := tr.Update(2 * time.Second)
+	assert.Equal(t, []string{"progress@10%"}, beaconEvents(bs))
+	assert.Equal(t, 2*time.Second, bs[0].Playhead)
+	assert.Equal(t, clock.now, bs[0].Time)
+
+	assert.Equal(t, []string{EventTypeFirstQuartile, "progress@00:00:05", EventTypeIconView}, beaconEvents(tr.Update(5*time.Second)))
+	assert.Empty(t, tr.Update(3*time.Second))
+	assert.Empty(t, tr.Update(5*time.Second))
+
+	assert.Equal(t, []string{EventTypeMute}, beaconEvents(tr.Mute()))
+	assert.Empty(t, tr.Mute())
+	assert.Equal(t, []string{EventTypeUnmute}, beaconEvents(tr.Unmute()))
+	assert.Equal(t, []string{EventTypeMute}, beaconEvents(tr.Mute()))
+
+	assert.Equal(t, []string{EventTypePause}, beaconEvents(tr.Pause()))
+	assert.Empty(t, tr.Pause())
+	clock.Advance(time.Minute)
+	assert.Empty(t, tr.Tick())
+	assert.Equal(t, []string{EventTypeResume}, beaconEvents(tr.Resume()))
+	assert.Empty(t, tr.Resume())
+
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, []string{EventTypeMidpoint}, beaconEvents(tr.Tick()))
+	assert.Equal(t, 10*time.Second, tr.Playhead())
+
+	assert.Equal(t, []string{EventTypeThirdQuartile, EventTypeComplete}, beaconEvents(tr.Complete()))
+	assert.Equal(t, 20*time.Second, tr.Playhead())
+	assert.Empty(t, tr.Complete())
+	assert.Empty(t, tr.Skip())
+	assert.Empty(t, tr.Update(30*time.Second))
+	assert.Empty(t, tr.Unmute())
+}
+
+func TestTrackerSkip(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tr := NewTracker(testLinear(), clock)
+	assert.Empty(t, tr.Skip())
+	tr.Start()
+	tr.Update(7 * time.Second)
+	bs := tr.Skip()
+	if assert.Len(t, bs, 1) {
+		assert.Equal(t, "http://skip?t=00%3A00%3A07.000", bs[0].URL(nil))
+	}
+	assert.Empty(t, tr.Complete())
+	assert.Empty(t, tr.Skip())
+}
+
+func TestTrackerUnknownDuration(t *testing.T) {
+	l := testLinear()
+	l.Duration = 0
+	tr := NewTracker(l, nil)
+	tr.Start()
+	assert.Equal(t, []string{"progress@00:00:05", EventTypeIconView}, beaconEvents(tr.Update(time.Hour)))
+	assert.Equal(t, []string{EventTypeComplete}, beaconEvents(tr.Complete()))
+}
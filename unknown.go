@@ -0,0 +1,109 @@
+package vast
+
+import (
+	"encoding/xml"
+	"strings"
+	"sync"
+)
+
+// UnknownAttrs are the attributes of an element not modelled by the
+// package, kept so that they are written back.
+//
+// The unknown attributes and elements are only kept on demand, by
+// DecodeWith and the Decoder: see DecodeOptions.KeepUnknown and
+// Decoder.KeepUnknown. xml.Unmarshal drops them, as it did before they could
+// be kept, and so do Decode and the Resolver.
+//
+// The namespace declarations aren't kept, as encoding/xml declares the
+// namespaces of the names it writes on its own.
+type UnknownAttrs []xml.Attr
+
+// UnmarshalXMLAttr implements the xml.UnmarshalerAttr interface.
+func (a *UnknownAttrs) UnmarshalXMLAttr(attr xml.Attr) error {
+	if attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+		return nil
+	}
+	*a = append(*a, attr)
+	return nil
+}
+
+// UnknownElement is an element not modelled by the package, kept in the
+// Unknown elements of the major elements so that it is written back.
+//
+// Its names are resolved against the namespaces declared in the document,
+// and written back with the declarations they need. The text of an element
+// with mixed content is gathered before its children, the white space
+// between children is dropped, as are the comments and processing
+// instructions.
+type UnknownElement struct {
+	XMLName  xml.Name
+	Attrs    UnknownAttrs     `xml:",any,attr"`
+	Text     string           `xml:",chardata"`
+	Children []UnknownElement `xml:",any"`
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (e *UnknownElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain UnknownElement
+	if err := d.DecodeElement((*plain)(e), &start); err != nil {
+		return err
+	}
+	if len(e.Children) > 0 && strings.TrimSpace(e.Text) == "" {
+		e.Text = ""
+	}
+	return nil
+}
+
+// keepingUnknown holds the decoders whose unknown content is kept, see
+// keepUnknown.
+var keepingUnknown sync.Map
+
+// keepUnknown makes the UnmarshalXML methods of the package keep the unknown
+// attributes and elements read from d, which they drop otherwise, until the
+// returned function is called.
+func keepUnknown(d *xml.Decoder) func() {
+	keepingUnknown.Store(d, true)
+	return func() { keepingUnknown.Delete(d) }
+}
+
+// keepsUnknown reports whether the unknown content read from d is kept.
+func keepsUnknown(d *xml.Decoder) bool {
+	_, ok := keepingUnknown.Load(d)
+	return ok
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (c *Companion) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Companion
+	if err := d.DecodeElement((*plain)(c), &start); err != nil {
+		return err
+	}
+	if !keepsUnknown(d) {
+		c.UnknownAttrs, c.Unknown = nil, nil
+	}
+	return nil
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (n *NonLinear) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain NonLinear
+	if err := d.DecodeElement((*plain)(n), &start); err != nil {
+		return err
+	}
+	if !keepsUnknown(d) {
+		n.UnknownAttrs, n.Unknown = nil, nil
+	}
+	return nil
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (i *Icon) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain Icon
+	if err := d.DecodeElement((*plain)(i), &start); err != nil {
+		return err
+	}
+	if !keepsUnknown(d) {
+		i.UnknownAttrs, i.Unknown = nil, nil
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package vast
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/llgoer/go-xml/xmltree"
+	"github.com/stretchr/testify/assert"
+)
+
+// loadUnknown decodes the document at path keeping its unknown content.
+func loadUnknown(path string) (*VAST, []byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, _, err := DecodeWith(bytes.NewReader(b), DecodeOptions{Mode: Lenient, KeepUnknown: true})
+	return v, b, err
+}
+
+func TestUnknownRoundTrip(t *testing.T) {
+	for _, path := range append(codecFixtures(t), "testdata/vendor_extended.xml") {
+		v, b, err := loadUnknown(path)
+		if err != nil {
+			// not a document the package decodes
+			continue
+		}
+		out, err := xml.Marshal(v)
+		if !assert.NoError(t, err, path) {
+			continue
+		}
+		expected, err := xmltree.Parse(b)
+		assert.NoError(t, err, path)
+		actual, err := xmltree.Parse(out)
+		assert.NoError(t, err, path)
+		if path == "testdata/vendor_extended.xml" {
+			// xmltree.Equal only checks that the attributes of its second
+			// argument are kept
+			assert.True(t, xmltree.Equal(expected, actual), path)
+			assert.True(t, xmltree.Equal(actual, expected), path)
+		}
+
+		// the unknown elements are stable
+		w, _, err := DecodeWith(bytes.NewReader(out), DecodeOptions{Mode: Strict, KeepUnknown: true})
+		if assert.NoError(t, err, path) {
+			assert.Empty(t, Diff(v, w), path)
+		}
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	v, _, err := loadUnknown("testdata/vendor_extended.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	const acme = "http://acme.example/vast"
+	in := v.Ads[0].InLine
+	assert.Equal(t, UnknownAttrs{{Name: xml.Name{Space: acme, Local: "region"}, Value: "eu"}}, in.UnknownAttrs)
+	if assert.Len(t, in.Unknown, 2) {
+		deal := in.Unknown[0]
+		assert.Equal(t, xml.Name{Space: acme, Local: "Deal"}, deal.XMLName)
+		assert.Equal(t, UnknownAttrs{
+			{Name: xml.Name{Local: "id"}, Value: "d-1"},
+			{Name: xml.Name{Local: "floor"}, Value: "2.50"},
+		}, deal.Attrs)
+		if assert.Len(t, deal.Children, 2) {
+			assert.Equal(t, "42", deal.Children[0].Text)
+			assert.Equal(t, "43", deal.Children[1].Text)
+		}
+		assert.Equal(t, xml.Name{Space: "http://www.iab.com/VAST", Local: "Rating"}, in.Unknown[1].XMLName)
+	}
+
+	linear := in.Creatives[0].Linear
+	// the modelled attributes aren't captured
+	mf := linear.MediaFiles.MediaFile[0]
+	assert.Equal(t, 1280, mf.Width)
+	assert.Equal(t, UnknownAttrs{
+		{Name: xml.Name{Space: acme, Local: "hdr"}, Value: "false"},
+		{Name: xml.Name{Local: "quality"}, Value: "high"},
+	}, mf.UnknownAttrs)
+	assert.Equal(t, "https://example.com/video-1280x720.mp4", strings.TrimSpace(mf.URI))
+	chapter := linear.Unknown[0].Children[0]
+	assert.Equal(t, "Intro & ", chapter.Text)
+	assert.Equal(t, "brand", chapter.Children[0].Text)
+
+	assert.Equal(t, "sidebar", in.Creatives[1].CompanionAds.Companions[0].Unknown[0].Text)
+	assert.Equal(t, "slide", in.Creatives[2].NonLinearAds.NonLinears[0].Unknown[0].Text)
+	assert.Equal(t, "Why this ad?", (*linear.Icons.Icon)[0].Unknown[0].Text)
+	assert.Equal(t, "a.example", v.Ads[1].Wrapper.Unknown[0].Children[0].Text)
+
+	// the unknown elements aren't part of the JSON encoding
+	b, err := json.Marshal(v.Ads[1].Wrapper)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "Unknown")
+}
+
+// dropUnknown clears the unknown content of v, as decoded by default.
+func dropUnknown(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			dropUnknown(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			dropUnknown(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			switch f := v.Field(i); {
+			case v.Type().Field(i).PkgPath != "":
+			case f.Type() == reflect.TypeOf(UnknownAttrs{}), f.Type() == reflect.TypeOf([]UnknownElement{}):
+				f.Set(reflect.Zero(f.Type()))
+			default:
+				dropUnknown(f)
+			}
+		}
+	}
+}
+
+func TestUnknownOptIn(t *testing.T) {
+	for _, path := range append(codecFixtures(t), "testdata/vendor_extended.xml") {
+		all, b, err := loadUnknown(path)
+		if err != nil {
+			continue
+		}
+
+		// the unknown content is dropped by default
+		want := *all
+		dropUnknown(reflect.ValueOf(&want))
+		v, _, err := Decode(bytes.NewReader(b), Lenient)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, &want, v, path)
+		}
+		var w VAST
+		if assert.NoError(t, xml.Unmarshal(b, &w), path) {
+			assert.Equal(t, &want, &w, path)
+		}
+	}
+
+	v, _, err := Decode(strings.NewReader(`<VAST><Ad><Wrapper x="1" y="2"><a:b xmlns:a="a"><Impression/></a:b><Impression>i</Impression></Wrapper></Ad></VAST>`), Strict)
+	if assert.NoError(t, err) {
+		assert.Nil(t, v.Ads[0].Wrapper.UnknownAttrs)
+		assert.Nil(t, v.Ads[0].Wrapper.Unknown)
+		assert.Equal(t, []Impression{{URI: "i"}}, v.Ads[0].Wrapper.Impressions)
+	}
+}
+
+func TestUnknownUnmarshal(t *testing.T) {
+	// xml.Unmarshal drops the unknown content, and xml.Marshal writes what
+	// it did before the unknown content could be kept
+	b, err := ioutil.ReadFile("testdata/vendor_extended.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	want, err := ioutil.ReadFile("testdata/vendor_extended.golden.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var v VAST
+	if !assert.NoError(t, xml.Unmarshal(b, &v)) {
+		return
+	}
+	out, err := xml.MarshalIndent(&v, "", "  ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(want), string(out)+"\n")
+	}
+
+	// Convert keeps it
+	kept, _, err := loadUnknown("testdata/vendor_extended.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	res, _, err := Convert(kept, Version42)
+	if assert.NoError(t, err) {
+		assert.Equal(t, kept.Ads[0].InLine.Unknown, res.Ads[0].InLine.Unknown)
+	}
+}
+
+func TestUnknownDecoder(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/vendor_extended.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	all, _, err := DecodeWith(bytes.NewReader(b), DecodeOptions{Mode: Strict, KeepUnknown: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	d := NewDecoder(bytes.NewReader(b))
+	d.KeepUnknown = true
+	assert.Equal(t, all.Ads, decodeAds(t, d))
+
+	dropUnknown(reflect.ValueOf(all))
+	assert.Equal(t, all.Ads, decodeAds(t, NewDecoder(bytes.NewReader(b))))
+
+	// the unknown elements count in the depth limit
+	d = NewDecoder(strings.NewReader(`<VAST><Ad><InLine><a><b><c/></b></a></InLine></Ad></VAST>`))
+	d.MaxDepth = 5
+	_, err = d.Next()
+	assert.Equal(t, ErrDocumentTooDeep, err)
+}
+
+func decodeAds(t *testing.T, d *Decoder) []Ad {
+	var ads []Ad
+	for {
+		ad, err := d.Next()
+		if err == io.EOF {
+			return ads
+		}
+		if !assert.NoError(t, err) {
+			return ads
+		}
+		ads = append(ads, *ad)
+	}
+}
+
+func TestUnknownDiff(t *testing.T) {
+	a, _, err := loadUnknown("testdata/vendor_extended.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, _, _ := loadUnknown("testdata/vendor_extended.xml")
+	b.Ads[1].Wrapper.Unknown[0].XMLName.Local = "Chains"
+	assert.Equal(t, []Change{
+		{Kind: ChangeModified, Path: `Ads["20002"].Wrapper.Unknown[0].XMLName.Local`, From: "Chain", To: "Chains"},
+	}, Diff(a, b))
+}
+
+func TestUnknownNormalize(t *testing.T) {
+	v, _, err := loadUnknown("testdata/vendor_extended.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	Normalize(v)
+	chapter := v.Ads[0].InLine.Creatives[0].Linear.Unknown[0].Children[0]
+	assert.Equal(t, "Intro & ", chapter.Text)
+}
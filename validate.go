@@ -0,0 +1,612 @@
+package vast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity is the severity of a Diagnostic.
+type Severity int
+
+const (
+	// SeverityError reports a violation of a rule of the spec.
+	SeverityError Severity = iota
+	// SeverityWarning reports a construct which is allowed but likely to cause
+	// issues, such as an element unknown to the targeted version.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Diagnostic is a problem found by Validate.
+type Diagnostic struct {
+	Severity Severity
+	// Path locates the offending element using the Go field names, e.g.
+	// "Ads[0].InLine.Creatives[1].Linear.MediaFiles".
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// Error implements the error interface.
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.Path, d.Message)
+}
+
+// ErrorCode implements the ErrorCoder interface: every diagnostic is a schema
+// validation error.
+func (d Diagnostic) ErrorCode() ErrorCode {
+	return ErrorCodeSchemaValidation
+}
+
+// ValidationErrors is returned when a document fails validation. It holds
+// the diagnostics of severity SeverityError.
+type ValidationErrors []Diagnostic
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, d := range e {
+		msgs[i] = d.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ErrorCode implements the ErrorCoder interface.
+func (e ValidationErrors) ErrorCode() ErrorCode {
+	return ErrorCodeSchemaValidation
+}
+
+// Versions supported by Validate.
+const (
+	Version2  = "2.0"
+	Version3  = "3.0"
+	Version4  = "4.0"
+	Version41 = "4.1"
+	Version42 = "4.2"
+)
+
+// versionNumbers orders the supported versions.
+var versionNumbers = map[string]int{
+	Version2:  20,
+	Version3:  30,
+	Version4:  40,
+	Version41: 41,
+	Version42: 42,
+}
+
+// Validate checks v against the required elements and cardinality rules of
+// the given VAST version, one of "2.0", "3.0", "4.0", "4.1" or "4.2". When
+// version is empty, the version of the document is used.
+func Validate(v *VAST, version string) []Diagnostic {
+	if version == "" {
+		version = v.Version
+	}
+	val := &validator{}
+	n, ok := versionNumbers[version]
+	if !ok {
+		val.errorf(pathOf("Version"), "unsupported VAST version %q", version)
+		return val.diags
+	}
+	val.version = n
+	val.validate(v)
+	return val.diags
+}
+
+// HasErrors reports whether diags contains at least one SeverityError.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// elemPath locates an element in a document. It is only formatted when a
+// diagnostic is reported so that validating a valid document doesn't
+// allocate.
+type elemPath struct {
+	parent  *elemPath
+	name    string
+	i       int
+	indexed bool
+}
+
+func pathOf(name string) elemPath {
+	return elemPath{name: name}
+}
+
+// field returns the path of the field name of the element at p.
+func (p *elemPath) field(name string) elemPath {
+	return elemPath{parent: p, name: name}
+}
+
+// at returns the path of the i-th element of the field name of the element
+// at p.
+func (p *elemPath) at(name string, i int) elemPath {
+	return elemPath{parent: p, name: name, i: i, indexed: true}
+}
+
+// index returns the path of the i-th element of the list at p.
+func (p elemPath) index(i int) elemPath {
+	p.i = i
+	p.indexed = true
+	return p
+}
+
+func (p *elemPath) String() string {
+	return string(p.appendTo(nil))
+}
+
+func (p *elemPath) appendTo(b []byte) []byte {
+	if p.parent != nil {
+		b = append(p.parent.appendTo(b), '.')
+	}
+	b = append(b, p.name...)
+	if p.indexed {
+		b = append(b, '[')
+		b = strconv.AppendInt(b, int64(p.i), 10)
+		b = append(b, ']')
+	}
+	return b
+}
+
+type validator struct {
+	version int
+	diags   []Diagnostic
+}
+
+func (val *validator) errorf(path elemPath, format string, args ...interface{}) {
+	val.diags = append(val.diags, Diagnostic{Severity: SeverityError, Path: path.String(), Message: fmt.Sprintf(format, args...)})
+}
+
+func (val *validator) warnf(path elemPath, format string, args ...interface{}) {
+	val.diags = append(val.diags, Diagnostic{Severity: SeverityWarning, Path: path.String(), Message: fmt.Sprintf(format, args...)})
+}
+
+// introduced maps the elements and attributes which VAST 2.0 doesn't define
+// to the version which introduced them, for Validate and Convert. They are
+// named after their Go type and field, or after their type alone when it
+// has several parents.
+var introduced = map[string]int{
+	"Ad.Sequence":                        30,
+	"Ad.ConditionalAd":                   40,
+	"Ad.AdType":                          41,
+	"InLine.AdServingId":                 41,
+	"InLine.Advertiser":                  30,
+	"InLine.Category":                    40,
+	"InLine.Expires":                     41,
+	"Wrapper.FollowAdditionalWrappers":   30,
+	"Wrapper.AllowMultipleAds":           30,
+	"Wrapper.FallbackOnNoAd":             30,
+	"Pricing":                            30,
+	"ViewableImpression":                 40,
+	"AdVerifications":                    41,
+	"Creative.UniversalAdID":             40,
+	"Creative.CreativeExtensions":        30,
+	"Linear.SkipOffset":                  30,
+	"Icons":                              30,
+	"MediaFile.FileSize":                 41,
+	"MediaFile.MediaType":                41,
+	"MediaFiles.Mezzanine":               40,
+	"MediaFiles.InteractiveCreativeFile": 40,
+	"MediaFiles.ClosedCaptionFiles":      41,
+	"Companion.RenderingMode":            41,
+	// the progress tracking event
+	"Tracking.progress": 30,
+}
+
+// since reports an element introduced in a later version than the one
+// validated against, named as in the introduced table.
+func (val *validator) since(path elemPath, name string) {
+	if version := introduced[name]; val.version < version {
+		val.warnf(path, "not defined before VAST %d.%d", version/10, version%10)
+	}
+}
+
+// required reports a missing required element or attribute.
+func (val *validator) required(path elemPath, present bool) {
+	if !present {
+		val.errorf(path, "required")
+	}
+}
+
+func blank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+func (val *validator) validate(v *VAST) {
+	if v.Version == "" {
+		val.warnf(pathOf("Version"), "missing version attribute")
+	} else if _, ok := versionNumbers[v.Version]; ok && versionNumbers[v.Version] != val.version {
+		val.warnf(pathOf("Version"), "document version %q validated as VAST %d.%d", v.Version, val.version/10, val.version%10)
+	}
+	if len(v.Ads) == 0 && len(v.Errors) == 0 {
+		val.warnf(pathOf("Ads"), "no Ad nor Error element")
+	}
+	for i, ad := range v.Ads {
+		val.validateAd(pathOf("Ads").index(i), &ad)
+	}
+}
+
+func (val *validator) validateAd(path elemPath, ad *Ad) {
+	switch {
+	case ad.InLine != nil && ad.Wrapper != nil:
+		val.errorf(path, "contains both an InLine and a Wrapper element")
+	case ad.InLine == nil && ad.Wrapper == nil:
+		val.errorf(path, "contains neither an InLine nor a Wrapper element")
+	}
+	if ad.Sequence != 0 {
+		val.since(path.field("Sequence"), "Ad.Sequence")
+		if ad.Sequence < 0 {
+			val.errorf(path.field("Sequence"), "must be greater than zero")
+		}
+	}
+	if ad.ConditionalAd {
+		val.since(path.field("ConditionalAd"), "Ad.ConditionalAd")
+		if val.version >= 41 {
+			val.warnf(path.field("ConditionalAd"), "deprecated since VAST 4.1")
+		}
+	}
+	if ad.AdType != "" {
+		val.since(path.field("AdType"), "Ad.AdType")
+		switch ad.AdType {
+		case "video", "audio", "hybrid":
+		default:
+			val.errorf(path.field("AdType"), "invalid value %q", ad.AdType)
+		}
+	}
+	if ad.InLine != nil {
+		val.validateInLine(path.field("InLine"), ad.InLine)
+	}
+	if ad.Wrapper != nil {
+		val.validateWrapper(path.field("Wrapper"), ad.Wrapper)
+	}
+}
+
+func (val *validator) validateInLine(path elemPath, in *InLine) {
+	val.required(path.field("AdSystem"), in.AdSystem != nil && !blank(in.AdSystem.Name))
+	val.required(path.field("AdTitle"), !blank(in.AdTitle.CDATA))
+	val.validateImpressions(path.field("Impressions"), in.Impressions)
+	if val.version >= 41 {
+		val.required(path.field("AdServingId"), !blank(in.AdServingId))
+	} else if in.AdServingId != "" {
+		val.since(path.field("AdServingId"), "InLine.AdServingId")
+	}
+	if in.Pricing != nil {
+		val.validatePricing(path.field("Pricing"), in.Pricing)
+	}
+	if in.Advertiser != nil {
+		val.since(path.field("Advertiser"), "InLine.Advertiser")
+	}
+	if in.Category != nil {
+		val.since(path.field("Category"), "InLine.Category")
+		for i, c := range *in.Category {
+			epath := path.at("Category", i)
+			val.required(epath.field("Authority"), !blank(c.Authority))
+		}
+	}
+	if in.Expires != nil {
+		val.since(path.field("Expires"), "InLine.Expires")
+		if *in.Expires < 0 {
+			val.errorf(path.field("Expires"), "must not be negative")
+		}
+	}
+	if in.ViewableImpression != nil {
+		val.since(path.field("ViewableImpression"), "ViewableImpression")
+	}
+	if in.AdVerifications != nil {
+		val.validateAdVerifications(path.field("AdVerifications"), in.AdVerifications)
+	}
+	if in.Extensions != nil {
+		val.validateExtensions(path.field("Extensions"), *in.Extensions)
+	}
+	if len(in.Creatives) == 0 {
+		val.errorf(path.field("Creatives"), "at least one Creative is required")
+	}
+	for i := range in.Creatives {
+		val.validateCreative(path.at("Creatives", i), &in.Creatives[i])
+	}
+}
+
+func (val *validator) validateWrapper(path elemPath, w *Wrapper) {
+	val.required(path.field("AdSystem"), w.AdSystem != nil && !blank(w.AdSystem.Name))
+	val.required(path.field("VASTAdTagURI"), !blank(w.VASTAdTagURI.CDATA))
+	val.validateImpressions(path.field("Impressions"), w.Impressions)
+	if w.FollowAdditionalWrappers != nil {
+		val.since(path.field("FollowAdditionalWrappers"), "Wrapper.FollowAdditionalWrappers")
+	}
+	if w.AllowMultipleAds != nil {
+		val.since(path.field("AllowMultipleAds"), "Wrapper.AllowMultipleAds")
+	}
+	if w.FallbackOnNoAd != nil {
+		val.since(path.field("FallbackOnNoAd"), "Wrapper.FallbackOnNoAd")
+	}
+	if w.Pricing != nil {
+		val.validatePricing(path.field("Pricing"), w.Pricing)
+	}
+	if w.ViewableImpression != nil {
+		val.since(path.field("ViewableImpression"), "ViewableImpression")
+	}
+	if w.AdVerifications != nil {
+		val.validateAdVerifications(path.field("AdVerifications"), w.AdVerifications)
+	}
+	if w.Extensions != nil {
+		val.validateExtensions(path.field("Extensions"), *w.Extensions)
+	}
+	for i, c := range w.Creatives {
+		cpath := path.at("Creatives", i)
+		if c.Linear != nil {
+			lpath := cpath.field("Linear")
+			val.validateTrackingEvents(lpath.field("TrackingEvents"), c.Linear.TrackingEvents)
+			if c.Linear.Icons != nil {
+				val.validateIcons(lpath.field("Icons"), c.Linear.Icons)
+			}
+		}
+		if c.CompanionAds != nil {
+			val.validateCompanionAds(cpath.field("CompanionAds"), c.CompanionAds)
+		}
+		if c.NonLinearAds != nil {
+			npath := cpath.field("NonLinearAds")
+			val.validateTrackingEvents(npath.field("TrackingEvents"), c.NonLinearAds.TrackingEvents)
+		}
+	}
+}
+
+func (val *validator) validateImpressions(path elemPath, imps []Impression) {
+	if len(imps) == 0 {
+		val.errorf(path, "at least one Impression is required")
+	}
+	for i, imp := range imps {
+		if blank(imp.URI) {
+			val.warnf(path.index(i), "empty URI")
+		}
+	}
+}
+
+func (val *validator) validatePricing(path elemPath, p *Pricing) {
+	val.since(path, "Pricing")
+	switch strings.ToLower(p.Model) {
+	case "":
+		val.errorf(path.field("Model"), "required")
+	case "cpm", "cpc", "cpe", "cpv":
+	default:
+		val.errorf(path.field("Model"), "invalid value %q", p.Model)
+	}
+	if len(p.Currency) != 3 {
+		val.errorf(path.field("Currency"), "must be a 3 letter ISO-4217 code")
+	}
+	val.required(path.field("Value"), !blank(p.Value))
+}
+
+func (val *validator) validateAdVerifications(path elemPath, av *AdVerifications) {
+	val.since(path, "AdVerifications")
+	for i, v := range av.Verification {
+		vpath := path.at("Verification", i)
+		if len(v.JavaScriptResource) == 0 && len(v.ExecutableResource) == 0 {
+			val.warnf(vpath, "no JavaScriptResource nor ExecutableResource")
+		}
+		for j, r := range v.JavaScriptResource {
+			epath := vpath.at("JavaScriptResource", j)
+			val.required(epath.field("URI"), !blank(r.URI))
+		}
+		for j, r := range v.ExecutableResource {
+			epath := vpath.at("ExecutableResource", j)
+			val.required(epath.field("URI"), !blank(r.URI))
+		}
+		val.validateTrackingEvents(vpath.field("TrackingEvents"), v.TrackingEvents)
+	}
+}
+
+func (val *validator) validateExtensions(path elemPath, exts []Extension) {
+	if len(exts) == 0 {
+		val.warnf(path, "empty Extensions element")
+	}
+}
+
+func (val *validator) validateCreative(path elemPath, c *Creative) {
+	n := 0
+	if c.Linear != nil {
+		n++
+		val.validateLinear(path.field("Linear"), c.Linear)
+	}
+	if c.CompanionAds != nil {
+		n++
+		val.validateCompanionAds(path.field("CompanionAds"), c.CompanionAds)
+	}
+	if c.NonLinearAds != nil {
+		n++
+		val.validateNonLinearAds(path.field("NonLinearAds"), c.NonLinearAds)
+	}
+	switch {
+	case n == 0:
+		val.errorf(path, "one of Linear, CompanionAds or NonLinearAds is required")
+	case n > 1:
+		val.errorf(path, "only one of Linear, CompanionAds or NonLinearAds is allowed")
+	}
+	if val.version >= 40 {
+		if c.UniversalAdID == nil || len(*c.UniversalAdID) == 0 {
+			val.errorf(path.field("UniversalAdID"), "required")
+		}
+	} else if c.UniversalAdID != nil {
+		val.since(path.field("UniversalAdID"), "Creative.UniversalAdID")
+	}
+	if c.UniversalAdID != nil {
+		for i, id := range *c.UniversalAdID {
+			epath := path.at("UniversalAdID", i)
+			val.required(epath.field("IDRegistry"), !blank(id.IDRegistry))
+		}
+	}
+	if c.CreativeExtensions != nil {
+		val.since(path.field("CreativeExtensions"), "Creative.CreativeExtensions")
+	}
+}
+
+func (val *validator) validateLinear(path elemPath, l *Linear) {
+	if l.SkipOffset != nil {
+		val.since(path.field("SkipOffset"), "Linear.SkipOffset")
+	}
+	if l.Duration == 0 {
+		val.warnf(path.field("Duration"), "missing or zero duration")
+	}
+	if l.Icons != nil {
+		val.validateIcons(path.field("Icons"), l.Icons)
+	}
+	val.validateTrackingEvents(path.field("TrackingEvents"), l.TrackingEvents)
+	if l.MediaFiles == nil || len(l.MediaFiles.MediaFile) == 0 {
+		val.errorf(path.field("MediaFiles"), "at least one MediaFile is required")
+		return
+	}
+	mpath := path.field("MediaFiles")
+	for i := range l.MediaFiles.MediaFile {
+		val.validateMediaFile(mpath.at("MediaFile", i), &l.MediaFiles.MediaFile[i])
+	}
+	for i, m := range l.MediaFiles.Mezzanine {
+		ppath := mpath.at("Mezzanine", i)
+		val.since(ppath, "MediaFiles.Mezzanine")
+		val.validateDelivery(ppath.field("Delivery"), m.Delivery)
+		val.required(ppath.field("Type"), !blank(m.Type))
+		val.required(ppath.field("Width"), m.Width > 0)
+		val.required(ppath.field("Height"), m.Height > 0)
+		val.required(ppath.field("URI"), !blank(m.URI))
+	}
+	for i, f := range l.MediaFiles.InteractiveCreativeFile {
+		ppath := mpath.at("InteractiveCreativeFile", i)
+		val.since(ppath, "MediaFiles.InteractiveCreativeFile")
+		val.required(ppath.field("URI"), !blank(f.URI))
+	}
+	if l.MediaFiles.ClosedCaptionFiles != nil {
+		val.since(mpath.field("ClosedCaptionFiles"), "MediaFiles.ClosedCaptionFiles")
+	}
+}
+
+func (val *validator) validateDelivery(path elemPath, delivery string) {
+	switch delivery {
+	case "progressive", "streaming":
+	case "":
+		val.errorf(path, "required")
+	default:
+		val.errorf(path, "must be either progressive or streaming, got %q", delivery)
+	}
+}
+
+func (val *validator) validateMediaFile(path elemPath, m *MediaFile) {
+	val.validateDelivery(path.field("Delivery"), m.Delivery)
+	val.required(path.field("Type"), !blank(m.Type))
+	val.required(path.field("Width"), m.Width > 0)
+	val.required(path.field("Height"), m.Height > 0)
+	val.required(path.field("URI"), !blank(m.URI))
+	if (m.MinBitrate != 0) != (m.MaxBitrate != 0) {
+		val.warnf(path, "MinBitrate and MaxBitrate should be provided together")
+	}
+	if m.MinBitrate > m.MaxBitrate && m.MaxBitrate != 0 {
+		val.errorf(path.field("MinBitrate"), "greater than MaxBitrate")
+	}
+	if m.FileSize != 0 {
+		val.since(path.field("FileSize"), "MediaFile.FileSize")
+	}
+	if m.MediaType != "" {
+		val.since(path.field("MediaType"), "MediaFile.MediaType")
+	}
+}
+
+func (val *validator) validateTrackingEvents(path elemPath, te *TrackingEvents) {
+	if te == nil {
+		return
+	}
+	for i, t := range te.Tracking {
+		tpath := path.at("Tracking", i)
+		val.required(tpath.field("Event"), !blank(t.Event))
+		if t.Event == EventTypeProgress {
+			val.since(tpath, "Tracking.progress")
+			val.required(tpath.field("Offset"), t.Offset != nil)
+		}
+		if blank(t.URI) {
+			val.warnf(tpath, "empty URI")
+		}
+	}
+}
+
+func (val *validator) validateIcons(path elemPath, icons *Icons) {
+	val.since(path, "Icons")
+	if icons.Icon == nil {
+		return
+	}
+	for i, icon := range *icons.Icon {
+		ipath := path.at("Icon", i)
+		if icon.StaticResource == nil && icon.IFrameResource == nil && icon.HTMLResource == nil {
+			val.errorf(ipath, "one of StaticResource, IFrameResource or HTMLResource is required")
+		}
+		if icon.Width == 0 || icon.Height == 0 {
+			val.warnf(ipath, "missing width or height")
+		}
+		val.validatePosition(ipath.field("XPosition"), icon.XPosition, "left", "right")
+		val.validatePosition(ipath.field("YPosition"), icon.YPosition, "top", "bottom")
+	}
+}
+
+func (val *validator) validatePosition(path elemPath, pos string, keywords ...string) {
+	if pos == "" {
+		return
+	}
+	for _, k := range keywords {
+		if pos == k {
+			return
+		}
+	}
+	for i := 0; i < len(pos); i++ {
+		if pos[i] < '0' || pos[i] > '9' {
+			val.errorf(path, "must be a number of pixels or one of %s, got %q", strings.Join(keywords, ", "), pos)
+			return
+		}
+	}
+}
+
+func (val *validator) validateCompanionAds(path elemPath, ca *CompanionAds) {
+	switch ca.Required {
+	case "":
+	case "all", "any", "none":
+	default:
+		val.errorf(path.field("Required"), "must be one of all, any or none, got %q", ca.Required)
+	}
+	for i, c := range ca.Companions {
+		cpath := path.at("Companions", i)
+		if c.StaticResource == nil && c.IFrameResource == nil && c.HTMLResource == nil {
+			val.errorf(cpath, "one of StaticResource, IFrameResource or HTMLResource is required")
+		}
+		if c.Width == 0 || c.Height == 0 {
+			val.errorf(cpath, "width and height are required")
+		}
+		if c.RenderingMode != "" {
+			val.since(cpath.field("RenderingMode"), "Companion.RenderingMode")
+			switch c.RenderingMode {
+			case "default", "end-card", "concurrent":
+			default:
+				val.errorf(cpath.field("RenderingMode"), "invalid value %q", c.RenderingMode)
+			}
+		}
+		val.validateTrackingEvents(cpath.field("TrackingEvents"), c.TrackingEvents)
+	}
+}
+
+func (val *validator) validateNonLinearAds(path elemPath, nla *NonLinearAds) {
+	val.validateTrackingEvents(path.field("TrackingEvents"), nla.TrackingEvents)
+	for i, nl := range nla.NonLinears {
+		npath := path.at("NonLinears", i)
+		if nl.StaticResource == nil && nl.IFrameResource == nil && nl.HTMLResource == nil {
+			val.errorf(npath, "one of StaticResource, IFrameResource or HTMLResource is required")
+		}
+		if nl.Width == 0 || nl.Height == 0 {
+			val.errorf(npath, "width and height are required")
+		}
+	}
+}
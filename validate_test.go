@@ -0,0 +1,140 @@
+package vast
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTestdata(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	samples, err := filepath.Glob("testdata/iab/vast_4.2_samples/*.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// fixtures trimmed down to exercise a single feature
+	partial := map[string]bool{
+		"testdata/creative_extensions.xml":                   true,
+		"testdata/inline_extensions.xml":                     true,
+		"testdata/extraspaces_vpaid.xml":                     true,
+		"testdata/spotx_vpaid.xml":                           true,
+		"testdata/vast_inline_linear-duration_undefined.xml": true,
+	}
+	for _, file := range append(files, samples...) {
+		t.Run(file, func(t *testing.T) {
+			v, _, _, err := loadFixture(file)
+			if !assert.NoError(t, err) {
+				return
+			}
+			diags := Validate(v, "")
+			if partial[file] {
+				assert.True(t, HasErrors(diags))
+			} else {
+				assert.False(t, HasErrors(diags), "%v", diags)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := &VAST{
+		Version: "4.2",
+		Ads: []Ad{
+			{InLine: &InLine{}, Wrapper: &Wrapper{}},
+			{},
+			{
+				InLine: &InLine{
+					AdSystem:    &AdSystem{Name: "DSP"},
+					AdTitle:     PlainString{CDATA: "title"},
+					AdServingId: "id",
+					Impressions: []Impression{{URI: "http://impression"}},
+					Pricing:     &Pricing{Currency: "US", Value: "1"},
+					Creatives: []Creative{
+						{UniversalAdID: &[]UniversalAdID{{IDRegistry: "Ad-ID", ID: "1"}}, Linear: &Linear{Duration: Duration(1)}},
+						{
+							UniversalAdID: &[]UniversalAdID{{ID: "1"}},
+							Linear: &Linear{
+								Duration: Duration(1),
+								TrackingEvents: &TrackingEvents{Tracking: []Tracking{
+									{Event: EventTypeProgress, URI: "http://progress"},
+								}},
+								MediaFiles: &MediaFiles{MediaFile: []MediaFile{
+									{Delivery: "download", Type: "video/mp4", Width: 1, Height: 1, URI: "http://media"},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	diags := Validate(v, "4.2")
+	paths := map[string]Severity{}
+	for _, d := range diags {
+		paths[d.Path] = d.Severity
+	}
+	for _, p := range []string{
+		"Ads[0]",
+		"Ads[0].InLine.AdSystem",
+		"Ads[0].InLine.AdTitle",
+		"Ads[0].InLine.Impressions",
+		"Ads[0].InLine.AdServingId",
+		"Ads[0].InLine.Creatives",
+		"Ads[0].Wrapper.AdSystem",
+		"Ads[0].Wrapper.VASTAdTagURI",
+		"Ads[0].Wrapper.Impressions",
+		"Ads[1]",
+		"Ads[2].InLine.Pricing.Model",
+		"Ads[2].InLine.Pricing.Currency",
+		"Ads[2].InLine.Creatives[0].Linear.MediaFiles",
+		"Ads[2].InLine.Creatives[1].UniversalAdID[0].IDRegistry",
+		"Ads[2].InLine.Creatives[1].Linear.TrackingEvents.Tracking[0].Offset",
+		"Ads[2].InLine.Creatives[1].Linear.MediaFiles.MediaFile[0].Delivery",
+	} {
+		if assert.Contains(t, paths, p) {
+			assert.Equal(t, SeverityError, paths[p], p)
+		}
+	}
+	assert.Len(t, paths, 16)
+	assert.Equal(t, ErrorCodeSchemaValidation, ErrorCodeFor(diags[0]))
+}
+
+func TestValidateVersion(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/iab/vast_4.2_samples/Inline_Linear_Tag-test.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	diags := Validate(v, "2.0")
+	assert.False(t, HasErrors(diags))
+	var paths []string
+	for _, d := range diags {
+		paths = append(paths, d.Path)
+	}
+	assert.Contains(t, paths, "Version")
+	assert.Contains(t, paths, "Ads[0].Sequence")
+	assert.Contains(t, paths, "Ads[0].InLine.Pricing")
+	assert.Contains(t, paths, "Ads[0].InLine.AdServingId")
+
+	// Advertiser is defined by VAST 3.0, conditionalAd only by VAST 4.0
+	v = &VAST{Version: Version3, Ads: []Ad{{ConditionalAd: true, InLine: &InLine{Advertiser: &Advertiser{Advertiser: "brand"}}}}}
+	paths = nil
+	for _, d := range Validate(v, Version3) {
+		paths = append(paths, d.Path)
+	}
+	assert.Contains(t, paths, "Ads[0].ConditionalAd")
+	assert.NotContains(t, paths, "Ads[0].InLine.Advertiser")
+	paths = nil
+	for _, d := range Validate(v, Version2) {
+		paths = append(paths, d.Path)
+	}
+	assert.Contains(t, paths, "Ads[0].InLine.Advertiser")
+
+	diags = Validate(v, "1.0")
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "error: Version: unsupported VAST version \"1.0\"", diags[0].Error())
+	}
+}
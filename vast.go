@@ -123,6 +123,10 @@ type InLine struct {
 	// The <AdVerifications> element is used to contain one or more <Verification> elements,
 	// which are used to initiate a controlled container where code can be executed for collecting data to verify ad playback details.
 	AdVerifications *AdVerifications `xml:"AdVerifications,omitempty" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // Impression is a URI that directs the video player to a tracking resource file that
@@ -189,6 +193,10 @@ type Wrapper struct {
 	FallbackOnNoAd           *bool `xml:"fallbackOnNoAd,attr,omitempty" json:",omitempty"`
 	AllowMultipleAds         *bool `xml:"allowMultipleAds,attr,omitempty" json:",omitempty"`
 	FollowAdditionalWrappers *bool `xml:"followAdditionalWrappers,attr,omitempty" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // AdSystem contains information about the system that returned the ad
@@ -227,6 +235,10 @@ type Creative struct {
 	// The nested <CreativeExtension> includes an attribute for type, which
 	// specifies the MIME type needed to execute the extension.
 	CreativeExtensions *[]Extension `xml:"CreativeExtensions>CreativeExtension,omitempty" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // <CompanionAds> contains companions creatives
@@ -291,6 +303,10 @@ type Linear struct {
 	AdParameters   *AdParameters   `xml:",omitempty" json:",omitempty"`
 	VideoClicks    *VideoClicks    `xml:",omitempty" json:",omitempty"`
 	MediaFiles     *MediaFiles     `xml:"MediaFiles" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // <LinearWrapper> defines a wrapped linear creative
@@ -340,6 +356,10 @@ type Companion struct {
 	HTMLResource  *HTMLResource `xml:",omitempty" json:",omitempty"`
 	Pxratio       string        `xml:"pxratio,attr,omitempty" json:",omitempty"`
 	RenderingMode string        `xml:"renderingMode,attr,omitempty" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // <NonLinear> defines a non linear ad
@@ -375,6 +395,10 @@ type NonLinear struct {
 	NonLinearClickThrough *CDATAString `xml:",omitempty" json:",omitempty"`
 	// URLs to ping when user clicks on the the non-linear ad.
 	NonLinearClickTrackings []NonLinearClickTracking `xml:"NonLinearClickTracking,omitempty" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // <NonLinearWrapper> defines a non linear ad in a wrapper
@@ -455,6 +479,10 @@ type Icon struct {
 	IFrameResource *CDATAString `xml:",omitempty" json:",omitempty"`
 	// HTML to display the companion element
 	HTMLResource *HTMLResource `xml:",omitempty" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // <Tracking> defines an event tracking URL
@@ -558,6 +586,10 @@ type MediaFile struct {
 	// Type of media file (2D / 3D / 360 / etc). Optional.
 	// Default value = 2D
 	MediaType string `xml:"mediaType,attr,omitempty" json:",omitempty"`
+	// The attributes and child elements not modelled by the package, only
+	// kept on demand, see UnknownAttrs.
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
 }
 
 // <UniversalAdID> describes a VAST 4.x universal ad id.
@@ -1,5 +1,7 @@
 package vast
 
+import "strings"
+
 // The ad server may provide URIs for tracking publisher-determined view-ability
 type ViewableImpression struct {
 	// An ad server id for the impression.
@@ -73,9 +75,9 @@ type JavaScriptResource struct {
 type ExecutableResource struct {
 	// Identifies the API needed to execute the resource file if applicable.
 	ApiFramework string `xml:"apiFramework,attr,omitempty"`
-	// Identifies the MIME type of the file provided.
-	Type bool `xml:"type,attr,omitempty"`
-	// A CDATA-wrapped URI to a file providing Closed Caption info for the media file.
+	// Identifies the MIME type of the file provided, e.g. "application/javascript".
+	Type string `xml:"type,attr,omitempty"`
+	// A CDATA-wrapped URI to the executable file.
 	URI string `xml:",cdata"`
 }
 
@@ -108,17 +110,41 @@ type Mezzanine struct {
 type InteractiveCreativeFile struct {
 	// Identifies the API needed to execute the resource file if applicable.
 	ApiFramework string `xml:"apiFramework,attr,omitempty"`
-	// Identifies the MIME type of the file provided.
-	Type bool `xml:"type,attr,omitempty"`
+	// Identifies the MIME type of the file provided, e.g. "text/html" for SIMID.
+	Type string `xml:"type,attr,omitempty"`
 	// Useful for interactive use cases.
 	// Identifies whether the ad always drops when the duration is reached,
 	// or if it can potentially extend the duration by pausing the underlying video or delaying the adStopped call after adVideoComplete.
 	// If it set to true the extension of the duration should be user-initiated (typically by engaging with an interactive element to view additional content).
 	VariableDuration bool `xml:"variableDuration,attr,omitempty"`
-	// A CDATA-wrapped URI to a file providing Closed Caption info for the media file.
+	// A CDATA-wrapped URI to the interactive creative file.
 	URI string `xml:",cdata"`
 }
 
+// APIFrameworkSIMID is the apiFramework of Secure Interactive Media Interface
+// Definition (SIMID) interactive creative files.
+const APIFrameworkSIMID = "SIMID"
+
+// IsSIMID reports whether the file is a SIMID creative.
+func (f InteractiveCreativeFile) IsSIMID() bool {
+	return strings.EqualFold(strings.TrimSpace(f.ApiFramework), APIFrameworkSIMID)
+}
+
+// VariableDuration reports whether an interactive creative file may extend
+// the duration of the ad beyond the Duration of its Linear, in which case
+// the player must wait for the interactive creative to end the ad.
+func (m *MediaFiles) VariableDuration() bool {
+	if m == nil {
+		return false
+	}
+	for _, f := range m.InteractiveCreativeFile {
+		if f.VariableDuration {
+			return true
+		}
+	}
+	return false
+}
+
 type ClosedCaptionFiles struct {
 	ClosedCaptionFile []ClosedCaptionFile `xml:"ClosedCaptionFile,omitempty" json:",omitempty"`
 }
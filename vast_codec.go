@@ -0,0 +1,3831 @@
+// Code generated by codecgen. DO NOT EDIT.
+
+package vast
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *VAST) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "version"}, Value: v.Version})
+	if v.XMLNS != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: v.XMLNS})
+	}
+	if v.Mute {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "mute"}, Value: strconv.FormatBool(v.Mute)})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := range v.Ads {
+		if err := v.Ads[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Ad"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.Errors {
+		if err := v.Errors[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Error"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *VAST) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "version":
+			v.Version = a.Value
+		case "xmlns":
+			v.XMLNS = a.Value
+		case "mute":
+			if err := parseBool(&v.Mute, a.Value); err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "Ad":
+				v.Ads = append(v.Ads, Ad{})
+				if err := v.Ads[len(v.Ads)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Error":
+				v.Errors = append(v.Errors, CDATAString{})
+				if err := v.Errors[len(v.Errors)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *VAST) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *VAST) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	if v.Version != "" {
+		b = append(b, `"Version":`...)
+		b = appendJSONString(b, v.Version)
+		b = append(b, ',')
+	}
+	if v.XMLNS != "" {
+		b = append(b, `"xmlns":`...)
+		b = appendJSONString(b, v.XMLNS)
+		b = append(b, ',')
+	}
+	if len(v.Ads) != 0 {
+		b = append(b, `"Ad":`...)
+		b = append(b, '[')
+		for i := range v.Ads {
+			if b, err = v.Ads[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	if len(v.Errors) != 0 {
+		b = append(b, `"Errors":`...)
+		b = append(b, '[')
+		for i := range v.Errors {
+			if b, err = v.Errors[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	if v.Mute {
+		b = append(b, `"Mute":`...)
+		b = strconv.AppendBool(b, v.Mute)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *VAST) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsVAST = []string{"Version", "xmlns", "Ad", "Errors", "Mute"}
+
+func (v *VAST) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsVAST) {
+		case 0:
+			if err := r.decodeString(&v.Version); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeString(&v.XMLNS); err != nil {
+				return err
+			}
+		case 2:
+			if r.null() {
+				v.Ads = nil
+				break
+			}
+			if ok, err := r.array(&v.Ads); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Ads) {
+					if i < cap(v.Ads) {
+						v.Ads = v.Ads[:i+1]
+					} else {
+						v.Ads = append(v.Ads, Ad{})
+					}
+				}
+				if err := v.Ads[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Ads = []Ad{}
+			} else {
+				v.Ads = v.Ads[:i]
+			}
+		case 3:
+			if r.null() {
+				v.Errors = nil
+				break
+			}
+			if ok, err := r.array(&v.Errors); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Errors) {
+					if i < cap(v.Errors) {
+						v.Errors = v.Errors[:i+1]
+					} else {
+						v.Errors = append(v.Errors, CDATAString{})
+					}
+				}
+				if err := v.Errors[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Errors = []CDATAString{}
+			} else {
+				v.Errors = v.Errors[:i]
+			}
+		case 4:
+			if err := r.decodeBool(&v.Mute); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*VAST) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *Ad) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: v.ID})
+	}
+	if v.AdType != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "adType"}, Value: v.AdType})
+	}
+	if v.Type != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: v.Type})
+	}
+	if v.Sequence != 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "sequence"}, Value: strconv.Itoa(v.Sequence)})
+	}
+	if v.ConditionalAd {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "conditionalAd"}, Value: strconv.FormatBool(v.ConditionalAd)})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.InLine != nil {
+		if err := v.InLine.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "InLine"}}); err != nil {
+			return err
+		}
+	}
+	if v.Wrapper != nil {
+		if err := v.Wrapper.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Wrapper"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *Ad) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			v.ID = a.Value
+		case "adType":
+			v.AdType = a.Value
+		case "type":
+			v.Type = a.Value
+		case "sequence":
+			if err := parseInt(&v.Sequence, a.Value); err != nil {
+				return err
+			}
+		case "conditionalAd":
+			if err := parseBool(&v.ConditionalAd, a.Value); err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "InLine":
+				if v.InLine == nil {
+					v.InLine = new(InLine)
+				}
+				if err := v.InLine.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Wrapper":
+				if v.Wrapper == nil {
+					v.Wrapper = new(Wrapper)
+				}
+				if err := v.Wrapper.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *Ad) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *Ad) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	if v.InLine != nil {
+		b = append(b, `"InLine":`...)
+		if b, err = v.InLine.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.Wrapper != nil {
+		b = append(b, `"Wrapper":`...)
+		if b, err = v.Wrapper.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.ID != "" {
+		b = append(b, `"ID":`...)
+		b = appendJSONString(b, v.ID)
+		b = append(b, ',')
+	}
+	if v.AdType != "" {
+		b = append(b, `"AdType":`...)
+		b = appendJSONString(b, v.AdType)
+		b = append(b, ',')
+	}
+	if v.Type != "" {
+		b = append(b, `"Type":`...)
+		b = appendJSONString(b, v.Type)
+		b = append(b, ',')
+	}
+	if v.Sequence != 0 {
+		b = append(b, `"Sequence":`...)
+		b = strconv.AppendInt(b, int64(v.Sequence), 10)
+		b = append(b, ',')
+	}
+	if v.ConditionalAd {
+		b = append(b, `"ConditionalAd":`...)
+		b = strconv.AppendBool(b, v.ConditionalAd)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *Ad) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsAd = []string{"InLine", "Wrapper", "ID", "AdType", "Type", "Sequence", "ConditionalAd"}
+
+func (v *Ad) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsAd) {
+		case 0:
+			if r.null() {
+				v.InLine = nil
+				break
+			}
+			if v.InLine == nil {
+				v.InLine = new(InLine)
+			}
+			if err := v.InLine.decodeJSON(r); err != nil {
+				return err
+			}
+		case 1:
+			if r.null() {
+				v.Wrapper = nil
+				break
+			}
+			if v.Wrapper == nil {
+				v.Wrapper = new(Wrapper)
+			}
+			if err := v.Wrapper.decodeJSON(r); err != nil {
+				return err
+			}
+		case 2:
+			if err := r.decodeString(&v.ID); err != nil {
+				return err
+			}
+		case 3:
+			if err := r.decodeString(&v.AdType); err != nil {
+				return err
+			}
+		case 4:
+			if err := r.decodeString(&v.Type); err != nil {
+				return err
+			}
+		case 5:
+			if err := r.decodeInt(&v.Sequence); err != nil {
+				return err
+			}
+		case 6:
+			if err := r.decodeBool(&v.ConditionalAd); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*Ad) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *InLine) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, v.UnknownAttrs...)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.AdSystem != nil {
+		if err := v.AdSystem.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "AdSystem"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.Errors {
+		if err := v.Errors[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Error"}}); err != nil {
+			return err
+		}
+	}
+	if v.Extensions != nil {
+		parent := xml.StartElement{Name: xml.Name{Local: "Extensions"}}
+		if err := e.EncodeToken(parent); err != nil {
+			return err
+		}
+		for i := range *v.Extensions {
+			if err := e.EncodeElement(&(*v.Extensions)[i], xml.StartElement{Name: xml.Name{Local: "Extension"}}); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(parent.End()); err != nil {
+			return err
+		}
+	}
+	for i := range v.Impressions {
+		if err := v.Impressions[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Impression"}}); err != nil {
+			return err
+		}
+	}
+	if v.Pricing != nil {
+		if err := e.EncodeElement(v.Pricing, xml.StartElement{Name: xml.Name{Local: "Pricing"}}); err != nil {
+			return err
+		}
+	}
+	if v.AdServingId != "" {
+		if err := encodeText(e, xml.StartElement{Name: xml.Name{Local: "AdServingId"}}, v.AdServingId); err != nil {
+			return err
+		}
+	}
+	if err := v.AdTitle.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "AdTitle"}}); err != nil {
+		return err
+	}
+	if v.Advertiser != nil {
+		if err := e.EncodeElement(v.Advertiser, xml.StartElement{Name: xml.Name{Local: "Advertiser"}}); err != nil {
+			return err
+		}
+	}
+	if v.Category != nil {
+		for i := range *v.Category {
+			if err := e.EncodeElement(&(*v.Category)[i], xml.StartElement{Name: xml.Name{Local: "Category"}}); err != nil {
+				return err
+			}
+		}
+	}
+	parent := xml.StartElement{Name: xml.Name{Local: "Creatives"}}
+	if err := e.EncodeToken(parent); err != nil {
+		return err
+	}
+	for i := range v.Creatives {
+		if err := v.Creatives[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Creative"}}); err != nil {
+			return err
+		}
+	}
+	if err := e.EncodeToken(parent.End()); err != nil {
+		return err
+	}
+	if v.Description != nil {
+		if err := v.Description.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Description"}}); err != nil {
+			return err
+		}
+	}
+	if v.Survey != nil {
+		if err := e.EncodeElement(v.Survey, xml.StartElement{Name: xml.Name{Local: "Survey"}}); err != nil {
+			return err
+		}
+	}
+	if v.Expires != nil {
+		if err := encodeText(e, xml.StartElement{Name: xml.Name{Local: "Expires"}}, strconv.Itoa(*v.Expires)); err != nil {
+			return err
+		}
+	}
+	if v.ViewableImpression != nil {
+		if err := e.EncodeElement(v.ViewableImpression, xml.StartElement{Name: xml.Name{Local: "ViewableImpression"}}); err != nil {
+			return err
+		}
+	}
+	if v.AdVerifications != nil {
+		if err := e.EncodeElement(v.AdVerifications, xml.StartElement{Name: xml.Name{Local: "AdVerifications"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.Unknown {
+		if err := e.Encode(&v.Unknown[i]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *InLine) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if keepsUnknown(d) {
+		for _, a := range start.Attr {
+			if err := v.UnknownAttrs.UnmarshalXMLAttr(a); err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "AdSystem":
+				if v.AdSystem == nil {
+					v.AdSystem = new(AdSystem)
+				}
+				if err := v.AdSystem.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Error":
+				v.Errors = append(v.Errors, CDATAString{})
+				if err := v.Errors[len(v.Errors)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Extensions":
+				for {
+					tok, err := d.Token()
+					if err != nil {
+						return err
+					}
+					if _, ok := tok.(xml.EndElement); ok {
+						break
+					}
+					t, ok := tok.(xml.StartElement)
+					if !ok {
+						continue
+					}
+					if t.Name.Local != "Extension" {
+						if err := d.Skip(); err != nil {
+							return err
+						}
+						continue
+					}
+					if v.Extensions == nil {
+						v.Extensions = new([]Extension)
+					}
+					*v.Extensions = append(*v.Extensions, Extension{})
+					if err := d.DecodeElement(&(*v.Extensions)[len(*v.Extensions)-1], &t); err != nil {
+						return err
+					}
+				}
+			case "Impression":
+				v.Impressions = append(v.Impressions, Impression{})
+				if err := v.Impressions[len(v.Impressions)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Pricing":
+				if v.Pricing == nil {
+					v.Pricing = new(Pricing)
+				}
+				if err := d.DecodeElement(v.Pricing, &t); err != nil {
+					return err
+				}
+			case "AdServingId":
+				b, err := decodeText(d)
+				if err != nil {
+					return err
+				}
+				v.AdServingId = string(b)
+			case "AdTitle":
+				if err := v.AdTitle.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Advertiser":
+				if v.Advertiser == nil {
+					v.Advertiser = new(Advertiser)
+				}
+				if err := d.DecodeElement(v.Advertiser, &t); err != nil {
+					return err
+				}
+			case "Category":
+				if v.Category == nil {
+					v.Category = new([]Category)
+				}
+				*v.Category = append(*v.Category, Category{})
+				if err := d.DecodeElement(&(*v.Category)[len(*v.Category)-1], &t); err != nil {
+					return err
+				}
+			case "Creatives":
+				for {
+					tok, err := d.Token()
+					if err != nil {
+						return err
+					}
+					if _, ok := tok.(xml.EndElement); ok {
+						break
+					}
+					t, ok := tok.(xml.StartElement)
+					if !ok {
+						continue
+					}
+					if t.Name.Local != "Creative" {
+						if err := d.Skip(); err != nil {
+							return err
+						}
+						continue
+					}
+					v.Creatives = append(v.Creatives, Creative{})
+					if err := v.Creatives[len(v.Creatives)-1].UnmarshalXML(d, t); err != nil {
+						return err
+					}
+				}
+			case "Description":
+				if v.Description == nil {
+					v.Description = new(CDATAString)
+				}
+				if err := v.Description.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Survey":
+				if v.Survey == nil {
+					v.Survey = new(Survey)
+				}
+				if err := d.DecodeElement(v.Survey, &t); err != nil {
+					return err
+				}
+			case "Expires":
+				b, err := decodeText(d)
+				if err != nil {
+					return err
+				}
+				if v.Expires == nil {
+					v.Expires = new(int)
+				}
+				if err := parseInt(v.Expires, string(b)); err != nil {
+					return err
+				}
+			case "ViewableImpression":
+				if v.ViewableImpression == nil {
+					v.ViewableImpression = new(ViewableImpression)
+				}
+				if err := d.DecodeElement(v.ViewableImpression, &t); err != nil {
+					return err
+				}
+			case "AdVerifications":
+				if v.AdVerifications == nil {
+					v.AdVerifications = new(AdVerifications)
+				}
+				if err := d.DecodeElement(v.AdVerifications, &t); err != nil {
+					return err
+				}
+			default:
+				if !keepsUnknown(d) {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				v.Unknown = append(v.Unknown, UnknownElement{})
+				if err := d.DecodeElement(&v.Unknown[len(v.Unknown)-1], &t); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *InLine) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *InLine) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	{
+		b = append(b, `"AdSystem":`...)
+		if v.AdSystem == nil {
+			b = append(b, "null"...)
+		} else {
+			if b, err = v.AdSystem.appendJSON(b); err != nil {
+				return nil, err
+			}
+		}
+		b = append(b, ',')
+	}
+	if len(v.Errors) != 0 {
+		b = append(b, `"Error":`...)
+		b = append(b, '[')
+		for i := range v.Errors {
+			if b, err = v.Errors[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	if v.Extensions != nil {
+		b = append(b, `"Extensions":`...)
+		if b, err = appendJSON(b, &v.Extensions); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Impressions":`...)
+		if v.Impressions == nil {
+			b = append(b, "null"...)
+		} else {
+			b = append(b, '[')
+			for i := range v.Impressions {
+				if b, err = v.Impressions[i].appendJSON(b); err != nil {
+					return nil, err
+				}
+				b = append(b, ',')
+			}
+			b = closeJSON(b, ']')
+		}
+		b = append(b, ',')
+	}
+	if v.Pricing != nil {
+		b = append(b, `"Pricing":`...)
+		if b, err = appendJSON(b, &v.Pricing); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.AdServingId != "" {
+		b = append(b, `"AdServingId":`...)
+		b = appendJSONString(b, v.AdServingId)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"AdTitle":`...)
+		if b, err = v.AdTitle.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.Advertiser != nil {
+		b = append(b, `"Advertiser":`...)
+		if b, err = appendJSON(b, &v.Advertiser); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.Category != nil {
+		b = append(b, `"Category":`...)
+		if b, err = appendJSON(b, &v.Category); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Creatives":`...)
+		if v.Creatives == nil {
+			b = append(b, "null"...)
+		} else {
+			b = append(b, '[')
+			for i := range v.Creatives {
+				if b, err = v.Creatives[i].appendJSON(b); err != nil {
+					return nil, err
+				}
+				b = append(b, ',')
+			}
+			b = closeJSON(b, ']')
+		}
+		b = append(b, ',')
+	}
+	if v.Description != nil {
+		b = append(b, `"Description":`...)
+		if b, err = v.Description.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.Survey != nil {
+		b = append(b, `"Survey":`...)
+		if b, err = appendJSON(b, &v.Survey); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.Expires != nil {
+		b = append(b, `"Expires":`...)
+		b = strconv.AppendInt(b, int64(*v.Expires), 10)
+		b = append(b, ',')
+	}
+	if v.ViewableImpression != nil {
+		b = append(b, `"ViewableImpression":`...)
+		if b, err = appendJSON(b, &v.ViewableImpression); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.AdVerifications != nil {
+		b = append(b, `"AdVerifications":`...)
+		if b, err = appendJSON(b, &v.AdVerifications); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *InLine) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsInLine = []string{"AdSystem", "Error", "Extensions", "Impressions", "Pricing", "AdServingId", "AdTitle", "Advertiser", "Category", "Creatives", "Description", "Survey", "Expires", "ViewableImpression", "AdVerifications"}
+
+func (v *InLine) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsInLine) {
+		case 0:
+			if r.null() {
+				v.AdSystem = nil
+				break
+			}
+			if v.AdSystem == nil {
+				v.AdSystem = new(AdSystem)
+			}
+			if err := v.AdSystem.decodeJSON(r); err != nil {
+				return err
+			}
+		case 1:
+			if r.null() {
+				v.Errors = nil
+				break
+			}
+			if ok, err := r.array(&v.Errors); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Errors) {
+					if i < cap(v.Errors) {
+						v.Errors = v.Errors[:i+1]
+					} else {
+						v.Errors = append(v.Errors, CDATAString{})
+					}
+				}
+				if err := v.Errors[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Errors = []CDATAString{}
+			} else {
+				v.Errors = v.Errors[:i]
+			}
+		case 2:
+			if err := r.unmarshal(&v.Extensions); err != nil {
+				return err
+			}
+		case 3:
+			if r.null() {
+				v.Impressions = nil
+				break
+			}
+			if ok, err := r.array(&v.Impressions); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Impressions) {
+					if i < cap(v.Impressions) {
+						v.Impressions = v.Impressions[:i+1]
+					} else {
+						v.Impressions = append(v.Impressions, Impression{})
+					}
+				}
+				if err := v.Impressions[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Impressions = []Impression{}
+			} else {
+				v.Impressions = v.Impressions[:i]
+			}
+		case 4:
+			if err := r.unmarshal(&v.Pricing); err != nil {
+				return err
+			}
+		case 5:
+			if err := r.decodeString(&v.AdServingId); err != nil {
+				return err
+			}
+		case 6:
+			if err := v.AdTitle.decodeJSON(r); err != nil {
+				return err
+			}
+		case 7:
+			if err := r.unmarshal(&v.Advertiser); err != nil {
+				return err
+			}
+		case 8:
+			if err := r.unmarshal(&v.Category); err != nil {
+				return err
+			}
+		case 9:
+			if r.null() {
+				v.Creatives = nil
+				break
+			}
+			if ok, err := r.array(&v.Creatives); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Creatives) {
+					if i < cap(v.Creatives) {
+						v.Creatives = v.Creatives[:i+1]
+					} else {
+						v.Creatives = append(v.Creatives, Creative{})
+					}
+				}
+				if err := v.Creatives[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Creatives = []Creative{}
+			} else {
+				v.Creatives = v.Creatives[:i]
+			}
+		case 10:
+			if r.null() {
+				v.Description = nil
+				break
+			}
+			if v.Description == nil {
+				v.Description = new(CDATAString)
+			}
+			if err := v.Description.decodeJSON(r); err != nil {
+				return err
+			}
+		case 11:
+			if err := r.unmarshal(&v.Survey); err != nil {
+				return err
+			}
+		case 12:
+			if r.null() {
+				v.Expires = nil
+				break
+			}
+			if v.Expires == nil {
+				v.Expires = new(int)
+			}
+			if err := r.decodeInt(v.Expires); err != nil {
+				return err
+			}
+		case 13:
+			if err := r.unmarshal(&v.ViewableImpression); err != nil {
+				return err
+			}
+		case 14:
+			if err := r.unmarshal(&v.AdVerifications); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*InLine) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *Wrapper) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.FallbackOnNoAd != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "fallbackOnNoAd"}, Value: strconv.FormatBool(*v.FallbackOnNoAd)})
+	}
+	if v.AllowMultipleAds != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "allowMultipleAds"}, Value: strconv.FormatBool(*v.AllowMultipleAds)})
+	}
+	if v.FollowAdditionalWrappers != nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "followAdditionalWrappers"}, Value: strconv.FormatBool(*v.FollowAdditionalWrappers)})
+	}
+	start.Attr = append(start.Attr, v.UnknownAttrs...)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.AdSystem != nil {
+		if err := v.AdSystem.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "AdSystem"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.Errors {
+		if err := v.Errors[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Error"}}); err != nil {
+			return err
+		}
+	}
+	if v.Extensions != nil {
+		parent := xml.StartElement{Name: xml.Name{Local: "Extensions"}}
+		if err := e.EncodeToken(parent); err != nil {
+			return err
+		}
+		for i := range *v.Extensions {
+			if err := e.EncodeElement(&(*v.Extensions)[i], xml.StartElement{Name: xml.Name{Local: "Extension"}}); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(parent.End()); err != nil {
+			return err
+		}
+	}
+	for i := range v.Impressions {
+		if err := v.Impressions[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Impression"}}); err != nil {
+			return err
+		}
+	}
+	parent := xml.StartElement{Name: xml.Name{Local: "Creatives"}}
+	if err := e.EncodeToken(parent); err != nil {
+		return err
+	}
+	for i := range v.Creatives {
+		if err := e.EncodeElement(&v.Creatives[i], xml.StartElement{Name: xml.Name{Local: "Creative"}}); err != nil {
+			return err
+		}
+	}
+	if err := e.EncodeToken(parent.End()); err != nil {
+		return err
+	}
+	if err := v.VASTAdTagURI.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "VASTAdTagURI"}}); err != nil {
+		return err
+	}
+	if v.Pricing != nil {
+		if err := e.EncodeElement(v.Pricing, xml.StartElement{Name: xml.Name{Local: "Pricing"}}); err != nil {
+			return err
+		}
+	}
+	if v.ViewableImpression != nil {
+		if err := e.EncodeElement(v.ViewableImpression, xml.StartElement{Name: xml.Name{Local: "ViewableImpression"}}); err != nil {
+			return err
+		}
+	}
+	if v.AdVerifications != nil {
+		if err := e.EncodeElement(v.AdVerifications, xml.StartElement{Name: xml.Name{Local: "AdVerifications"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.Unknown {
+		if err := e.Encode(&v.Unknown[i]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *Wrapper) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "fallbackOnNoAd":
+			if v.FallbackOnNoAd == nil {
+				v.FallbackOnNoAd = new(bool)
+			}
+			if err := parseBool(v.FallbackOnNoAd, a.Value); err != nil {
+				return err
+			}
+		case "allowMultipleAds":
+			if v.AllowMultipleAds == nil {
+				v.AllowMultipleAds = new(bool)
+			}
+			if err := parseBool(v.AllowMultipleAds, a.Value); err != nil {
+				return err
+			}
+		case "followAdditionalWrappers":
+			if v.FollowAdditionalWrappers == nil {
+				v.FollowAdditionalWrappers = new(bool)
+			}
+			if err := parseBool(v.FollowAdditionalWrappers, a.Value); err != nil {
+				return err
+			}
+		default:
+			if keepsUnknown(d) {
+				if err := v.UnknownAttrs.UnmarshalXMLAttr(a); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "AdSystem":
+				if v.AdSystem == nil {
+					v.AdSystem = new(AdSystem)
+				}
+				if err := v.AdSystem.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Error":
+				v.Errors = append(v.Errors, CDATAString{})
+				if err := v.Errors[len(v.Errors)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Extensions":
+				for {
+					tok, err := d.Token()
+					if err != nil {
+						return err
+					}
+					if _, ok := tok.(xml.EndElement); ok {
+						break
+					}
+					t, ok := tok.(xml.StartElement)
+					if !ok {
+						continue
+					}
+					if t.Name.Local != "Extension" {
+						if err := d.Skip(); err != nil {
+							return err
+						}
+						continue
+					}
+					if v.Extensions == nil {
+						v.Extensions = new([]Extension)
+					}
+					*v.Extensions = append(*v.Extensions, Extension{})
+					if err := d.DecodeElement(&(*v.Extensions)[len(*v.Extensions)-1], &t); err != nil {
+						return err
+					}
+				}
+			case "Impression":
+				v.Impressions = append(v.Impressions, Impression{})
+				if err := v.Impressions[len(v.Impressions)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Creatives":
+				for {
+					tok, err := d.Token()
+					if err != nil {
+						return err
+					}
+					if _, ok := tok.(xml.EndElement); ok {
+						break
+					}
+					t, ok := tok.(xml.StartElement)
+					if !ok {
+						continue
+					}
+					if t.Name.Local != "Creative" {
+						if err := d.Skip(); err != nil {
+							return err
+						}
+						continue
+					}
+					v.Creatives = append(v.Creatives, CreativeWrapper{})
+					if err := d.DecodeElement(&v.Creatives[len(v.Creatives)-1], &t); err != nil {
+						return err
+					}
+				}
+			case "VASTAdTagURI":
+				if err := v.VASTAdTagURI.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Pricing":
+				if v.Pricing == nil {
+					v.Pricing = new(Pricing)
+				}
+				if err := d.DecodeElement(v.Pricing, &t); err != nil {
+					return err
+				}
+			case "ViewableImpression":
+				if v.ViewableImpression == nil {
+					v.ViewableImpression = new(ViewableImpression)
+				}
+				if err := d.DecodeElement(v.ViewableImpression, &t); err != nil {
+					return err
+				}
+			case "AdVerifications":
+				if v.AdVerifications == nil {
+					v.AdVerifications = new(AdVerifications)
+				}
+				if err := d.DecodeElement(v.AdVerifications, &t); err != nil {
+					return err
+				}
+			default:
+				if !keepsUnknown(d) {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				v.Unknown = append(v.Unknown, UnknownElement{})
+				if err := d.DecodeElement(&v.Unknown[len(v.Unknown)-1], &t); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *Wrapper) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *Wrapper) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	{
+		b = append(b, `"AdSystem":`...)
+		if v.AdSystem == nil {
+			b = append(b, "null"...)
+		} else {
+			if b, err = v.AdSystem.appendJSON(b); err != nil {
+				return nil, err
+			}
+		}
+		b = append(b, ',')
+	}
+	if len(v.Errors) != 0 {
+		b = append(b, `"Error":`...)
+		b = append(b, '[')
+		for i := range v.Errors {
+			if b, err = v.Errors[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	if v.Extensions != nil {
+		b = append(b, `"Extensions":`...)
+		if b, err = appendJSON(b, &v.Extensions); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Impressions":`...)
+		if v.Impressions == nil {
+			b = append(b, "null"...)
+		} else {
+			b = append(b, '[')
+			for i := range v.Impressions {
+				if b, err = v.Impressions[i].appendJSON(b); err != nil {
+					return nil, err
+				}
+				b = append(b, ',')
+			}
+			b = closeJSON(b, ']')
+		}
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Creatives":`...)
+		if b, err = appendJSON(b, &v.Creatives); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"VASTAdTagURI":`...)
+		if b, err = v.VASTAdTagURI.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.Pricing != nil {
+		b = append(b, `"Pricing":`...)
+		if b, err = appendJSON(b, &v.Pricing); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.ViewableImpression != nil {
+		b = append(b, `"ViewableImpression":`...)
+		if b, err = appendJSON(b, &v.ViewableImpression); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.AdVerifications != nil {
+		b = append(b, `"AdVerifications":`...)
+		if b, err = appendJSON(b, &v.AdVerifications); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.FallbackOnNoAd != nil {
+		b = append(b, `"FallbackOnNoAd":`...)
+		b = strconv.AppendBool(b, *v.FallbackOnNoAd)
+		b = append(b, ',')
+	}
+	if v.AllowMultipleAds != nil {
+		b = append(b, `"AllowMultipleAds":`...)
+		b = strconv.AppendBool(b, *v.AllowMultipleAds)
+		b = append(b, ',')
+	}
+	if v.FollowAdditionalWrappers != nil {
+		b = append(b, `"FollowAdditionalWrappers":`...)
+		b = strconv.AppendBool(b, *v.FollowAdditionalWrappers)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *Wrapper) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsWrapper = []string{"AdSystem", "Error", "Extensions", "Impressions", "Creatives", "VASTAdTagURI", "Pricing", "ViewableImpression", "AdVerifications", "FallbackOnNoAd", "AllowMultipleAds", "FollowAdditionalWrappers"}
+
+func (v *Wrapper) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsWrapper) {
+		case 0:
+			if r.null() {
+				v.AdSystem = nil
+				break
+			}
+			if v.AdSystem == nil {
+				v.AdSystem = new(AdSystem)
+			}
+			if err := v.AdSystem.decodeJSON(r); err != nil {
+				return err
+			}
+		case 1:
+			if r.null() {
+				v.Errors = nil
+				break
+			}
+			if ok, err := r.array(&v.Errors); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Errors) {
+					if i < cap(v.Errors) {
+						v.Errors = v.Errors[:i+1]
+					} else {
+						v.Errors = append(v.Errors, CDATAString{})
+					}
+				}
+				if err := v.Errors[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Errors = []CDATAString{}
+			} else {
+				v.Errors = v.Errors[:i]
+			}
+		case 2:
+			if err := r.unmarshal(&v.Extensions); err != nil {
+				return err
+			}
+		case 3:
+			if r.null() {
+				v.Impressions = nil
+				break
+			}
+			if ok, err := r.array(&v.Impressions); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Impressions) {
+					if i < cap(v.Impressions) {
+						v.Impressions = v.Impressions[:i+1]
+					} else {
+						v.Impressions = append(v.Impressions, Impression{})
+					}
+				}
+				if err := v.Impressions[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Impressions = []Impression{}
+			} else {
+				v.Impressions = v.Impressions[:i]
+			}
+		case 4:
+			if err := r.unmarshal(&v.Creatives); err != nil {
+				return err
+			}
+		case 5:
+			if err := v.VASTAdTagURI.decodeJSON(r); err != nil {
+				return err
+			}
+		case 6:
+			if err := r.unmarshal(&v.Pricing); err != nil {
+				return err
+			}
+		case 7:
+			if err := r.unmarshal(&v.ViewableImpression); err != nil {
+				return err
+			}
+		case 8:
+			if err := r.unmarshal(&v.AdVerifications); err != nil {
+				return err
+			}
+		case 9:
+			if r.null() {
+				v.FallbackOnNoAd = nil
+				break
+			}
+			if v.FallbackOnNoAd == nil {
+				v.FallbackOnNoAd = new(bool)
+			}
+			if err := r.decodeBool(v.FallbackOnNoAd); err != nil {
+				return err
+			}
+		case 10:
+			if r.null() {
+				v.AllowMultipleAds = nil
+				break
+			}
+			if v.AllowMultipleAds == nil {
+				v.AllowMultipleAds = new(bool)
+			}
+			if err := r.decodeBool(v.AllowMultipleAds); err != nil {
+				return err
+			}
+		case 11:
+			if r.null() {
+				v.FollowAdditionalWrappers = nil
+				break
+			}
+			if v.FollowAdditionalWrappers == nil {
+				v.FollowAdditionalWrappers = new(bool)
+			}
+			if err := r.decodeBool(v.FollowAdditionalWrappers); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*Wrapper) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *Creative) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: v.ID})
+	}
+	if v.Sequence != 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "sequence"}, Value: strconv.Itoa(v.Sequence)})
+	}
+	if v.AdID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "adId"}, Value: v.AdID})
+	}
+	if v.APIFramework != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "apiFramework"}, Value: v.APIFramework})
+	}
+	start.Attr = append(start.Attr, v.UnknownAttrs...)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.UniversalAdID != nil {
+		for i := range *v.UniversalAdID {
+			if err := e.EncodeElement(&(*v.UniversalAdID)[i], xml.StartElement{Name: xml.Name{Local: "UniversalAdId"}}); err != nil {
+				return err
+			}
+		}
+	}
+	if v.Linear != nil {
+		if err := v.Linear.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Linear"}}); err != nil {
+			return err
+		}
+	}
+	if v.CompanionAds != nil {
+		if err := e.EncodeElement(v.CompanionAds, xml.StartElement{Name: xml.Name{Local: "CompanionAds"}}); err != nil {
+			return err
+		}
+	}
+	if v.NonLinearAds != nil {
+		if err := e.EncodeElement(v.NonLinearAds, xml.StartElement{Name: xml.Name{Local: "NonLinearAds"}}); err != nil {
+			return err
+		}
+	}
+	if v.CreativeExtensions != nil {
+		parent := xml.StartElement{Name: xml.Name{Local: "CreativeExtensions"}}
+		if err := e.EncodeToken(parent); err != nil {
+			return err
+		}
+		for i := range *v.CreativeExtensions {
+			if err := e.EncodeElement(&(*v.CreativeExtensions)[i], xml.StartElement{Name: xml.Name{Local: "CreativeExtension"}}); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(parent.End()); err != nil {
+			return err
+		}
+	}
+	for i := range v.Unknown {
+		if err := e.Encode(&v.Unknown[i]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *Creative) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			v.ID = a.Value
+		case "sequence":
+			if err := parseInt(&v.Sequence, a.Value); err != nil {
+				return err
+			}
+		case "adId":
+			v.AdID = a.Value
+		case "apiFramework":
+			v.APIFramework = a.Value
+		default:
+			if keepsUnknown(d) {
+				if err := v.UnknownAttrs.UnmarshalXMLAttr(a); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "UniversalAdId":
+				if v.UniversalAdID == nil {
+					v.UniversalAdID = new([]UniversalAdID)
+				}
+				*v.UniversalAdID = append(*v.UniversalAdID, UniversalAdID{})
+				if err := d.DecodeElement(&(*v.UniversalAdID)[len(*v.UniversalAdID)-1], &t); err != nil {
+					return err
+				}
+			case "Linear":
+				if v.Linear == nil {
+					v.Linear = new(Linear)
+				}
+				if err := v.Linear.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "CompanionAds":
+				if v.CompanionAds == nil {
+					v.CompanionAds = new(CompanionAds)
+				}
+				if err := d.DecodeElement(v.CompanionAds, &t); err != nil {
+					return err
+				}
+			case "NonLinearAds":
+				if v.NonLinearAds == nil {
+					v.NonLinearAds = new(NonLinearAds)
+				}
+				if err := d.DecodeElement(v.NonLinearAds, &t); err != nil {
+					return err
+				}
+			case "CreativeExtensions":
+				for {
+					tok, err := d.Token()
+					if err != nil {
+						return err
+					}
+					if _, ok := tok.(xml.EndElement); ok {
+						break
+					}
+					t, ok := tok.(xml.StartElement)
+					if !ok {
+						continue
+					}
+					if t.Name.Local != "CreativeExtension" {
+						if err := d.Skip(); err != nil {
+							return err
+						}
+						continue
+					}
+					if v.CreativeExtensions == nil {
+						v.CreativeExtensions = new([]Extension)
+					}
+					*v.CreativeExtensions = append(*v.CreativeExtensions, Extension{})
+					if err := d.DecodeElement(&(*v.CreativeExtensions)[len(*v.CreativeExtensions)-1], &t); err != nil {
+						return err
+					}
+				}
+			default:
+				if !keepsUnknown(d) {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				v.Unknown = append(v.Unknown, UnknownElement{})
+				if err := d.DecodeElement(&v.Unknown[len(v.Unknown)-1], &t); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *Creative) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *Creative) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	if v.ID != "" {
+		b = append(b, `"ID":`...)
+		b = appendJSONString(b, v.ID)
+		b = append(b, ',')
+	}
+	if v.Sequence != 0 {
+		b = append(b, `"Sequence":`...)
+		b = strconv.AppendInt(b, int64(v.Sequence), 10)
+		b = append(b, ',')
+	}
+	if v.AdID != "" {
+		b = append(b, `"AdID":`...)
+		b = appendJSONString(b, v.AdID)
+		b = append(b, ',')
+	}
+	if v.APIFramework != "" {
+		b = append(b, `"APIFramework":`...)
+		b = appendJSONString(b, v.APIFramework)
+		b = append(b, ',')
+	}
+	if v.UniversalAdID != nil {
+		b = append(b, `"UniversalAdID":`...)
+		if b, err = appendJSON(b, &v.UniversalAdID); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.Linear != nil {
+		b = append(b, `"Linear":`...)
+		if b, err = v.Linear.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.CompanionAds != nil {
+		b = append(b, `"CompanionAds":`...)
+		if b, err = appendJSON(b, &v.CompanionAds); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.NonLinearAds != nil {
+		b = append(b, `"NonLinearAds":`...)
+		if b, err = appendJSON(b, &v.NonLinearAds); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.CreativeExtensions != nil {
+		b = append(b, `"CreativeExtensions":`...)
+		if b, err = appendJSON(b, &v.CreativeExtensions); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *Creative) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsCreative = []string{"ID", "Sequence", "AdID", "APIFramework", "UniversalAdID", "Linear", "CompanionAds", "NonLinearAds", "CreativeExtensions"}
+
+func (v *Creative) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsCreative) {
+		case 0:
+			if err := r.decodeString(&v.ID); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeInt(&v.Sequence); err != nil {
+				return err
+			}
+		case 2:
+			if err := r.decodeString(&v.AdID); err != nil {
+				return err
+			}
+		case 3:
+			if err := r.decodeString(&v.APIFramework); err != nil {
+				return err
+			}
+		case 4:
+			if err := r.unmarshal(&v.UniversalAdID); err != nil {
+				return err
+			}
+		case 5:
+			if r.null() {
+				v.Linear = nil
+				break
+			}
+			if v.Linear == nil {
+				v.Linear = new(Linear)
+			}
+			if err := v.Linear.decodeJSON(r); err != nil {
+				return err
+			}
+		case 6:
+			if err := r.unmarshal(&v.CompanionAds); err != nil {
+				return err
+			}
+		case 7:
+			if err := r.unmarshal(&v.NonLinearAds); err != nil {
+				return err
+			}
+		case 8:
+			if err := r.unmarshal(&v.CreativeExtensions); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*Creative) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *Linear) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.SkipOffset != nil {
+		b, err := v.SkipOffset.MarshalText()
+		if err != nil {
+			return err
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "skipoffset"}, Value: string(b)})
+	}
+	start.Attr = append(start.Attr, v.UnknownAttrs...)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.Duration != 0 {
+		b, err := v.Duration.MarshalText()
+		if err != nil {
+			return err
+		}
+		if err := encodeText(e, xml.StartElement{Name: xml.Name{Local: "Duration"}}, string(b)); err != nil {
+			return err
+		}
+	}
+	if v.Icons != nil {
+		if err := e.EncodeElement(v.Icons, xml.StartElement{Name: xml.Name{Local: "Icons"}}); err != nil {
+			return err
+		}
+	}
+	if v.TrackingEvents != nil {
+		if err := v.TrackingEvents.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "TrackingEvents"}}); err != nil {
+			return err
+		}
+	}
+	if v.AdParameters != nil {
+		if err := v.AdParameters.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "AdParameters"}}); err != nil {
+			return err
+		}
+	}
+	if v.VideoClicks != nil {
+		if err := v.VideoClicks.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "VideoClicks"}}); err != nil {
+			return err
+		}
+	}
+	if v.MediaFiles != nil {
+		if err := v.MediaFiles.MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "MediaFiles"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.Unknown {
+		if err := e.Encode(&v.Unknown[i]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *Linear) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "skipoffset":
+			if v.SkipOffset == nil {
+				v.SkipOffset = new(Offset)
+			}
+			if err := v.SkipOffset.UnmarshalText([]byte(a.Value)); err != nil {
+				return err
+			}
+		default:
+			if keepsUnknown(d) {
+				if err := v.UnknownAttrs.UnmarshalXMLAttr(a); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "Duration":
+				b, err := decodeText(d)
+				if err != nil {
+					return err
+				}
+				if err := v.Duration.UnmarshalText(b); err != nil {
+					return err
+				}
+			case "Icons":
+				if v.Icons == nil {
+					v.Icons = new(Icons)
+				}
+				if err := d.DecodeElement(v.Icons, &t); err != nil {
+					return err
+				}
+			case "TrackingEvents":
+				if v.TrackingEvents == nil {
+					v.TrackingEvents = new(TrackingEvents)
+				}
+				if err := v.TrackingEvents.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "AdParameters":
+				if v.AdParameters == nil {
+					v.AdParameters = new(AdParameters)
+				}
+				if err := v.AdParameters.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "VideoClicks":
+				if v.VideoClicks == nil {
+					v.VideoClicks = new(VideoClicks)
+				}
+				if err := v.VideoClicks.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "MediaFiles":
+				if v.MediaFiles == nil {
+					v.MediaFiles = new(MediaFiles)
+				}
+				if err := v.MediaFiles.UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			default:
+				if !keepsUnknown(d) {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				v.Unknown = append(v.Unknown, UnknownElement{})
+				if err := d.DecodeElement(&v.Unknown[len(v.Unknown)-1], &t); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *Linear) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *Linear) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	if v.SkipOffset != nil {
+		b = append(b, `"SkipOffset":`...)
+		text, err := v.SkipOffset.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		b = appendJSONString(b, string(text))
+		b = append(b, ',')
+	}
+	if v.Duration != 0 {
+		b = append(b, `"Duration":`...)
+		text, err := v.Duration.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		b = appendJSONString(b, string(text))
+		b = append(b, ',')
+	}
+	if v.Icons != nil {
+		b = append(b, `"Icons":`...)
+		if b, err = appendJSON(b, &v.Icons); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.TrackingEvents != nil {
+		b = append(b, `"TrackingEvents":`...)
+		if b, err = v.TrackingEvents.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.AdParameters != nil {
+		b = append(b, `"AdParameters":`...)
+		if b, err = v.AdParameters.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.VideoClicks != nil {
+		b = append(b, `"VideoClicks":`...)
+		if b, err = v.VideoClicks.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.MediaFiles != nil {
+		b = append(b, `"MediaFiles":`...)
+		if b, err = v.MediaFiles.appendJSON(b); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *Linear) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsLinear = []string{"SkipOffset", "Duration", "Icons", "TrackingEvents", "AdParameters", "VideoClicks", "MediaFiles"}
+
+func (v *Linear) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsLinear) {
+		case 0:
+			if r.null() {
+				v.SkipOffset = nil
+				break
+			}
+			if v.SkipOffset == nil {
+				v.SkipOffset = new(Offset)
+			}
+			if err := r.decodeText(v.SkipOffset); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeText(&v.Duration); err != nil {
+				return err
+			}
+		case 2:
+			if err := r.unmarshal(&v.Icons); err != nil {
+				return err
+			}
+		case 3:
+			if r.null() {
+				v.TrackingEvents = nil
+				break
+			}
+			if v.TrackingEvents == nil {
+				v.TrackingEvents = new(TrackingEvents)
+			}
+			if err := v.TrackingEvents.decodeJSON(r); err != nil {
+				return err
+			}
+		case 4:
+			if r.null() {
+				v.AdParameters = nil
+				break
+			}
+			if v.AdParameters == nil {
+				v.AdParameters = new(AdParameters)
+			}
+			if err := v.AdParameters.decodeJSON(r); err != nil {
+				return err
+			}
+		case 5:
+			if r.null() {
+				v.VideoClicks = nil
+				break
+			}
+			if v.VideoClicks == nil {
+				v.VideoClicks = new(VideoClicks)
+			}
+			if err := v.VideoClicks.decodeJSON(r); err != nil {
+				return err
+			}
+		case 6:
+			if r.null() {
+				v.MediaFiles = nil
+				break
+			}
+			if v.MediaFiles == nil {
+				v.MediaFiles = new(MediaFiles)
+			}
+			if err := v.MediaFiles.decodeJSON(r); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*Linear) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *MediaFiles) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := range v.MediaFile {
+		if err := v.MediaFile[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "MediaFile"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.Mezzanine {
+		if err := e.EncodeElement(&v.Mezzanine[i], xml.StartElement{Name: xml.Name{Local: "Mezzanine"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.InteractiveCreativeFile {
+		if err := e.EncodeElement(&v.InteractiveCreativeFile[i], xml.StartElement{Name: xml.Name{Local: "InteractiveCreativeFile"}}); err != nil {
+			return err
+		}
+	}
+	if v.ClosedCaptionFiles != nil {
+		parent := xml.StartElement{Name: xml.Name{Local: "ClosedCaptionFiles"}}
+		if err := e.EncodeToken(parent); err != nil {
+			return err
+		}
+		for i := range *v.ClosedCaptionFiles {
+			if err := e.EncodeElement(&(*v.ClosedCaptionFiles)[i], xml.StartElement{Name: xml.Name{Local: "ClosedCaptionFile"}}); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(parent.End()); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *MediaFiles) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "MediaFile":
+				v.MediaFile = append(v.MediaFile, MediaFile{})
+				if err := v.MediaFile[len(v.MediaFile)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "Mezzanine":
+				v.Mezzanine = append(v.Mezzanine, Mezzanine{})
+				if err := d.DecodeElement(&v.Mezzanine[len(v.Mezzanine)-1], &t); err != nil {
+					return err
+				}
+			case "InteractiveCreativeFile":
+				v.InteractiveCreativeFile = append(v.InteractiveCreativeFile, InteractiveCreativeFile{})
+				if err := d.DecodeElement(&v.InteractiveCreativeFile[len(v.InteractiveCreativeFile)-1], &t); err != nil {
+					return err
+				}
+			case "ClosedCaptionFiles":
+				for {
+					tok, err := d.Token()
+					if err != nil {
+						return err
+					}
+					if _, ok := tok.(xml.EndElement); ok {
+						break
+					}
+					t, ok := tok.(xml.StartElement)
+					if !ok {
+						continue
+					}
+					if t.Name.Local != "ClosedCaptionFile" {
+						if err := d.Skip(); err != nil {
+							return err
+						}
+						continue
+					}
+					if v.ClosedCaptionFiles == nil {
+						v.ClosedCaptionFiles = new([]ClosedCaptionFile)
+					}
+					*v.ClosedCaptionFiles = append(*v.ClosedCaptionFiles, ClosedCaptionFile{})
+					if err := d.DecodeElement(&(*v.ClosedCaptionFiles)[len(*v.ClosedCaptionFiles)-1], &t); err != nil {
+						return err
+					}
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *MediaFiles) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *MediaFiles) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	if len(v.MediaFile) != 0 {
+		b = append(b, `"MediaFile":`...)
+		b = append(b, '[')
+		for i := range v.MediaFile {
+			if b, err = v.MediaFile[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	if len(v.Mezzanine) != 0 {
+		b = append(b, `"Mezzanine":`...)
+		if b, err = appendJSON(b, &v.Mezzanine); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if len(v.InteractiveCreativeFile) != 0 {
+		b = append(b, `"InteractiveCreativeFile":`...)
+		if b, err = appendJSON(b, &v.InteractiveCreativeFile); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	if v.ClosedCaptionFiles != nil {
+		b = append(b, `"ClosedCaptionFiles":`...)
+		if b, err = appendJSON(b, &v.ClosedCaptionFiles); err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *MediaFiles) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsMediaFiles = []string{"MediaFile", "Mezzanine", "InteractiveCreativeFile", "ClosedCaptionFiles"}
+
+func (v *MediaFiles) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsMediaFiles) {
+		case 0:
+			if r.null() {
+				v.MediaFile = nil
+				break
+			}
+			if ok, err := r.array(&v.MediaFile); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.MediaFile) {
+					if i < cap(v.MediaFile) {
+						v.MediaFile = v.MediaFile[:i+1]
+					} else {
+						v.MediaFile = append(v.MediaFile, MediaFile{})
+					}
+				}
+				if err := v.MediaFile[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.MediaFile = []MediaFile{}
+			} else {
+				v.MediaFile = v.MediaFile[:i]
+			}
+		case 1:
+			if err := r.unmarshal(&v.Mezzanine); err != nil {
+				return err
+			}
+		case 2:
+			if err := r.unmarshal(&v.InteractiveCreativeFile); err != nil {
+				return err
+			}
+		case 3:
+			if err := r.unmarshal(&v.ClosedCaptionFiles); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*MediaFiles) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *MediaFile) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !cdataSafe(v.URI) {
+		type plain MediaFile
+		return e.EncodeElement((*plain)(v), start)
+	}
+	if v.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: v.ID})
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "delivery"}, Value: v.Delivery})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: v.Type})
+	if v.Bitrate != 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "bitrate"}, Value: strconv.Itoa(v.Bitrate)})
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "width"}, Value: strconv.Itoa(v.Width)})
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "height"}, Value: strconv.Itoa(v.Height)})
+	if v.MinBitrate != 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "minBitrate"}, Value: strconv.Itoa(v.MinBitrate)})
+	}
+	if v.MaxBitrate != 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maxBitrate"}, Value: strconv.Itoa(v.MaxBitrate)})
+	}
+	if v.Scalable {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "scalable"}, Value: strconv.FormatBool(v.Scalable)})
+	}
+	if v.MaintainAspectRatio {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "maintainAspectRatio"}, Value: strconv.FormatBool(v.MaintainAspectRatio)})
+	}
+	if v.Codec != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "codec"}, Value: v.Codec})
+	}
+	if v.APIFramework != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "apiFramework"}, Value: v.APIFramework})
+	}
+	if v.Label != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: v.Label})
+	}
+	if v.FileSize != 0 {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "fileSize"}, Value: strconv.Itoa(v.FileSize)})
+	}
+	if v.MediaType != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "mediaType"}, Value: v.MediaType})
+	}
+	start.Attr = append(start.Attr, v.UnknownAttrs...)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeCDATA(e, v.URI); err != nil {
+		return err
+	}
+	for i := range v.Unknown {
+		if err := e.Encode(&v.Unknown[i]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *MediaFile) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			v.ID = a.Value
+		case "delivery":
+			v.Delivery = a.Value
+		case "type":
+			v.Type = a.Value
+		case "bitrate":
+			if err := parseInt(&v.Bitrate, a.Value); err != nil {
+				return err
+			}
+		case "width":
+			if err := parseInt(&v.Width, a.Value); err != nil {
+				return err
+			}
+		case "height":
+			if err := parseInt(&v.Height, a.Value); err != nil {
+				return err
+			}
+		case "minBitrate":
+			if err := parseInt(&v.MinBitrate, a.Value); err != nil {
+				return err
+			}
+		case "maxBitrate":
+			if err := parseInt(&v.MaxBitrate, a.Value); err != nil {
+				return err
+			}
+		case "scalable":
+			if err := parseBool(&v.Scalable, a.Value); err != nil {
+				return err
+			}
+		case "maintainAspectRatio":
+			if err := parseBool(&v.MaintainAspectRatio, a.Value); err != nil {
+				return err
+			}
+		case "codec":
+			v.Codec = a.Value
+		case "apiFramework":
+			v.APIFramework = a.Value
+		case "label":
+			v.Label = a.Value
+		case "fileSize":
+			if err := parseInt(&v.FileSize, a.Value); err != nil {
+				return err
+			}
+		case "mediaType":
+			v.MediaType = a.Value
+		default:
+			if keepsUnknown(d) {
+				if err := v.UnknownAttrs.UnmarshalXMLAttr(a); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if !keepsUnknown(d) {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				v.Unknown = append(v.Unknown, UnknownElement{})
+				if err := d.DecodeElement(&v.Unknown[len(v.Unknown)-1], &t); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.URI = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *MediaFile) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *MediaFile) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	if v.ID != "" {
+		b = append(b, `"ID":`...)
+		b = appendJSONString(b, v.ID)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Delivery":`...)
+		b = appendJSONString(b, v.Delivery)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Type":`...)
+		b = appendJSONString(b, v.Type)
+		b = append(b, ',')
+	}
+	if v.Bitrate != 0 {
+		b = append(b, `"Bitrate":`...)
+		b = strconv.AppendInt(b, int64(v.Bitrate), 10)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Width":`...)
+		b = strconv.AppendInt(b, int64(v.Width), 10)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Height":`...)
+		b = strconv.AppendInt(b, int64(v.Height), 10)
+		b = append(b, ',')
+	}
+	if v.MinBitrate != 0 {
+		b = append(b, `"MinBitrate":`...)
+		b = strconv.AppendInt(b, int64(v.MinBitrate), 10)
+		b = append(b, ',')
+	}
+	if v.MaxBitrate != 0 {
+		b = append(b, `"MaxBitrate":`...)
+		b = strconv.AppendInt(b, int64(v.MaxBitrate), 10)
+		b = append(b, ',')
+	}
+	if v.Scalable {
+		b = append(b, `"Scalable":`...)
+		b = strconv.AppendBool(b, v.Scalable)
+		b = append(b, ',')
+	}
+	if v.MaintainAspectRatio {
+		b = append(b, `"MaintainAspectRatio":`...)
+		b = strconv.AppendBool(b, v.MaintainAspectRatio)
+		b = append(b, ',')
+	}
+	if v.Codec != "" {
+		b = append(b, `"Codec":`...)
+		b = appendJSONString(b, v.Codec)
+		b = append(b, ',')
+	}
+	if v.APIFramework != "" {
+		b = append(b, `"APIFramework":`...)
+		b = appendJSONString(b, v.APIFramework)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"URI":`...)
+		b = appendJSONString(b, v.URI)
+		b = append(b, ',')
+	}
+	if v.Label != "" {
+		b = append(b, `"Label":`...)
+		b = appendJSONString(b, v.Label)
+		b = append(b, ',')
+	}
+	if v.FileSize != 0 {
+		b = append(b, `"FileSize":`...)
+		b = strconv.AppendInt(b, int64(v.FileSize), 10)
+		b = append(b, ',')
+	}
+	if v.MediaType != "" {
+		b = append(b, `"MediaType":`...)
+		b = appendJSONString(b, v.MediaType)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *MediaFile) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsMediaFile = []string{"ID", "Delivery", "Type", "Bitrate", "Width", "Height", "MinBitrate", "MaxBitrate", "Scalable", "MaintainAspectRatio", "Codec", "APIFramework", "URI", "Label", "FileSize", "MediaType"}
+
+func (v *MediaFile) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsMediaFile) {
+		case 0:
+			if err := r.decodeString(&v.ID); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeString(&v.Delivery); err != nil {
+				return err
+			}
+		case 2:
+			if err := r.decodeString(&v.Type); err != nil {
+				return err
+			}
+		case 3:
+			if err := r.decodeInt(&v.Bitrate); err != nil {
+				return err
+			}
+		case 4:
+			if err := r.decodeInt(&v.Width); err != nil {
+				return err
+			}
+		case 5:
+			if err := r.decodeInt(&v.Height); err != nil {
+				return err
+			}
+		case 6:
+			if err := r.decodeInt(&v.MinBitrate); err != nil {
+				return err
+			}
+		case 7:
+			if err := r.decodeInt(&v.MaxBitrate); err != nil {
+				return err
+			}
+		case 8:
+			if err := r.decodeBool(&v.Scalable); err != nil {
+				return err
+			}
+		case 9:
+			if err := r.decodeBool(&v.MaintainAspectRatio); err != nil {
+				return err
+			}
+		case 10:
+			if err := r.decodeString(&v.Codec); err != nil {
+				return err
+			}
+		case 11:
+			if err := r.decodeString(&v.APIFramework); err != nil {
+				return err
+			}
+		case 12:
+			if err := r.decodeString(&v.URI); err != nil {
+				return err
+			}
+		case 13:
+			if err := r.decodeString(&v.Label); err != nil {
+				return err
+			}
+		case 14:
+			if err := r.decodeInt(&v.FileSize); err != nil {
+				return err
+			}
+		case 15:
+			if err := r.decodeString(&v.MediaType); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*MediaFile) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *TrackingEvents) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := range v.Tracking {
+		if err := v.Tracking[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "Tracking"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *TrackingEvents) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "Tracking":
+				v.Tracking = append(v.Tracking, Tracking{})
+				if err := v.Tracking[len(v.Tracking)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *TrackingEvents) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *TrackingEvents) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	{
+		b = append(b, `"Tracking":`...)
+		if v.Tracking == nil {
+			b = append(b, "null"...)
+		} else {
+			b = append(b, '[')
+			for i := range v.Tracking {
+				if b, err = v.Tracking[i].appendJSON(b); err != nil {
+					return nil, err
+				}
+				b = append(b, ',')
+			}
+			b = closeJSON(b, ']')
+		}
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *TrackingEvents) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsTrackingEvents = []string{"Tracking"}
+
+func (v *TrackingEvents) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsTrackingEvents) {
+		case 0:
+			if r.null() {
+				v.Tracking = nil
+				break
+			}
+			if ok, err := r.array(&v.Tracking); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.Tracking) {
+					if i < cap(v.Tracking) {
+						v.Tracking = v.Tracking[:i+1]
+					} else {
+						v.Tracking = append(v.Tracking, Tracking{})
+					}
+				}
+				if err := v.Tracking[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.Tracking = []Tracking{}
+			} else {
+				v.Tracking = v.Tracking[:i]
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*TrackingEvents) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *Tracking) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !cdataSafe(v.URI) {
+		type plain Tracking
+		return e.EncodeElement((*plain)(v), start)
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "event"}, Value: v.Event})
+	if v.Offset != nil {
+		b, err := v.Offset.MarshalText()
+		if err != nil {
+			return err
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "offset"}, Value: string(b)})
+	}
+	if v.UA != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "ua"}, Value: v.UA})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeCDATA(e, v.URI); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *Tracking) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "event":
+			v.Event = a.Value
+		case "offset":
+			if v.Offset == nil {
+				v.Offset = new(Offset)
+			}
+			if err := v.Offset.UnmarshalText([]byte(a.Value)); err != nil {
+				return err
+			}
+		case "ua":
+			v.UA = a.Value
+		}
+	}
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.URI = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *Tracking) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *Tracking) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	{
+		b = append(b, `"Event":`...)
+		b = appendJSONString(b, v.Event)
+		b = append(b, ',')
+	}
+	if v.Offset != nil {
+		b = append(b, `"Offset":`...)
+		text, err := v.Offset.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		b = appendJSONString(b, string(text))
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"URI":`...)
+		b = appendJSONString(b, v.URI)
+		b = append(b, ',')
+	}
+	if v.UA != "" {
+		b = append(b, `"UA":`...)
+		b = appendJSONString(b, v.UA)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *Tracking) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsTracking = []string{"Event", "Offset", "URI", "UA"}
+
+func (v *Tracking) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsTracking) {
+		case 0:
+			if err := r.decodeString(&v.Event); err != nil {
+				return err
+			}
+		case 1:
+			if r.null() {
+				v.Offset = nil
+				break
+			}
+			if v.Offset == nil {
+				v.Offset = new(Offset)
+			}
+			if err := r.decodeText(v.Offset); err != nil {
+				return err
+			}
+		case 2:
+			if err := r.decodeString(&v.URI); err != nil {
+				return err
+			}
+		case 3:
+			if err := r.decodeString(&v.UA); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*Tracking) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *AdSystem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !textSafe(v.Name) {
+		type plain AdSystem
+		return e.EncodeElement((*plain)(v), start)
+	}
+	if v.Version != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "version"}, Value: v.Version})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.Name != "" {
+		if err := e.EncodeToken(xml.CharData(v.Name)); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *AdSystem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "version":
+			v.Version = a.Value
+		}
+	}
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.Name = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *AdSystem) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *AdSystem) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	if v.Version != "" {
+		b = append(b, `"Version":`...)
+		b = appendJSONString(b, v.Version)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Data":`...)
+		b = appendJSONString(b, v.Name)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *AdSystem) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsAdSystem = []string{"Version", "Data"}
+
+func (v *AdSystem) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsAdSystem) {
+		case 0:
+			if err := r.decodeString(&v.Version); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeString(&v.Name); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*AdSystem) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *Impression) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !cdataSafe(v.URI) {
+		type plain Impression
+		return e.EncodeElement((*plain)(v), start)
+	}
+	if v.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: v.ID})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeCDATA(e, v.URI); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *Impression) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			v.ID = a.Value
+		}
+	}
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.URI = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *Impression) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *Impression) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	if v.ID != "" {
+		b = append(b, `"ID":`...)
+		b = appendJSONString(b, v.ID)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"URI":`...)
+		b = appendJSONString(b, v.URI)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *Impression) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsImpression = []string{"ID", "URI"}
+
+func (v *Impression) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsImpression) {
+		case 0:
+			if err := r.decodeString(&v.ID); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeString(&v.URI); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*Impression) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *CDATAString) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !cdataSafe(v.CDATA) {
+		type plain CDATAString
+		return e.EncodeElement((*plain)(v), start)
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeCDATA(e, v.CDATA); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *CDATAString) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.CDATA = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *CDATAString) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *CDATAString) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	{
+		b = append(b, `"Data":`...)
+		b = appendJSONString(b, v.CDATA)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *CDATAString) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsCDATAString = []string{"Data"}
+
+func (v *CDATAString) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsCDATAString) {
+		case 0:
+			if err := r.decodeString(&v.CDATA); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*CDATAString) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *PlainString) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !textSafe(v.CDATA) {
+		type plain PlainString
+		return e.EncodeElement((*plain)(v), start)
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.CDATA != "" {
+		if err := e.EncodeToken(xml.CharData(v.CDATA)); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *PlainString) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.CDATA = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *PlainString) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *PlainString) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	{
+		b = append(b, `"Data":`...)
+		b = appendJSONString(b, v.CDATA)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *PlainString) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsPlainString = []string{"Data"}
+
+func (v *PlainString) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsPlainString) {
+		case 0:
+			if err := r.decodeString(&v.CDATA); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*PlainString) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *VideoClicks) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := range v.ClickTrackings {
+		if err := v.ClickTrackings[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "ClickTracking"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.CustomClicks {
+		if err := v.CustomClicks[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "CustomClick"}}); err != nil {
+			return err
+		}
+	}
+	for i := range v.ClickThroughs {
+		if err := v.ClickThroughs[i].MarshalXML(e, xml.StartElement{Name: xml.Name{Local: "ClickThrough"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *VideoClicks) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "ClickTracking":
+				v.ClickTrackings = append(v.ClickTrackings, VideoClick{})
+				if err := v.ClickTrackings[len(v.ClickTrackings)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "CustomClick":
+				v.CustomClicks = append(v.CustomClicks, VideoClick{})
+				if err := v.CustomClicks[len(v.CustomClicks)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			case "ClickThrough":
+				v.ClickThroughs = append(v.ClickThroughs, VideoClick{})
+				if err := v.ClickThroughs[len(v.ClickThroughs)-1].UnmarshalXML(d, t); err != nil {
+					return err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *VideoClicks) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *VideoClicks) appendJSON(b []byte) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	if len(v.ClickTrackings) != 0 {
+		b = append(b, `"ClickTrackings":`...)
+		b = append(b, '[')
+		for i := range v.ClickTrackings {
+			if b, err = v.ClickTrackings[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	if len(v.CustomClicks) != 0 {
+		b = append(b, `"CustomClicks":`...)
+		b = append(b, '[')
+		for i := range v.CustomClicks {
+			if b, err = v.CustomClicks[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	if len(v.ClickThroughs) != 0 {
+		b = append(b, `"ClickThroughs":`...)
+		b = append(b, '[')
+		for i := range v.ClickThroughs {
+			if b, err = v.ClickThroughs[i].appendJSON(b); err != nil {
+				return nil, err
+			}
+			b = append(b, ',')
+		}
+		b = closeJSON(b, ']')
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *VideoClicks) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsVideoClicks = []string{"ClickTrackings", "CustomClicks", "ClickThroughs"}
+
+func (v *VideoClicks) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsVideoClicks) {
+		case 0:
+			if r.null() {
+				v.ClickTrackings = nil
+				break
+			}
+			if ok, err := r.array(&v.ClickTrackings); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.ClickTrackings) {
+					if i < cap(v.ClickTrackings) {
+						v.ClickTrackings = v.ClickTrackings[:i+1]
+					} else {
+						v.ClickTrackings = append(v.ClickTrackings, VideoClick{})
+					}
+				}
+				if err := v.ClickTrackings[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.ClickTrackings = []VideoClick{}
+			} else {
+				v.ClickTrackings = v.ClickTrackings[:i]
+			}
+		case 1:
+			if r.null() {
+				v.CustomClicks = nil
+				break
+			}
+			if ok, err := r.array(&v.CustomClicks); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.CustomClicks) {
+					if i < cap(v.CustomClicks) {
+						v.CustomClicks = v.CustomClicks[:i+1]
+					} else {
+						v.CustomClicks = append(v.CustomClicks, VideoClick{})
+					}
+				}
+				if err := v.CustomClicks[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.CustomClicks = []VideoClick{}
+			} else {
+				v.CustomClicks = v.CustomClicks[:i]
+			}
+		case 2:
+			if r.null() {
+				v.ClickThroughs = nil
+				break
+			}
+			if ok, err := r.array(&v.ClickThroughs); !ok || err != nil {
+				if err != nil {
+					return err
+				}
+				break
+			}
+			i := 0
+			for ; ; i++ {
+				ok, err := r.next(i == 0)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					break
+				}
+				if i == len(v.ClickThroughs) {
+					if i < cap(v.ClickThroughs) {
+						v.ClickThroughs = v.ClickThroughs[:i+1]
+					} else {
+						v.ClickThroughs = append(v.ClickThroughs, VideoClick{})
+					}
+				}
+				if err := v.ClickThroughs[i].decodeJSON(r); err != nil {
+					return err
+				}
+			}
+			if i == 0 {
+				v.ClickThroughs = []VideoClick{}
+			} else {
+				v.ClickThroughs = v.ClickThroughs[:i]
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*VideoClicks) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *VideoClick) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !cdataSafe(v.URI) {
+		type plain VideoClick
+		return e.EncodeElement((*plain)(v), start)
+	}
+	if v.ID != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: v.ID})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeCDATA(e, v.URI); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *VideoClick) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			v.ID = a.Value
+		}
+	}
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.URI = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *VideoClick) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *VideoClick) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	if v.ID != "" {
+		b = append(b, `"ID":`...)
+		b = appendJSONString(b, v.ID)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"URI":`...)
+		b = appendJSONString(b, v.URI)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *VideoClick) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsVideoClick = []string{"ID", "URI"}
+
+func (v *VideoClick) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsVideoClick) {
+		case 0:
+			if err := r.decodeString(&v.ID); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeString(&v.URI); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*VideoClick) tagCodec() {}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (v *AdParameters) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !cdataSafe(v.Parameters) {
+		type plain AdParameters
+		return e.EncodeElement((*plain)(v), start)
+	}
+	if v.XMLEncoded {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlEncoded"}, Value: strconv.FormatBool(v.XMLEncoded)})
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeCDATA(e, v.Parameters); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface.
+func (v *AdParameters) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "xmlEncoded":
+			if err := parseBool(&v.XMLEncoded, a.Value); err != nil {
+				return err
+			}
+		}
+	}
+	var data []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			data = append(data, t...)
+		case xml.EndElement:
+			v.Parameters = string(data)
+			return nil
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v *AdParameters) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v *AdParameters) appendJSON(b []byte) ([]byte, error) {
+	b = append(b, '{')
+	if v.XMLEncoded {
+		b = append(b, `"XMLEncoded":`...)
+		b = strconv.AppendBool(b, v.XMLEncoded)
+		b = append(b, ',')
+	}
+	{
+		b = append(b, `"Parameters":`...)
+		b = appendJSONString(b, v.Parameters)
+		b = append(b, ',')
+	}
+	return closeJSON(b, '}'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (v *AdParameters) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(data, v)
+}
+
+var jsonFieldsAdParameters = []string{"XMLEncoded", "Parameters"}
+
+func (v *AdParameters) decodeJSON(r *jsonReader) error {
+	if ok, err := r.object(v); !ok || err != nil {
+		return err
+	}
+	for first := true; ; first = false {
+		key, ok, err := r.key(first)
+		if !ok || err != nil {
+			return err
+		}
+		switch jsonField(key, jsonFieldsAdParameters) {
+		case 0:
+			if err := r.decodeBool(&v.XMLEncoded); err != nil {
+				return err
+			}
+		case 1:
+			if err := r.decodeString(&v.Parameters); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*AdParameters) tagCodec() {}
@@ -0,0 +1,236 @@
+// Code generated by codecgen. DO NOT EDIT.
+
+package vast
+
+// reflectAd is Ad without its generated codecs.
+type reflectAd struct {
+	InLine  *reflectInLine  `xml:",omitempty" json:",omitempty"`
+	Wrapper *reflectWrapper `xml:",omitempty" json:",omitempty"`
+
+	ID string `xml:"id,attr,omitempty" json:",omitempty"`
+
+	AdType string `xml:"adType,attr,omitempty" json:",omitempty"`
+
+	Type string `xml:"type,attr,omitempty" json:",omitempty"`
+
+	Sequence int `xml:"sequence,attr,omitempty" json:",omitempty"`
+
+	ConditionalAd bool `xml:"conditionalAd,attr,omitempty" json:",omitempty"`
+}
+
+// reflectAdParameters is AdParameters without its generated codecs.
+type reflectAdParameters struct {
+	XMLEncoded bool   `xml:"xmlEncoded,attr,omitempty" json:",omitempty"`
+	Parameters string `xml:",cdata"`
+}
+
+// reflectAdSystem is AdSystem without its generated codecs.
+type reflectAdSystem struct {
+	Version string `xml:"version,attr,omitempty" json:"Version,omitempty"`
+	Name    string `xml:",chardata" json:"Data"`
+}
+
+// reflectCDATAString is CDATAString without its generated codecs.
+type reflectCDATAString struct {
+	CDATA string `xml:",cdata" json:"Data"`
+}
+
+// reflectCreative is Creative without its generated codecs.
+type reflectCreative struct {
+	ID string `xml:"id,attr,omitempty" json:",omitempty"`
+
+	Sequence int `xml:"sequence,attr,omitempty" json:",omitempty"`
+
+	AdID string `xml:"adId,attr,omitempty" json:",omitempty"`
+
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:",omitempty"`
+
+	UniversalAdID *[]UniversalAdID `xml:"UniversalAdId,omitempty" json:",omitempty"`
+
+	Linear *reflectLinear `xml:",omitempty" json:",omitempty"`
+
+	CompanionAds *CompanionAds `xml:",omitempty" json:",omitempty"`
+
+	NonLinearAds *NonLinearAds `xml:",omitempty" json:",omitempty"`
+
+	CreativeExtensions *[]Extension `xml:"CreativeExtensions>CreativeExtension,omitempty" json:",omitempty"`
+
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
+}
+
+// reflectImpression is Impression without its generated codecs.
+type reflectImpression struct {
+	ID  string `xml:"id,attr,omitempty" json:",omitempty"`
+	URI string `xml:",cdata" `
+}
+
+// reflectInLine is InLine without its generated codecs.
+type reflectInLine struct {
+	AdSystem *reflectAdSystem
+
+	Errors []reflectCDATAString `xml:"Error,omitempty" json:"Error,omitempty"`
+
+	Extensions *[]Extension `xml:"Extensions>Extension,omitempty" json:",omitempty"`
+
+	Impressions []reflectImpression `xml:"Impression"`
+
+	Pricing *Pricing `xml:",omitempty" json:",omitempty"`
+
+	AdServingId string `xml:",omitempty" json:",omitempty"`
+
+	AdTitle reflectPlainString
+
+	Advertiser *Advertiser `xml:",omitempty" json:",omitempty"`
+
+	Category *[]Category `xml:",omitempty" json:",omitempty"`
+
+	Creatives []reflectCreative `xml:"Creatives>Creative"`
+
+	Description *reflectCDATAString `xml:",omitempty" json:",omitempty"`
+
+	Survey *Survey `xml:",omitempty" json:",omitempty"`
+
+	Expires *int `xml:"Expires,omitempty" json:"Expires,omitempty"`
+
+	ViewableImpression *ViewableImpression `xml:",omitempty" json:",omitempty"`
+
+	AdVerifications *AdVerifications `xml:"AdVerifications,omitempty" json:",omitempty"`
+
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
+}
+
+// reflectLinear is Linear without its generated codecs.
+type reflectLinear struct {
+	SkipOffset *Offset `xml:"skipoffset,attr,omitempty" json:",omitempty"`
+
+	Duration       Duration               `xml:"Duration,omitempty" json:",omitempty"`
+	Icons          *Icons                 `json:",omitempty"`
+	TrackingEvents *reflectTrackingEvents `xml:"TrackingEvents,omitempty" json:",omitempty"`
+	AdParameters   *reflectAdParameters   `xml:",omitempty" json:",omitempty"`
+	VideoClicks    *reflectVideoClicks    `xml:",omitempty" json:",omitempty"`
+	MediaFiles     *reflectMediaFiles     `xml:"MediaFiles" json:",omitempty"`
+
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
+}
+
+// reflectMediaFile is MediaFile without its generated codecs.
+type reflectMediaFile struct {
+	ID string `xml:"id,attr,omitempty" json:",omitempty"`
+
+	Delivery string `xml:"delivery,attr"`
+
+	Type string `xml:"type,attr"`
+
+	Bitrate int `xml:"bitrate,attr,omitempty" json:",omitempty"`
+
+	Width int `xml:"width,attr"`
+
+	Height int `xml:"height,attr"`
+
+	MinBitrate int `xml:"minBitrate,attr,omitempty" json:",omitempty"`
+
+	MaxBitrate int `xml:"maxBitrate,attr,omitempty" json:",omitempty"`
+
+	Scalable bool `xml:"scalable,attr,omitempty" json:",omitempty"`
+
+	MaintainAspectRatio bool `xml:"maintainAspectRatio,attr,omitempty" json:",omitempty"`
+
+	Codec string `xml:"codec,attr,omitempty" json:",omitempty"`
+
+	APIFramework string `xml:"apiFramework,attr,omitempty" json:",omitempty"`
+	URI          string `xml:",cdata"`
+
+	Label string `xml:"label,attr,omitempty" json:",omitempty"`
+
+	FileSize int `xml:"fileSize,attr,omitempty" json:",omitempty"`
+
+	MediaType string `xml:"mediaType,attr,omitempty" json:",omitempty"`
+
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
+}
+
+// reflectMediaFiles is MediaFiles without its generated codecs.
+type reflectMediaFiles struct {
+	MediaFile               []reflectMediaFile        `xml:",omitempty" json:",omitempty"`
+	Mezzanine               []Mezzanine               `xml:",omitempty" json:",omitempty"`
+	InteractiveCreativeFile []InteractiveCreativeFile `xml:",omitempty" json:",omitempty"`
+	ClosedCaptionFiles      *[]ClosedCaptionFile      `xml:"ClosedCaptionFiles>ClosedCaptionFile,omitempty" json:",omitempty"`
+}
+
+// reflectPlainString is PlainString without its generated codecs.
+type reflectPlainString struct {
+	CDATA string `xml:",chardata" json:"Data"`
+}
+
+// reflectTracking is Tracking without its generated codecs.
+type reflectTracking struct {
+	Event string `xml:"event,attr"`
+
+	Offset *Offset `xml:"offset,attr,omitempty" json:",omitempty"`
+	URI    string  `xml:",cdata"`
+
+	UA string `xml:"ua,attr,omitempty" json:",omitempty"`
+}
+
+// reflectTrackingEvents is TrackingEvents without its generated codecs.
+type reflectTrackingEvents struct {
+	Tracking []reflectTracking `xml:"Tracking,omitempty"`
+}
+
+// reflectVAST is VAST without its generated codecs.
+type reflectVAST struct {
+	Version string `xml:"version,attr" json:",omitempty"`
+
+	XMLNS string `xml:"xmlns,attr,omitempty" json:"xmlns,omitempty"`
+
+	Ads []reflectAd `xml:"Ad,omitempty" json:"Ad,omitempty"`
+
+	Errors []reflectCDATAString `xml:"Error,omitempty" json:",omitempty"`
+
+	Mute bool `xml:"mute,attr,omitempty" json:",omitempty"`
+}
+
+// reflectVideoClick is VideoClick without its generated codecs.
+type reflectVideoClick struct {
+	ID  string `xml:"id,attr,omitempty" json:",omitempty"`
+	URI string `xml:",cdata"`
+}
+
+// reflectVideoClicks is VideoClicks without its generated codecs.
+type reflectVideoClicks struct {
+	ClickTrackings []reflectVideoClick `xml:"ClickTracking,omitempty" json:",omitempty"`
+	CustomClicks   []reflectVideoClick `xml:"CustomClick,omitempty" json:",omitempty"`
+	ClickThroughs  []reflectVideoClick `xml:"ClickThrough,omitempty" json:",omitempty"`
+}
+
+// reflectWrapper is Wrapper without its generated codecs.
+type reflectWrapper struct {
+	AdSystem *reflectAdSystem
+
+	Errors []reflectCDATAString `xml:"Error,omitempty" json:"Error,omitempty"`
+
+	Extensions *[]Extension `xml:"Extensions>Extension,omitempty" json:",omitempty"`
+
+	Impressions []reflectImpression `xml:"Impression"`
+
+	Creatives []CreativeWrapper `xml:"Creatives>Creative"`
+
+	VASTAdTagURI reflectCDATAString
+
+	Pricing *Pricing `xml:",omitempty" json:",omitempty"`
+
+	ViewableImpression *ViewableImpression `xml:",omitempty" json:",omitempty"`
+
+	AdVerifications *AdVerifications `xml:"AdVerifications,omitempty" json:",omitempty"`
+
+	FallbackOnNoAd           *bool `xml:"fallbackOnNoAd,attr,omitempty" json:",omitempty"`
+	AllowMultipleAds         *bool `xml:"allowMultipleAds,attr,omitempty" json:",omitempty"`
+	FollowAdditionalWrappers *bool `xml:"followAdditionalWrappers,attr,omitempty" json:",omitempty"`
+
+	UnknownAttrs UnknownAttrs     `xml:",any,attr" json:"-"`
+	Unknown      []UnknownElement `xml:",any" json:"-"`
+}
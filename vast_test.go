@@ -1009,6 +1009,8 @@ func TestIABVASTSamples(t *testing.T) {
 		"testdata/iab/vast_4.2_samples/Video_Clicks_and_click_tracking-Inline-test.xml",
 		"testdata/iab/vast_4.2_samples/Viewable_Impression-test.xml",
 		"testdata/iab/vast_4.2_samples/Wrapper_Tag-test.xml",
+		"testdata/iab/simid/simid_linear.xml",
+		"testdata/iab/simid/simid_survey.xml",
 	}
 	for _, sample := range samples {
 		t.Run(sample, func(t *testing.T) {
@@ -1028,3 +1030,38 @@ func TestIABVASTSamples(t *testing.T) {
 		})
 	}
 }
+
+func TestSIMID(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/iab/simid/simid_linear.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	in := v.Ads[0].InLine
+	exec := in.AdVerifications.Verification[0].ExecutableResource
+	if assert.Len(t, exec, 1) {
+		assert.Equal(t, "application/javascript", exec[0].Type)
+		assert.Equal(t, "custom", exec[0].ApiFramework)
+	}
+	mf := in.Creatives[0].Linear.MediaFiles
+	if assert.Len(t, mf.InteractiveCreativeFile, 1) {
+		icf := mf.InteractiveCreativeFile[0]
+		assert.Equal(t, "text/html", icf.Type)
+		assert.True(t, icf.IsSIMID())
+		assert.True(t, icf.VariableDuration)
+		assert.Equal(t, "https://example.com/simid/creative.html", icf.URI)
+	}
+	assert.True(t, mf.VariableDuration())
+
+	v, _, _, err = loadFixture("testdata/iab/simid/simid_survey.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	mf = v.Ads[0].InLine.Creatives[0].Linear.MediaFiles
+	if assert.Len(t, mf.InteractiveCreativeFile, 2) {
+		assert.True(t, mf.InteractiveCreativeFile[0].IsSIMID())
+		assert.False(t, mf.InteractiveCreativeFile[1].IsSIMID())
+		assert.Equal(t, "application/javascript", mf.InteractiveCreativeFile[1].Type)
+	}
+	assert.False(t, mf.VariableDuration())
+	assert.False(t, (*MediaFiles)(nil).VariableDuration())
+}
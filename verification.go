@@ -0,0 +1,212 @@
+package vast
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// APIFrameworkOMID is the apiFramework of the Open Measurement verification
+// scripts.
+const APIFrameworkOMID = "omid"
+
+// EventTypeVerificationNotExecuted is the tracking event of a Verification
+// pinged when its script isn't executed, with the [REASON] macro set to one
+// of the VerificationReason codes.
+const EventTypeVerificationNotExecuted = "verificationNotExecuted"
+
+// Codes of the [REASON] macro of the verificationNotExecuted event.
+const (
+	// The publisher doesn't allow the verification resource.
+	VerificationReasonRejected = 1
+	// The API framework or its version isn't supported.
+	VerificationReasonNotSupported = 2
+	// The resource couldn't be loaded.
+	VerificationReasonLoadError = 3
+)
+
+// OMEnvironment describes where the verification scripts run, for
+// SelectVerifications.
+type OMEnvironment struct {
+	// Browserless is set when the scripts run without DOM and other browser
+	// built-ins, e.g. in iOS' JavaScriptCore, where only the browser optional
+	// scripts can run.
+	Browserless bool
+	// Allow reports whether the publisher allows the verification to run,
+	// all of them if nil.
+	Allow func(v *Verification) bool
+}
+
+// OMVerification is a verification considered by SelectVerifications.
+type OMVerification struct {
+	Verification *Verification
+	Vendor       string
+	// URI of the selected Open Measurement script, empty if none was.
+	URI             string
+	BrowserOptional bool
+	// Parameters to pass verbatim to the script.
+	Parameters string
+	// URIs of the verificationNotExecuted tracking events.
+	NotExecuted []string
+	// Why the verification was rejected, as a VerificationReason code, zero
+	// for accepted verifications.
+	Reason int
+}
+
+// NotExecutedURLs returns the verificationNotExecuted URIs of v with their
+// macros expanded using c and the [REASON] macro set to reason, for a
+// verification that was rejected or failed to load.
+func (v *OMVerification) NotExecutedURLs(reason int, c *MacroContext) []string {
+	var mc MacroContext
+	if c != nil {
+		mc = *c
+	}
+	mc.Reason = reason
+	urls := make([]string, len(v.NotExecuted))
+	for i, uri := range v.NotExecuted {
+		urls[i] = mc.Expand(uri)
+	}
+	return urls
+}
+
+// VerificationSelection is the result of SelectVerifications.
+type VerificationSelection struct {
+	// Accepted verifications, in document order.
+	Verifications []OMVerification
+	// Rejected verifications, in document order.
+	Rejected []OMVerification
+	// Errors of the AdVerifications extensions which couldn't be decoded,
+	// whose verifications are missing from the selection.
+	Errors []error
+}
+
+// OMScriptResource is a verification script resource, shaped after the
+// arguments of the VerificationScriptResource of the OM SDK.
+type OMScriptResource struct {
+	ResourceURL            string `json:"resourceUrl"`
+	VendorKey              string `json:"vendorKey,omitempty"`
+	VerificationParameters string `json:"verificationParameters,omitempty"`
+}
+
+// ScriptResources returns the resources of the accepted verifications.
+func (s *VerificationSelection) ScriptResources() []OMScriptResource {
+	res := make([]OMScriptResource, len(s.Verifications))
+	for i, v := range s.Verifications {
+		res[i] = OMScriptResource{ResourceURL: v.URI, VendorKey: v.Vendor, VerificationParameters: v.Parameters}
+	}
+	return res
+}
+
+// ScriptResourcesJSON returns the JSON array of the ScriptResources, to hand
+// over to the OM SDK session.
+func (s *VerificationSelection) ScriptResourcesJSON() ([]byte, error) {
+	return json.Marshal(s.ScriptResources())
+}
+
+// SelectVerifications selects the Open Measurement verification scripts of
+// the resolved ad to load in an environment, nil meaning a browser allowing
+// all the verifications.
+//
+// The verifications are those of the AdVerifications of the InLine ad,
+// which hold the ones of the wrappers once resolved, followed by those of the
+// AdVerifications extensions of documents older than VAST 4.1, the InLine
+// ad first and then the wrappers from the innermost. A verification found
+// twice is only considered once. The extensions which can't be decoded are
+// reported in the Errors of the selection.
+//
+// The script of a verification is its first JavaScriptResource with an
+// apiFramework of "omid" which can run in env. The verifications without one
+// are rejected as not supported, and those env doesn't allow as rejected.
+func SelectVerifications(ad *ResolvedAd, env *OMEnvironment) *VerificationSelection {
+	if env == nil {
+		env = &OMEnvironment{}
+	}
+	s := &VerificationSelection{}
+	seen := make(map[string]bool)
+	var vs []*Verification
+	vs, s.Errors = adVerifications(ad)
+	for _, v := range vs {
+		key := v.Vendor + "\x00"
+		for _, r := range v.JavaScriptResource {
+			key += strings.TrimSpace(r.URI) + "\x00"
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		ov := OMVerification{Verification: v, Vendor: strings.TrimSpace(v.Vendor)}
+		if v.VerificationParameters != nil {
+			ov.Parameters = v.VerificationParameters.URI
+		}
+		if v.TrackingEvents != nil {
+			for _, tr := range v.TrackingEvents.Tracking {
+				if strings.TrimSpace(tr.Event) == EventTypeVerificationNotExecuted && !blank(tr.URI) {
+					ov.NotExecuted = append(ov.NotExecuted, strings.TrimSpace(tr.URI))
+				}
+			}
+		}
+		for _, r := range v.JavaScriptResource {
+			if !strings.EqualFold(strings.TrimSpace(r.ApiFramework), APIFrameworkOMID) || blank(r.URI) || env.Browserless && !r.BrowserOptional {
+				continue
+			}
+			ov.URI = strings.TrimSpace(r.URI)
+			ov.BrowserOptional = r.BrowserOptional
+			break
+		}
+		switch {
+		case ov.URI == "":
+			ov.Reason = VerificationReasonNotSupported
+		case env.Allow != nil && !env.Allow(v):
+			ov.Reason = VerificationReasonRejected
+		}
+		if ov.Reason != 0 {
+			s.Rejected = append(s.Rejected, ov)
+		} else {
+			s.Verifications = append(s.Verifications, ov)
+		}
+	}
+	return s
+}
+
+// adVerifications returns the verifications of the resolved ad, including
+// those of the AdVerifications extensions of the chain, and the errors of the
+// extensions which couldn't be decoded.
+func adVerifications(ad *ResolvedAd) ([]*Verification, []error) {
+	if ad == nil || ad.Ad.InLine == nil {
+		return nil, nil
+	}
+	var res []*Verification
+	in := ad.Ad.InLine
+	if in.AdVerifications != nil {
+		for i := range in.AdVerifications.Verification {
+			res = append(res, &in.AdVerifications.Verification[i])
+		}
+	}
+	var errs []error
+	exts := []*[]Extension{in.Extensions}
+	paths := []string{"InLine.Extensions"}
+	for i := len(ad.Wrappers) - 1; i >= 0; i-- {
+		exts = append(exts, ad.Wrappers[i].Extensions)
+		paths = append(paths, index("Wrappers", i)+".Extensions")
+	}
+	for k, e := range exts {
+		if e == nil {
+			continue
+		}
+		for i := range *e {
+			if (*e)[i].Type != ExtensionTypeAdVerifications {
+				continue
+			}
+			var av AdVerifications
+			if err := (*e)[i].Decode(&av); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", index(paths[k], i), err))
+				continue
+			}
+			for j := range av.Verification {
+				res = append(res, &av.Verification[j])
+			}
+		}
+	}
+	return res, errs
+}
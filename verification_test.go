@@ -0,0 +1,192 @@
+package vast
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const verificationWrapper = `<VAST version="4.2">
+	<Ad id="wrapper">
+		<Wrapper>
+			<AdSystem>Wrapper</AdSystem>
+			<VASTAdTagURI><![CDATA[http://inline]]></VASTAdTagURI>
+			<AdVerifications>
+				<Verification vendor="wrapper.com-omid">
+					<JavaScriptResource apiFramework="omid"><![CDATA[https://wrapper.com/omid.js]]></JavaScriptResource>
+				</Verification>
+			</AdVerifications>
+			<Extensions>
+				<Extension type="AdVerifications">
+					<AdVerifications>
+						<Verification vendor="legacy.com-omid">
+							<JavaScriptResource apiFramework="omid" browserOptional="true"><![CDATA[https://legacy.com/omid.js]]></JavaScriptResource>
+						</Verification>
+					</AdVerifications>
+				</Extension>
+			</Extensions>
+		</Wrapper>
+	</Ad>
+</VAST>`
+
+const verificationInLine = `<VAST version="4.2">
+	<Ad id="inline">
+		<InLine>
+			<AdSystem>InLine</AdSystem>
+			<AdTitle>Ad</AdTitle>
+			<AdVerifications>
+				<Verification vendor="company.com-omid">
+					<JavaScriptResource apiFramework="omid" browserOptional="false"><![CDATA[ https://company.com/omid.js ]]></JavaScriptResource>
+					<JavaScriptResource apiFramework="omid" browserOptional="true"><![CDATA[https://company.com/omid-lite.js]]></JavaScriptResource>
+					<TrackingEvents>
+						<Tracking event="verificationNotExecuted"><![CDATA[https://company.com/not-executed?reason=[REASON]]]></Tracking>
+					</TrackingEvents>
+					<VerificationParameters><![CDATA[{"id":"42"}]]></VerificationParameters>
+				</Verification>
+				<Verification vendor="other.com-custom">
+					<JavaScriptResource apiFramework="custom"><![CDATA[https://other.com/custom.js]]></JavaScriptResource>
+					<ExecutableResource apiFramework="omid" type="application/octet-stream"><![CDATA[https://other.com/omid.bin]]></ExecutableResource>
+					<TrackingEvents>
+						<Tracking event="verificationNotExecuted"><![CDATA[https://other.com/not-executed?reason=[REASON]]]></Tracking>
+					</TrackingEvents>
+				</Verification>
+			</AdVerifications>
+			<Creatives/>
+		</InLine>
+	</Ad>
+</VAST>`
+
+func resolveVerifications(t *testing.T) *ResolvedAd {
+	docs := map[string]string{"http://inline": verificationInLine}
+	fetcher := FetcherFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		doc, ok := docs[uri]
+		if !ok {
+			return nil, fmt.Errorf("unknown uri %s", uri)
+		}
+		return []byte(doc), nil
+	})
+	var v VAST
+	if !assert.NoError(t, xml.Unmarshal([]byte(verificationWrapper), &v)) {
+		return nil
+	}
+	ads, err := NewResolver(fetcher).Resolve(context.Background(), &v)
+	if !assert.NoError(t, err) || !assert.Len(t, ads, 1) {
+		return nil
+	}
+	return &ads[0]
+}
+
+func verificationURIs(vs []OMVerification) []string {
+	uris := make([]string, len(vs))
+	for i, v := range vs {
+		uris[i] = v.Vendor + " " + v.URI
+	}
+	return uris
+}
+
+func TestSelectVerifications(t *testing.T) {
+	ad := resolveVerifications(t)
+	if ad == nil {
+		return
+	}
+
+	s := SelectVerifications(ad, nil)
+	assert.Equal(t, []string{
+		"company.com-omid https://company.com/omid.js",
+		"wrapper.com-omid https://wrapper.com/omid.js",
+		"legacy.com-omid https://legacy.com/omid.js",
+	}, verificationURIs(s.Verifications))
+	assert.Equal(t, []string{"other.com-custom "}, verificationURIs(s.Rejected))
+	assert.Equal(t, VerificationReasonNotSupported, s.Rejected[0].Reason)
+
+	v := s.Verifications[0]
+	assert.Equal(t, `{"id":"42"}`, v.Parameters)
+	assert.False(t, v.BrowserOptional)
+	assert.Equal(t, 0, v.Reason)
+	assert.Equal(t, []string{"https://company.com/not-executed?reason=[REASON]"}, v.NotExecuted)
+	assert.Equal(t, []string{"https://company.com/not-executed?reason=3"}, v.NotExecutedURLs(VerificationReasonLoadError, nil))
+	assert.Equal(t, []string{"https://other.com/not-executed?reason=2"}, s.Rejected[0].NotExecutedURLs(s.Rejected[0].Reason, &MacroContext{Reason: 1}))
+
+	// only the browser optional scripts run without a browser
+	s = SelectVerifications(ad, &OMEnvironment{
+		Browserless: true,
+		Allow:       func(v *Verification) bool { return v.Vendor != "legacy.com-omid" },
+	})
+	assert.Equal(t, []string{"company.com-omid https://company.com/omid-lite.js"}, verificationURIs(s.Verifications))
+	assert.True(t, s.Verifications[0].BrowserOptional)
+	assert.Equal(t, []string{
+		"other.com-custom ",
+		"wrapper.com-omid ",
+		"legacy.com-omid https://legacy.com/omid.js",
+	}, verificationURIs(s.Rejected))
+	assert.Equal(t, []int{VerificationReasonNotSupported, VerificationReasonNotSupported, VerificationReasonRejected},
+		[]int{s.Rejected[0].Reason, s.Rejected[1].Reason, s.Rejected[2].Reason})
+
+	assert.Empty(t, SelectVerifications(nil, nil).Verifications)
+	assert.Empty(t, SelectVerifications(&ResolvedAd{}, nil).Verifications)
+}
+
+func TestSelectVerificationsDuplicates(t *testing.T) {
+	in := &InLine{
+		AdVerifications: &AdVerifications{Verification: []Verification{
+			{Vendor: "a", JavaScriptResource: []JavaScriptResource{{ApiFramework: "OMID", URI: "https://a/omid.js"}}},
+		}},
+	}
+	b, err := xml.Marshal(in.AdVerifications)
+	if !assert.NoError(t, err) {
+		return
+	}
+	in.Extensions = &[]Extension{{Type: ExtensionTypeAdVerifications, Data: string(b)}}
+	s := SelectVerifications(&ResolvedAd{Ad: Ad{InLine: in}}, nil)
+	assert.Equal(t, []string{"a https://a/omid.js"}, verificationURIs(s.Verifications))
+}
+
+func TestSelectVerificationsInvalidExtension(t *testing.T) {
+	in := &InLine{Extensions: &[]Extension{
+		{Type: ExtensionTypeAdVerifications, Data: "<AdVerifications><Verification"},
+	}}
+	w := &Wrapper{Extensions: &[]Extension{
+		{Type: "other"},
+		{Type: ExtensionTypeAdVerifications, Data: `<AdVerifications><Verification vendor="a"><JavaScriptResource apiFramework="omid"><![CDATA[https://a/omid.js]]></JavaScriptResource></Verification></AdVerifications>`},
+	}}
+	s := SelectVerifications(&ResolvedAd{Ad: Ad{InLine: in}, Wrappers: []*Wrapper{w}}, nil)
+	assert.Equal(t, []string{"a https://a/omid.js"}, verificationURIs(s.Verifications))
+	if assert.Len(t, s.Errors, 1) {
+		assert.Contains(t, s.Errors[0].Error(), "InLine.Extensions[0]: ")
+	}
+
+	in.Extensions = nil
+	assert.Empty(t, SelectVerifications(&ResolvedAd{Ad: Ad{InLine: in}, Wrappers: []*Wrapper{w}}, nil).Errors)
+}
+
+func TestScriptResourcesJSON(t *testing.T) {
+	ad := resolveVerifications(t)
+	if ad == nil {
+		return
+	}
+	b, err := SelectVerifications(ad, nil).ScriptResourcesJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"resourceUrl": "https://company.com/omid.js", "vendorKey": "company.com-omid", "verificationParameters": "{\"id\":\"42\"}"},
+		{"resourceUrl": "https://wrapper.com/omid.js", "vendorKey": "wrapper.com-omid"},
+		{"resourceUrl": "https://legacy.com/omid.js", "vendorKey": "legacy.com-omid"}
+	]`, string(b))
+
+	b, err = SelectVerifications(nil, nil).ScriptResourcesJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(b))
+}
+
+func TestSelectVerificationsTestdata(t *testing.T) {
+	v, _, _, err := loadFixture("testdata/iab/vast_4.2_samples/Ad_Verification-test.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the scripts of the sample don't name their API framework
+	s := SelectVerifications(&ResolvedAd{Ad: v.Ads[0]}, nil)
+	assert.Empty(t, s.Verifications)
+	assert.Len(t, s.Rejected, 2)
+}
@@ -0,0 +1,146 @@
+package vast
+
+import (
+	"strings"
+	"time"
+)
+
+// Events of the beacons emitted by a ViewabilityTracker for the URIs of the
+// ViewableImpression.
+const (
+	EventTypeViewable         = "viewable"
+	EventTypeNotViewable      = "notViewable"
+	EventTypeViewUndetermined = "viewUndetermined"
+)
+
+// ViewabilityStandard defines when an impression is viewable.
+type ViewabilityStandard struct {
+	// Minimum fraction of the pixels of the ad in view, between 0 and 1.
+	InView float64
+	// Minimum continuous duration meeting the criteria.
+	Duration time.Duration
+	// Audible is set if the ad must also be audible.
+	Audible bool
+}
+
+// The viewability standards of the Media Rating Council.
+var (
+	// MRCVideoViewability is the standard of video ads: half of the pixels
+	// in view for two continuous seconds.
+	MRCVideoViewability = ViewabilityStandard{InView: 0.5, Duration: 2 * time.Second}
+	// MRCDisplayViewability is the standard of display ads, such as
+	// companions: half of the pixels in view for one continuous second.
+	MRCDisplayViewability = ViewabilityStandard{InView: 0.5, Duration: time.Second}
+)
+
+// ViewabilityMeasurement is the visibility of an ad during a period.
+type ViewabilityMeasurement struct {
+	// Fraction of the pixels of the ad in view, between 0 and 1.
+	InView float64
+	// Duration of the period.
+	Duration time.Duration
+	// Audible is set if the ad could be heard.
+	Audible bool
+}
+
+// ViewabilityTracker tells which ViewableImpression URIs of an ad to ping
+// from the measurements of its visibility.
+//
+// The player calls Measure with the successive measurements while the ad
+// plays, then End once it is over, or Undetermined if it can't measure the
+// visibility. The outcome is decided once, by the first of them to return
+// beacons: the Viewable URIs as soon as the measurements meet the standard,
+// else the NotViewable URIs at the end, or the ViewUndetermined URIs if
+// nothing was measured.
+//
+// A ViewabilityTracker isn't safe for concurrent use.
+type ViewabilityTracker struct {
+	clock    Clock
+	standard ViewabilityStandard
+	uris     map[string][]string
+
+	measured   bool
+	elapsed    time.Duration
+	continuous time.Duration
+	outcome    string
+}
+
+// NewViewabilityTracker returns a ViewabilityTracker for the
+// ViewableImpression of the resolved ad, which holds the URIs of the InLine
+// ad and of all its wrappers, judged by std. A nil clock is the SystemClock.
+func NewViewabilityTracker(ad *ResolvedAd, std ViewabilityStandard, clock Clock) *ViewabilityTracker {
+	if clock == nil {
+		clock = SystemClock
+	}
+	t := &ViewabilityTracker{clock: clock, standard: std, uris: map[string][]string{}}
+	if ad == nil || ad.Ad.InLine == nil || ad.Ad.InLine.ViewableImpression == nil {
+		return t
+	}
+	vi := ad.Ad.InLine.ViewableImpression
+	for event, list := range map[string][]CDATAString{
+		EventTypeViewable:         vi.Viewable,
+		EventTypeNotViewable:      vi.NotViewable,
+		EventTypeViewUndetermined: vi.ViewUndetermined,
+	} {
+		for _, uri := range list {
+			if !blank(uri.CDATA) {
+				t.uris[event] = append(t.uris[event], strings.TrimSpace(uri.CDATA))
+			}
+		}
+	}
+	return t
+}
+
+// Outcome returns the event decided, EventTypeViewable, EventTypeNotViewable
+// or EventTypeViewUndetermined, or "" if it isn't decided yet.
+func (t *ViewabilityTracker) Outcome() string {
+	return t.outcome
+}
+
+// Measure records a measurement, emitting the Viewable beacons if the ad
+// becomes viewable.
+func (t *ViewabilityTracker) Measure(m ViewabilityMeasurement) []Beacon {
+	if t.outcome != "" || m.Duration < 0 {
+		return nil
+	}
+	t.measured = true
+	t.elapsed += m.Duration
+	if m.InView < t.standard.InView || t.standard.Audible && !m.Audible {
+		t.continuous = 0
+		return nil
+	}
+	t.continuous += m.Duration
+	if t.continuous < t.standard.Duration {
+		return nil
+	}
+	return t.decide(EventTypeViewable)
+}
+
+// End ends the impression, emitting the NotViewable beacons if the ad didn't
+// become viewable, or the ViewUndetermined beacons if nothing was measured.
+func (t *ViewabilityTracker) End() []Beacon {
+	if !t.measured {
+		return t.decide(EventTypeViewUndetermined)
+	}
+	return t.decide(EventTypeNotViewable)
+}
+
+// Undetermined emits the ViewUndetermined beacons, if the outcome isn't
+// decided yet, when the visibility can't be measured.
+func (t *ViewabilityTracker) Undetermined() []Beacon {
+	return t.decide(EventTypeViewUndetermined)
+}
+
+// decide decides the outcome, unless it was before, and returns its beacons.
+func (t *ViewabilityTracker) decide(event string) []Beacon {
+	if t.outcome != "" {
+		return nil
+	}
+	t.outcome = event
+	var res []Beacon
+	now := t.clock.Now()
+	for _, uri := range t.uris[event] {
+		res = append(res, Beacon{Event: event, URI: uri, Playhead: t.elapsed, Time: now})
+	}
+	return res
+}
@@ -0,0 +1,128 @@
+package vast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const viewableInLine = `<VAST version="4.2">
+	<Ad id="inline">
+		<InLine>
+			<AdSystem>InLine</AdSystem>
+			<AdTitle>Ad</AdTitle>
+			<ViewableImpression id="inline">
+				<Viewable><![CDATA[http://inline/viewable]]></Viewable>
+				<NotViewable><![CDATA[http://inline/not-viewable]]></NotViewable>
+				<ViewUndetermined><![CDATA[ ]]></ViewUndetermined>
+			</ViewableImpression>
+			<Creatives/>
+		</InLine>
+	</Ad>
+</VAST>`
+
+// resolveViewable resolves the wrapper of the IAB sample to an InLine ad,
+// both with a ViewableImpression.
+func resolveViewable(t *testing.T) *ResolvedAd {
+	v, _, _, err := loadFixture("testdata/iab/vast_4.2_samples/Viewable_Impression-test.xml")
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	fetcher := FetcherFunc(func(ctx context.Context, uri string) ([]byte, error) {
+		return []byte(viewableInLine), nil
+	})
+	ads, err := NewResolver(fetcher).Resolve(context.Background(), v)
+	if !assert.NoError(t, err) || !assert.Len(t, ads, 1) {
+		return nil
+	}
+	return &ads[0]
+}
+
+func beaconURIs(bs []Beacon) []string {
+	uris := make([]string, len(bs))
+	for i, b := range bs {
+		uris[i] = b.Event + " " + b.URI
+	}
+	return uris
+}
+
+func TestViewabilityTracker(t *testing.T) {
+	ad := resolveViewable(t)
+	if ad == nil {
+		return
+	}
+	const wrapper = "https://search.iabtechlab.com/error?errcode=102&imprid=s5-ea2f7f298e28c0c98374491aec3dfeb1&ts=1243"
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	vt := NewViewabilityTracker(ad, MRCVideoViewability, clock)
+
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 0.8, Duration: 1500 * time.Millisecond}))
+	// the duration in view must be continuous
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 0.4, Duration: time.Second}))
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 0.5, Duration: time.Second}))
+	assert.Equal(t, "", vt.Outcome())
+
+	clock.Advance(time.Second)
+	bs := vt.Measure(ViewabilityMeasurement{InView: 1, Duration: time.Second})
+	assert.Equal(t, []string{
+		EventTypeViewable + " http://inline/viewable",
+		EventTypeViewable + " " + wrapper,
+	}, beaconURIs(bs))
+	assert.Equal(t, 4500*time.Millisecond, bs[0].Playhead)
+	assert.Equal(t, clock.now, bs[0].Time)
+	assert.Equal(t, EventTypeViewable, vt.Outcome())
+
+	// the outcome is decided once
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 1, Duration: time.Minute}))
+	assert.Empty(t, vt.End())
+	assert.Empty(t, vt.Undetermined())
+	assert.Equal(t, EventTypeViewable, vt.Outcome())
+}
+
+func TestViewabilityTrackerNotViewable(t *testing.T) {
+	ad := resolveViewable(t)
+	if ad == nil {
+		return
+	}
+	vt := NewViewabilityTracker(ad, MRCVideoViewability, nil)
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 0.2, Duration: 10 * time.Second}))
+	assert.Equal(t, []string{
+		EventTypeNotViewable + " http://inline/not-viewable",
+		EventTypeNotViewable + " https://search.iabtechlab.com/error?errcode=102&imprid=s5-ea2f7f298e28c0c98374491aec3dfeb1&ts=1243",
+	}, beaconURIs(vt.End()))
+	assert.Equal(t, EventTypeNotViewable, vt.Outcome())
+	assert.Empty(t, vt.End())
+
+	// nothing measured
+	vt = NewViewabilityTracker(ad, MRCVideoViewability, nil)
+	assert.Equal(t, []string{
+		EventTypeViewUndetermined + " https://search.iabtechlab.com/error?errcode=102&imprid=s5-ea2f7f298e28c0c98374491aec3dfeb1&ts=1243",
+	}, beaconURIs(vt.End()))
+	assert.Equal(t, EventTypeViewUndetermined, vt.Outcome())
+
+	vt = NewViewabilityTracker(ad, MRCVideoViewability, nil)
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 1, Duration: time.Second}))
+	assert.Len(t, vt.Undetermined(), 1)
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 1, Duration: time.Second}))
+}
+
+func TestViewabilityTrackerStandard(t *testing.T) {
+	vi := &ViewableImpression{Viewable: []CDATAString{{"http://viewable"}}}
+	ad := &ResolvedAd{Ad: Ad{InLine: &InLine{ViewableImpression: vi}}}
+
+	audible := ViewabilityStandard{InView: 1, Duration: 15 * time.Second, Audible: true}
+	vt := NewViewabilityTracker(ad, audible, nil)
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 1, Duration: 15 * time.Second}))
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 1, Duration: 10 * time.Second, Audible: true}))
+	assert.Len(t, vt.Measure(ViewabilityMeasurement{InView: 1, Duration: 5 * time.Second, Audible: true}), 1)
+
+	// a standard without duration is met by a single measurement
+	vt = NewViewabilityTracker(ad, ViewabilityStandard{InView: 0.5}, nil)
+	assert.Len(t, vt.Measure(ViewabilityMeasurement{InView: 0.5}), 1)
+
+	vt = NewViewabilityTracker(&ResolvedAd{}, MRCDisplayViewability, nil)
+	assert.Empty(t, vt.Measure(ViewabilityMeasurement{InView: 1, Duration: time.Second}))
+	assert.Equal(t, EventTypeViewable, vt.Outcome())
+	assert.Empty(t, NewViewabilityTracker(nil, MRCDisplayViewability, nil).End())
+}
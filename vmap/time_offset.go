@@ -0,0 +1,70 @@
+package vmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/llgoer/vast"
+)
+
+// Special values of a TimeOffset.
+const (
+	TimeOffsetStart = "start"
+	TimeOffsetEnd   = "end"
+)
+
+// TimeOffset is the timeOffset attribute of an ad break. Only one of its
+// fields is set.
+type TimeOffset struct {
+	// Start is true for breaks before the content ("start").
+	Start bool
+	// End is true for breaks after the content ("end").
+	End bool
+	// Position is the n of positional offsets "#n", starting at 1.
+	Position int
+	// Offset is a time or percentage offset.
+	Offset *vast.Offset
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (o TimeOffset) MarshalText() ([]byte, error) {
+	switch {
+	case o.Start:
+		return []byte(TimeOffsetStart), nil
+	case o.End:
+		return []byte(TimeOffsetEnd), nil
+	case o.Position > 0:
+		return []byte("#" + strconv.Itoa(o.Position)), nil
+	case o.Offset != nil:
+		return o.Offset.MarshalText()
+	}
+	return []byte(TimeOffsetStart), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (o *TimeOffset) UnmarshalText(data []byte) error {
+	*o = TimeOffset{}
+	s := strings.TrimSpace(string(data))
+	switch {
+	case s == "":
+		return fmt.Errorf("invalid time offset: %s", data)
+	case s == TimeOffsetStart:
+		o.Start = true
+	case s == TimeOffsetEnd:
+		o.End = true
+	case strings.HasPrefix(s, "#"):
+		n, err := strconv.Atoi(s[1:])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid time offset: %s", data)
+		}
+		o.Position = n
+	default:
+		var offset vast.Offset
+		if err := offset.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid time offset: %s", data)
+		}
+		o.Offset = &offset
+	}
+	return nil
+}
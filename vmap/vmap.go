@@ -0,0 +1,181 @@
+// Package vmap implements IAB VMAP 1.0.1 https://www.iab.com/wp-content/uploads/2015/06/VMAPv1_0.pdf
+//
+// VMAP describes the ad breaks of a content and reuses the VAST types of the
+// parent package for the ads they contain.
+package vmap
+
+import (
+	"encoding/xml"
+
+	"github.com/llgoer/vast"
+)
+
+// Namespace is the XML namespace of VMAP elements.
+const Namespace = "http://www.iab.net/videosuite/vmap"
+
+// prefix is the namespace prefix used when marshaling VMAP elements.
+const prefix = "vmap:"
+
+// Break types, which can be combined with commas in AdBreak.BreakType.
+const (
+	BreakTypeLinear    = "linear"
+	BreakTypeNonLinear = "nonlinear"
+	BreakTypeDisplay   = "display"
+)
+
+// Tracking events of an ad break.
+const (
+	// the ad break started.
+	EventBreakStart = "breakStart"
+	// the ad break ended.
+	EventBreakEnd = "breakEnd"
+	// an error occurred while playing the ad break.
+	EventError = "error"
+)
+
+// VMAP is the root <vmap:VMAP> tag
+type VMAP struct {
+	// The version of the VMAP spec (should be "1.0")
+	Version string `xml:"version,attr" json:",omitempty"`
+	// Zero or more ad breaks, in the order they should be played.
+	AdBreaks []AdBreak `xml:"AdBreak,omitempty" json:"AdBreak,omitempty"`
+	// XML node for custom extensions, as defined by the ad server.
+	Extensions *Extensions `xml:"Extensions,omitempty" json:",omitempty"`
+}
+
+// AdBreak represents a single ad break, opportunity for one or more ads.
+type AdBreak struct {
+	// Represents the timing for the ad break: a time, a percentage, "start",
+	// "end" or a position "#n".
+	TimeOffset TimeOffset `xml:"timeOffset,attr"`
+	// Identifies whether the ad break allows "linear", "nonlinear" or
+	// "display" ads, as a comma separated list.
+	BreakType string `xml:"breakType,attr"`
+	// An optional string identifier for the ad break.
+	BreakID string `xml:"breakId,attr,omitempty" json:",omitempty"`
+	// An optional duration after which the ad break repeats.
+	RepeatAfter *vast.Duration `xml:"repeatAfter,attr,omitempty" json:",omitempty"`
+	// Represents the ad data that will be used to fill the ad break.
+	AdSource *AdSource `xml:"AdSource,omitempty" json:",omitempty"`
+	// Contains URIs for the tracking of ad break events.
+	TrackingEvents *TrackingEvents `xml:"TrackingEvents,omitempty" json:",omitempty"`
+	// XML node for custom extensions, as defined by the ad server.
+	Extensions *Extensions `xml:"Extensions,omitempty" json:",omitempty"`
+}
+
+// AdSource contains the ad response to use for the ad break, either inlined
+// or by reference. Only one of its data fields should be set.
+type AdSource struct {
+	// An optional identifier for the ad source.
+	ID string `xml:"id,attr,omitempty" json:",omitempty"`
+	// Indicates whether a VAST ad pod or multiple buffet of ads can be served
+	// into the ad break.
+	AllowMultipleAds *bool `xml:"allowMultipleAds,attr,omitempty" json:",omitempty"`
+	// Indicates whether the video player should honor the redirects within an
+	// ad response.
+	FollowRedirects *bool `xml:"followRedirects,attr,omitempty" json:",omitempty"`
+	// A VAST document that comprises the ad response.
+	VASTAdData *VASTAdData `xml:"VASTAdData,omitempty" json:",omitempty"`
+	// A URI for the ad tag of the ad break.
+	AdTagURI *AdTagURI `xml:"AdTagURI,omitempty" json:",omitempty"`
+	// An ad response document that is not VAST.
+	CustomAdData *CustomAdData `xml:"CustomAdData,omitempty" json:",omitempty"`
+}
+
+// VASTAdData embeds a VAST document.
+type VASTAdData struct {
+	VAST *vast.VAST `xml:"VAST,omitempty" json:",omitempty"`
+}
+
+// AdTagURI is a URI for the ad tag of the ad break.
+type AdTagURI struct {
+	// The ad response template, e.g. "vast3".
+	TemplateType string `xml:"templateType,attr"`
+	URI          string `xml:",cdata"`
+}
+
+// CustomAdData is an ad response which is not VAST.
+type CustomAdData struct {
+	// The ad response template, e.g. "vast3" or "proprietary".
+	TemplateType string `xml:"templateType,attr"`
+	// The raw content of the element.
+	Data string `xml:",innerxml"`
+}
+
+// TrackingEvents contains the tracking URIs of an ad break.
+type TrackingEvents struct {
+	Tracking []Tracking `xml:"Tracking,omitempty"`
+}
+
+// Tracking defines an ad break event tracking URL.
+type Tracking struct {
+	// The name of the event: breakStart, breakEnd or error.
+	Event string `xml:"event,attr"`
+	URI   string `xml:",cdata"`
+}
+
+// Extensions contains custom extensions, as defined by the ad server.
+type Extensions struct {
+	Extension []vast.Extension `xml:"Extension,omitempty" json:",omitempty"`
+}
+
+// The following types mirror the VMAP types with prefixed element names, as
+// encoding/xml can't marshal namespace prefixes. They don't have the
+// MarshalXML methods of the types they mirror.
+type (
+	vmapXML struct {
+		Version    string      `xml:"version,attr"`
+		AdBreaks   []AdBreak   `xml:"vmap:AdBreak,omitempty"`
+		Extensions *Extensions `xml:"vmap:Extensions,omitempty"`
+	}
+	adBreakXML struct {
+		TimeOffset     TimeOffset      `xml:"timeOffset,attr"`
+		BreakType      string          `xml:"breakType,attr"`
+		BreakID        string          `xml:"breakId,attr,omitempty"`
+		RepeatAfter    *vast.Duration  `xml:"repeatAfter,attr,omitempty"`
+		AdSource       *AdSource       `xml:"vmap:AdSource,omitempty"`
+		TrackingEvents *TrackingEvents `xml:"vmap:TrackingEvents,omitempty"`
+		Extensions     *Extensions     `xml:"vmap:Extensions,omitempty"`
+	}
+	adSourceXML struct {
+		ID               string        `xml:"id,attr,omitempty"`
+		AllowMultipleAds *bool         `xml:"allowMultipleAds,attr,omitempty"`
+		FollowRedirects  *bool         `xml:"followRedirects,attr,omitempty"`
+		VASTAdData       *VASTAdData   `xml:"vmap:VASTAdData,omitempty"`
+		AdTagURI         *AdTagURI     `xml:"vmap:AdTagURI,omitempty"`
+		CustomAdData     *CustomAdData `xml:"vmap:CustomAdData,omitempty"`
+	}
+	trackingEventsXML struct {
+		Tracking []Tracking `xml:"vmap:Tracking,omitempty"`
+	}
+	extensionsXML struct {
+		Extension []vast.Extension `xml:"vmap:Extension,omitempty"`
+	}
+)
+
+// MarshalXML implements xml.Marshaler interface.
+func (v VMAP) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: prefix + "VMAP"}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:vmap"}, Value: Namespace})
+	return enc.EncodeElement(vmapXML(v), start)
+}
+
+// MarshalXML implements xml.Marshaler interface.
+func (b AdBreak) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(adBreakXML(b), start)
+}
+
+// MarshalXML implements xml.Marshaler interface.
+func (s AdSource) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(adSourceXML(s), start)
+}
+
+// MarshalXML implements xml.Marshaler interface.
+func (t TrackingEvents) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(trackingEventsXML(t), start)
+}
+
+// MarshalXML implements xml.Marshaler interface.
+func (e Extensions) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(extensionsXML(e), start)
+}
@@ -0,0 +1,155 @@
+package vmap
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llgoer/go-xml/xmltree"
+	"github.com/llgoer/vast"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadFixture(path string) (*VMAP, []byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var v VMAP
+	err = xml.Unmarshal(b, &v)
+	return &v, b, err
+}
+
+func TestAdTagURI(t *testing.T) {
+	v, _, err := loadFixture("testdata/vmap_adtaguri.xml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "1.0", v.Version)
+	if !assert.Len(t, v.AdBreaks, 5) {
+		return
+	}
+
+	pre := v.AdBreaks[0]
+	assert.Equal(t, TimeOffset{Start: true}, pre.TimeOffset)
+	assert.Equal(t, BreakTypeLinear, pre.BreakType)
+	assert.Equal(t, "preroll", pre.BreakID)
+	if assert.NotNil(t, pre.AdSource) {
+		assert.Equal(t, "preroll-ad-1", pre.AdSource.ID)
+		if assert.NotNil(t, pre.AdSource.AllowMultipleAds) {
+			assert.False(t, *pre.AdSource.AllowMultipleAds)
+		}
+		if assert.NotNil(t, pre.AdSource.FollowRedirects) {
+			assert.True(t, *pre.AdSource.FollowRedirects)
+		}
+		assert.Equal(t, &AdTagURI{TemplateType: "vast3", URI: "http://example.com/vast/preroll.xml"}, pre.AdSource.AdTagURI)
+	}
+	if assert.NotNil(t, pre.TrackingEvents) && assert.Len(t, pre.TrackingEvents.Tracking, 3) {
+		assert.Equal(t, EventBreakStart, pre.TrackingEvents.Tracking[0].Event)
+		assert.Equal(t, EventBreakEnd, pre.TrackingEvents.Tracking[1].Event)
+		assert.Equal(t, EventError, pre.TrackingEvents.Tracking[2].Event)
+		assert.Equal(t, "http://example.com/tracking/error?id=preroll&code=[ERRORCODE]", pre.TrackingEvents.Tracking[2].URI)
+	}
+
+	mid := v.AdBreaks[1]
+	d := vast.Duration(10 * time.Minute)
+	assert.Equal(t, TimeOffset{Offset: &vast.Offset{Duration: &d}}, mid.TimeOffset)
+	assert.Equal(t, "linear,nonlinear", mid.BreakType)
+	assert.Equal(t, &d, mid.RepeatAfter)
+
+	custom := v.AdBreaks[2]
+	assert.Equal(t, TimeOffset{Offset: &vast.Offset{Percent: 0.5}}, custom.TimeOffset)
+	if assert.NotNil(t, custom.AdSource) && assert.NotNil(t, custom.AdSource.CustomAdData) {
+		assert.Equal(t, "proprietary", custom.AdSource.CustomAdData.TemplateType)
+		assert.Equal(t, `<![CDATA[<Ad id="custom"/>]]>`, custom.AdSource.CustomAdData.Data)
+	}
+
+	assert.Equal(t, TimeOffset{Position: 2}, v.AdBreaks[3].TimeOffset)
+
+	post := v.AdBreaks[4]
+	assert.Equal(t, TimeOffset{End: true}, post.TimeOffset)
+	if assert.NotNil(t, post.Extensions) && assert.Len(t, post.Extensions.Extension, 1) {
+		assert.Equal(t, "example", post.Extensions.Extension[0].Type)
+		assert.Equal(t, "<Priority>1</Priority>", string(post.Extensions.Extension[0].Data))
+	}
+	if assert.NotNil(t, v.Extensions) && assert.Len(t, v.Extensions.Extension, 1) {
+		assert.Equal(t, "content", v.Extensions.Extension[0].Type)
+	}
+}
+
+func TestVASTAdData(t *testing.T) {
+	v, _, err := loadFixture("testdata/vmap_inline_vast.xml")
+	if !assert.NoError(t, err) || !assert.Len(t, v.AdBreaks, 2) {
+		return
+	}
+	src := v.AdBreaks[0].AdSource
+	if !assert.NotNil(t, src) || !assert.NotNil(t, src.VASTAdData) || !assert.NotNil(t, src.VASTAdData.VAST) {
+		return
+	}
+	doc := src.VASTAdData.VAST
+	assert.Equal(t, "3.0", doc.Version)
+	if assert.Len(t, doc.Ads, 1) && assert.NotNil(t, doc.Ads[0].InLine) {
+		in := doc.Ads[0].InLine
+		assert.Equal(t, "Preroll", in.AdTitle.CDATA)
+		if assert.Len(t, in.Creatives, 1) && assert.NotNil(t, in.Creatives[0].Linear) {
+			assert.Equal(t, vast.Duration(15*time.Second), in.Creatives[0].Linear.Duration)
+		}
+	}
+	d := vast.Duration(5*time.Minute + 30*time.Second + 500*time.Millisecond)
+	assert.Equal(t, TimeOffset{Offset: &vast.Offset{Duration: &d}}, v.AdBreaks[1].TimeOffset)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, fixture := range []string{
+		"testdata/vmap_adtaguri.xml",
+		"testdata/vmap_inline_vast.xml",
+	} {
+		t.Run(fixture, func(t *testing.T) {
+			v, b, err := loadFixture(fixture)
+			if !assert.NoError(t, err) {
+				return
+			}
+			expected, err := xmltree.Parse(b)
+			assert.NoError(t, err)
+
+			out, err := xml.Marshal(v)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.True(t, strings.HasPrefix(string(out), `<vmap:VMAP xmlns:vmap="`+Namespace+`" version="1.0">`))
+
+			actual, err := xmltree.Parse(out)
+			assert.NoError(t, err)
+			assert.True(t, xmltree.Equal(actual, expected))
+		})
+	}
+}
+
+func TestTimeOffset(t *testing.T) {
+	d := vast.Duration(90 * time.Second)
+	tests := []struct {
+		in  string
+		out TimeOffset
+	}{
+		{"start", TimeOffset{Start: true}},
+		{"end", TimeOffset{End: true}},
+		{"#3", TimeOffset{Position: 3}},
+		{"00:01:30", TimeOffset{Offset: &vast.Offset{Duration: &d}}},
+		{"25%", TimeOffset{Offset: &vast.Offset{Percent: 0.25}}},
+	}
+	for _, tt := range tests {
+		var o TimeOffset
+		if assert.NoError(t, o.UnmarshalText([]byte(tt.in)), tt.in) {
+			assert.Equal(t, tt.out, o, tt.in)
+			b, err := o.MarshalText()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.in, string(b))
+		}
+	}
+	for _, in := range []string{"#0", "#x", "later", ""} {
+		var o TimeOffset
+		assert.EqualError(t, o.UnmarshalText([]byte(in)), "invalid time offset: "+in)
+	}
+}